@@ -0,0 +1,70 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/middleware/auth"
+	"github.com/sachin-duhan/postal-go/internal/middleware/signing"
+)
+
+// KeyProvider exchanges organization credentials for API keys
+type KeyProvider = auth.KeyProvider
+
+// Token is an API key returned by a KeyProvider
+type Token = auth.Token
+
+// WithKeyProvider sends requests with keys from provider instead of the
+// client's static API key. Tokens are cached and refreshed refreshBefore
+// ahead of expiry (one minute when zero), with concurrent requests sharing
+// a single refresh. Requests sent with another key, such as those from
+// tenant views, are left unchanged.
+func WithKeyProvider(provider KeyProvider, refreshBefore time.Duration) Option {
+	return func(c *clientImpl) {
+		if c.keyProvider {
+			c.conflicts = append(c.conflicts, "WithKeyProvider is applied more than once")
+		}
+		c.keyProvider = true
+		c.transport.AddMiddleware(auth.New(auth.Config{
+			Provider:      provider,
+			RefreshBefore: refreshBefore,
+			ReplaceKey:    c.apiKey,
+		}))
+	}
+}
+
+// Signer signs request bodies for WithRequestSigner
+type Signer = signing.Signer
+
+// WithRequestSigning adds an HMAC-SHA256 signature of each request body to
+// header (X-Postal-Signature when empty), for gateways in front of Postal
+// that authenticate requests with a shared secret. Streamed bodies are
+// buffered to be signed.
+func WithRequestSigning(secret []byte, header string) Option {
+	return WithRequestSigner(signing.NewHMAC(secret), header)
+}
+
+// WithRequestSigner is WithRequestSigning with a custom signature scheme,
+// such as one backed by a FIPS-validated module. Requests whose body cannot
+// be signed are not sent.
+func WithRequestSigner(signer Signer, header string) Option {
+	return func(c *clientImpl) {
+		name := header
+		if name == "" {
+			name = signing.DefaultHeader
+		}
+		name = http.CanonicalHeaderKey(name)
+		if c.signingHeaders[name] {
+			c.conflicts = append(c.conflicts, fmt.Sprintf("request signing is applied more than once to header %s", name))
+		}
+		if c.signingHeaders == nil {
+			c.signingHeaders = make(map[string]bool)
+		}
+		c.signingHeaders[name] = true
+		c.transport.AddMiddleware(signing.New(signing.Config{
+			Signer: signer,
+			Header: header,
+		}))
+	}
+}
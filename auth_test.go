@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type staticKeyProvider struct {
+	calls atomic.Int32
+}
+
+func (p *staticKeyProvider) Token(ctx context.Context) (Token, error) {
+	p.calls.Add(1)
+	return Token{Value: "exchanged-key", Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestWithKeyProvider(t *testing.T) {
+	var keys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("X-Server-API-Key"))
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12356", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	provider := &staticKeyProvider{}
+	c, err := NewClient(ts.URL, "org-placeholder", WithKeyProvider(provider, 0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := c.SendMessage(ctx, compatTestMessage()); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	if _, err := c.ForTenant("tenant-key", TenantDefaults{}).SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("tenant SendMessage() error = %v", err)
+	}
+
+	want := []string{"exchanged-key", "exchanged-key", "tenant-key"}
+	if len(keys) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(keys), len(want))
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("request %d key = %q, want %q", i, keys[i], want[i])
+		}
+	}
+	if got := provider.calls.Load(); got != 1 {
+		t.Errorf("provider calls = %d, want 1", got)
+	}
+}
+
+func TestWithRequestSigning(t *testing.T) {
+	var verified bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("gateway-secret"))
+		mac.Write(body)
+		verified = hex.EncodeToString(mac.Sum(nil)) == r.Header.Get("X-Gateway-Signature")
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12356", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key", WithRequestSigning([]byte("gateway-secret"), "X-Gateway-Signature"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := c.SendMessage(context.Background(), compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if !verified {
+		t.Error("gateway could not verify the request signature")
+	}
+}
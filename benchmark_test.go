@@ -1,10 +1,14 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -544,4 +548,116 @@ func BenchmarkStressTest(b *testing.B) {
 	wg.Wait()
 	
 	b.Logf("Success: %d, Errors: %d", successCount, errorCount)
-}
\ No newline at end of file
+}
+// campaignMessage returns a message with a large static body, as in a campaign
+func campaignMessage() *types.Message {
+	return &types.Message{
+		To:       []string{"recipient@example.com"},
+		From:     "sender@example.com",
+		Subject:  "Spring newsletter",
+		HTMLBody: "<html><body>" + strings.Repeat("<p>Newsletter paragraph with some content.</p>", 400) + "</body></html>",
+		Body:     strings.Repeat("Newsletter paragraph with some content.\n", 400),
+		Headers:  map[string]string{"X-Campaign": "spring"},
+	}
+}
+
+// BenchmarkMessageBody measures building a request body from scratch per send
+func BenchmarkMessageBody(b *testing.B) {
+	msg := campaignMessage()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		msg.To = []string{generateRecipient(i)}
+		if _, err := json.Marshal(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPreparedMessageBody measures patching recipients into a prepared body
+func BenchmarkPreparedMessageBody(b *testing.B) {
+	c, err := NewClient("https://postal.example.com", "test-key")
+	if err != nil {
+		b.Fatalf("failed to create client: %v", err)
+	}
+	prepared, err := c.Prepare(campaignMessage())
+	if err != nil {
+		b.Fatalf("Prepare() error = %v", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var buf bytes.Buffer
+	for i := 0; i < b.N; i++ {
+		recipients, err := json.Marshal([]string{generateRecipient(i)})
+		if err != nil {
+			b.Fatal(err)
+		}
+		buf.Reset()
+		prepared.writeTail(&buf, recipients)
+	}
+}
+
+// newBenchmarkOfflineClient returns a client whose middleware answers every
+// request without touching the network, after reading the body
+func newBenchmarkOfflineClient(b *testing.B) Client {
+	response := []byte(`{"message_id": "bulk", "status": "success"}`)
+	client, err := NewClient("https://postal.example.com", "test-key", WithMiddleware(func(http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			io.Copy(io.Discard, r.Body)
+			r.Body.Close()
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader(response)),
+			}, nil
+		})
+	}))
+	if err != nil {
+		b.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+// bulkFile is the attachment used by the bulk benchmarks
+var bulkFile = BulkFile{Name: "brochure.pdf", ContentType: "application/pdf", Data: bytes.Repeat([]byte{0x25}, 100<<10)}
+
+// BenchmarkBulkSend measures allocations per send in bulk mode
+func BenchmarkBulkSend(b *testing.B) {
+	client := newBenchmarkOfflineClient(b)
+	bulk, err := NewBulk(client, campaignMessage(), []BulkFile{bulkFile})
+	if err != nil {
+		b.Fatalf("NewBulk() error = %v", err)
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := bulk.prepared.Send(ctx, []string{generateRecipient(i)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBulkSendMessage measures the same sends through SendMessage
+func BenchmarkBulkSendMessage(b *testing.B) {
+	client := newBenchmarkOfflineClient(b)
+	msg := campaignMessage()
+	msg.Attachments = []types.Attachment{{
+		Name:        bulkFile.Name,
+		ContentType: bulkFile.ContentType,
+		Data:        base64.StdEncoding.EncodeToString(bulkFile.Data),
+	}}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		msg.To = []string{generateRecipient(i)}
+		if _, err := client.SendMessage(ctx, msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestBulk(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]types.Message)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= 0 {
+			t.Errorf("ContentLength = %d, want the body length", r.ContentLength)
+		}
+		var msg types.Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg.To[0] == "fail@example.com" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"code": "ValidationError", "message": "rejected"}`))
+			return
+		}
+		mu.Lock()
+		received[msg.To[0]] = msg
+		mu.Unlock()
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "bulk-1", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key", WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	bulk, err := NewBulk(c, compatTestMessage(), []BulkFile{
+		{Name: "a.txt", ContentType: "text/plain", Data: []byte("shared attachment")},
+	})
+	if err != nil {
+		t.Fatalf("NewBulk() error = %v", err)
+	}
+	bulk.Concurrency = 3
+
+	recipients := [][]string{
+		{"a@example.com"}, {"b@example.com"}, {"fail@example.com"}, {"c@example.com"}, {"not-an-address"},
+	}
+	results := bulk.Send(context.Background(), recipients)
+
+	for i, r := range results {
+		if r.To[0] != recipients[i][0] {
+			t.Errorf("result %d is for %v, want %v", i, r.To, recipients[i])
+		}
+		wantErr := i == 2 || i == 4
+		if (r.Err != nil) != wantErr {
+			t.Errorf("result %d error = %v, want error %v", i, r.Err, wantErr)
+		}
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("server received %d messages, want 3", len(received))
+	}
+	want := base64.StdEncoding.EncodeToString([]byte("shared attachment"))
+	for to, msg := range received {
+		if len(msg.Attachments) != 1 || msg.Attachments[0].Data != want {
+			t.Errorf("%s attachments = %+v", to, msg.Attachments)
+		}
+	}
+}
+
+func TestBulkResultsWriteCSV(t *testing.T) {
+	started := time.Date(2024, 5, 1, 9, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	results := BulkResults{
+		{
+			To:       []string{"a@example.com", "b@example.com"},
+			Result:   &types.Result{MessageID: "101", Status: "success", Data: map[string]interface{}{"sent_at": "2024-05-01T07:00:01Z"}},
+			Started:  started,
+			Finished: started.Add(250 * time.Millisecond),
+		},
+		{
+			To:  []string{"fail@example.com"},
+			Err: errors.New("rejected"),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := results.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("WriteCSV() output is not valid CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"recipient", "message_id", "status", "error", "started_at", "finished_at", "sent_at"},
+		{"a@example.com", "101", "success", "", "2024-05-01T07:00:00Z", "2024-05-01T07:00:00.25Z", "2024-05-01T07:00:01Z"},
+		{"b@example.com", "101", "success", "", "2024-05-01T07:00:00Z", "2024-05-01T07:00:00.25Z", "2024-05-01T07:00:01Z"},
+		{"fail@example.com", "", "", "rejected", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("WriteCSV() rows =\n%v\nwant\n%v", rows, want)
+	}
+}
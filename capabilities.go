@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// Capabilities implements Client
+func (c *clientImpl) Capabilities(ctx context.Context) (*types.Capabilities, error) {
+	key := c.effectiveAPIKey(ctx)
+
+	c.capsMu.Lock()
+	defer c.capsMu.Unlock()
+
+	if caps, ok := c.caps[key]; ok {
+		return caps, nil
+	}
+
+	caps := &types.Capabilities{Supported: make(map[types.Capability]bool, len(types.AllCapabilities))}
+	for _, capability := range types.AllCapabilities {
+		req := &transport.Request{Method: http.MethodGet, Path: string(capability)}
+		c.applyContextHeaders(ctx, req)
+		if c.tenant != nil {
+			c.tenantRequest(req)
+		}
+		status, err := c.transport.Probe(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to probe %s: %w", capability, err)
+		}
+		// 405 means the endpoint exists but only for POST
+		caps.Supported[capability] = status == http.StatusMethodNotAllowed || !transport.IsMissingEndpoint(status)
+	}
+
+	if c.caps == nil {
+		c.caps = make(map[string]*types.Capabilities)
+	}
+	c.caps[key] = caps
+	return caps, nil
+}
+
+// requireCapability rejects req without sending it when its endpoint was
+// probed and found unsupported. Until Capabilities is called requests are
+// sent, and optional ones report a missing endpoint from the response.
+func (c *clientImpl) requireCapability(ctx context.Context, req *transport.Request) error {
+	c.capsMu.Lock()
+	caps := c.caps[c.effectiveAPIKey(ctx)]
+	c.capsMu.Unlock()
+
+	if caps == nil {
+		return nil
+	}
+	capability := types.Capability(req.Path)
+	if _, probed := caps.Supported[capability]; !probed {
+		return nil
+	}
+	return caps.Require(capability)
+}
+
+// effectiveAPIKey returns the API key requests made with ctx are sent with
+func (c *clientImpl) effectiveAPIKey(ctx context.Context) string {
+	if key, ok := types.APIKeyFromContext(ctx); ok {
+		return key
+	}
+	return c.apiKey
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestCapabilities(t *testing.T) {
+	var probes int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		if r.Method != http.MethodGet || r.ContentLength != 0 {
+			t.Errorf("probe %s %s with %d byte body, want a bodyless GET", r.Method, r.URL.Path, r.ContentLength)
+		}
+		switch r.URL.Path {
+		case "/api/v1/send/message":
+			w.WriteHeader(200)
+			w.Write([]byte(`{"status": "parameter-error", "data": {"code": "ValidationError"}}`))
+		case "/api/v1/send/raw":
+			w.WriteHeader(405)
+		case "/api/v1/messages/message":
+			w.WriteHeader(501)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	caps, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+
+	want := map[types.Capability]bool{
+		types.CapabilitySendMessage:    true,
+		types.CapabilitySendRaw:        true,
+		types.CapabilityMessageDetails: false,
+		types.CapabilityDeliveries:     false,
+	}
+	for capability, supported := range want {
+		if got := caps.Supports(capability); got != supported {
+			t.Errorf("Supports(%s) = %v, want %v", capability, got, supported)
+		}
+	}
+	if err := caps.Require(types.CapabilityDeliveries); !errors.Is(err, types.ErrNotSupported) {
+		t.Errorf("Require(deliveries) error = %v, want ErrNotSupported", err)
+	}
+
+	// Second call is served from cache
+	if _, err := client.Capabilities(context.Background()); err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&probes); got != int32(len(types.AllCapabilities)) {
+		t.Errorf("server received %d probes, want %d", got, len(types.AllCapabilities))
+	}
+}
+
+func TestCapabilitiesProbeFailure(t *testing.T) {
+	client, err := NewClient("http://127.0.0.1:1", "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.Capabilities(context.Background()); err == nil {
+		t.Error("expected error when server is unreachable")
+	}
+}
+
+func TestCapabilitiesCredentials(t *testing.T) {
+	var mu sync.Mutex
+	keys := map[string]int{}
+	var traces []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys[r.Header.Get("X-Server-API-Key")]++
+		traces = append(traces, r.Header.Get("X-Trace-ID"))
+		mu.Unlock()
+		// Only the tenant's server has the deliveries endpoint
+		if r.URL.Path == "/api/v1/messages/deliveries" && r.Header.Get("X-Server-API-Key") != "tenant-key" {
+			w.WriteHeader(404)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "parent-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.ContextHeaders = []ContextHeader{{Key: ctxKey("trace"), Header: "X-Trace-ID"}}
+	client.WithConfig(cfg)
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "abc123")
+
+	tenant := client.ForTenant("tenant-key", TenantDefaults{Name: "acme"})
+	caps, err := tenant.Capabilities(ctx)
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	if !caps.Supports(types.CapabilityDeliveries) {
+		t.Error("tenant view did not probe with its own key")
+	}
+
+	caps, err = client.Capabilities(types.ContextWithAPIKey(ctx, "other-key"))
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	if caps.Supports(types.CapabilityDeliveries) {
+		t.Error("context API key override was not probed with")
+	}
+
+	n := len(types.AllCapabilities)
+	if keys["tenant-key"] != n || keys["other-key"] != n || keys["parent-key"] != 0 {
+		t.Errorf("probes by key = %v, want %d each for tenant-key and other-key", keys, n)
+	}
+	for _, trace := range traces {
+		if trace != "abc123" {
+			t.Errorf("probe X-Trace-ID = %q, want the context header", trace)
+		}
+	}
+}
+
+func TestCapabilitiesRequire(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/api/v1/messages/deliveries" {
+			w.WriteHeader(404)
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"status": "success", "data": {"id": 1}}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.Capabilities(ctx); err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	probes := atomic.LoadInt32(&requests)
+
+	// Unsupported endpoints fail without a request once probed
+	if _, err := client.GetDeliveries(ctx, 1); !errors.Is(err, types.ErrNotSupported) {
+		t.Errorf("GetDeliveries() error = %v, want ErrNotSupported", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != probes {
+		t.Errorf("GetDeliveries() sent %d requests, want none", got-probes)
+	}
+	if _, err := client.GetMessage(ctx, 1); err != nil {
+		t.Errorf("GetMessage() error = %v", err)
+	}
+
+	// Another API key has not been probed, so its requests are sent
+	if _, err := client.GetDeliveries(types.ContextWithAPIKey(ctx, "other-key"), 1); !errors.Is(err, types.ErrEndpointNotSupported) {
+		t.Errorf("GetDeliveries() with other key error = %v, want ErrEndpointNotSupported", err)
+	}
+}
@@ -3,26 +3,92 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/mail"
+	"strings"
+	"sync"
 
+	"github.com/sachin-duhan/postal-go/clock"
+	"github.com/sachin-duhan/postal-go/common/privacy"
 	"github.com/sachin-duhan/postal-go/common/types"
 	"github.com/sachin-duhan/postal-go/common/validation"
+	"github.com/sachin-duhan/postal-go/internal/middleware"
 	"github.com/sachin-duhan/postal-go/internal/transport"
+	"github.com/sachin-duhan/postal-go/reporting"
+	"github.com/sachin-duhan/postal-go/resultstore"
 )
 
+// MessageSender sends messages built from fields. Code that only sends
+// mail can depend on it instead of Client, and mock it with one method.
+type MessageSender interface {
+	// SendMessage sends an email using the message builder pattern
+	SendMessage(ctx context.Context, msg *types.Message, opts ...SendOption) (*types.Result, error)
+}
+
+// RawSender sends pre-formatted MIME messages
+type RawSender interface {
+	// SendRawMessage sends a pre-formatted email message, base64 encoding
+	// its mail as send/raw expects
+	SendRawMessage(ctx context.Context, raw *types.RawMessage, opts ...SendOption) (*types.Result, error)
+}
+
+// MessageFetcher looks up messages the server has stored
+type MessageFetcher interface {
+	// GetMessage returns the message with the given ID, including the
+	// sections selected by expansions
+	GetMessage(ctx context.Context, id int, expansions ...types.MessageExpansion) (*types.MessageDetails, error)
+
+	// GetDeliveries returns the delivery attempts made for a message, oldest
+	// first
+	GetDeliveries(ctx context.Context, messageID int) ([]types.Delivery, error)
+}
+
 // Client represents the interface for interacting with the Postal API
 type Client interface {
-	// SendMessage sends an email using the message builder pattern
-	SendMessage(ctx context.Context, msg *types.Message) (*types.Result, error)
+	MessageSender
+	RawSender
+	MessageFetcher
+
+	// SendRawMessageFrom streams pre-built MIME content from r, base64
+	// encoding it on the fly instead of holding it in memory as a string
+	SendRawMessageFrom(ctx context.Context, r io.Reader, env types.Envelope, opts ...SendOption) (*types.Result, error)
+
+	// Explain returns the request SendMessage would make for msg, with its
+	// validation findings, without sending it
+	Explain(ctx context.Context, msg *types.Message, opts ...SendOption) (*Explanation, error)
 
-	// SendRawMessage sends a pre-formatted email message
-	SendRawMessage(ctx context.Context, raw *types.RawMessage) (*types.Result, error)
+	// Prepare marshals msg once for repeated sends to different recipients
+	Prepare(msg *types.Message, opts ...SendOption) (*PreparedMessage, error)
 
 	// WithMiddleware adds middleware to the client
 	WithMiddleware(middleware ...Middleware) Client
 
 	// WithConfig updates the client configuration
 	WithConfig(cfg *Config) Client
+
+	// Do calls an endpoint the client does not wrap, with the client's
+	// authentication, middleware, retries and error mapping. Path is
+	// relative to the API prefix, e.g. "messages/message". When out is non-nil
+	// the response's data field is decoded into it; otherwise it is returned
+	// in Result.Data.
+	Do(ctx context.Context, method, path string, body, out interface{}) (*types.Result, error)
+
+	// Capabilities probes which optional endpoints the server supports,
+	// with GET requests under the API key requests made with ctx use. The
+	// result is cached per key after the first successful probe, and
+	// requests to endpoints found unsupported then fail with
+	// ErrNotSupported without being sent.
+	Capabilities(ctx context.Context) (*types.Capabilities, error)
+
+	// ForTenant returns a lightweight view of the client that shares its
+	// transport and connection pool but sends with a different API key
+	ForTenant(apiKey string, defaults TenantDefaults) Client
+
+	// Clone returns an independent client that shares the HTTP connection
+	// pool but has its own configuration and middleware
+	Clone(opts ...Option) Client
 }
 
 // clientImpl is the concrete implementation of the Client interface
@@ -33,6 +99,44 @@ type clientImpl struct {
 	config     *Config
 	middleware []Middleware
 	transport  *transport.Transport
+
+	// caps holds probed capabilities by API key
+	capsMu sync.Mutex
+	caps   map[string]*types.Capabilities
+
+	// tenant is set on views created by ForTenant
+	tenant *TenantDefaults
+	// viewMiddleware holds middleware added to a tenant view. It is applied
+	// per call because the transport is shared with the parent client.
+	viewMiddleware []middleware.Middleware
+	// quota limits sends made through a tenant view
+	quota *quota
+
+	// profiles holds the sender profiles registered with WithSenderProfile
+	profiles map[string]SenderProfile
+
+	// budget, when set, records the outcome of every send
+	budget *ErrorBudget
+	// resultStore, when set, saves a record of every send
+	resultStore resultstore.Store
+	// domainStats, when set, counts every send per recipient domain
+	domainStats *reporting.DomainStats
+
+	// inflight limits concurrent sends to Config.MaxConcurrency; nil means
+	// no limit. Tenant views share their parent's.
+	inflight chan struct{}
+
+	// clock times retries, quotas and send records; nil means clock.Real
+	clock clock.Clock
+	// random draws retry jitter; nil means the math/rand global source
+	random *lockedRand
+
+	// keyProvider and signingHeaders record the single-use options applied
+	// by NewClient so conflicting ones can be reported
+	keyProvider    bool
+	signingHeaders map[string]bool
+	// conflicts describes options that cannot be combined
+	conflicts []string
 }
 
 // NewClient creates a new Postal API client
@@ -55,52 +159,257 @@ func NewClient(baseURL, apiKey string, opts ...Option) (Client, error) {
 	for _, opt := range opts {
 		opt(client)
 	}
+	problems := append(client.conflicts, client.config.problems()...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", types.ErrInvalidConfig, strings.Join(problems, "; "))
+	}
+	client.applyConfig()
 
 	return client, nil
 }
 
+// MustNewClient is like NewClient but panics if the client cannot be
+// created. It simplifies initialization in var blocks and DI containers.
+func MustNewClient(baseURL, apiKey string, opts ...Option) Client {
+	c, err := NewClient(baseURL, apiKey, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("postal: MustNewClient(%q): %v", baseURL, err))
+	}
+	return c
+}
+
 // SendMessage implements Client
-func (c *clientImpl) SendMessage(ctx context.Context, msg *types.Message) (*types.Result, error) {
+func (c *clientImpl) SendMessage(ctx context.Context, msg *types.Message, opts ...SendOption) (*types.Result, error) {
+	o := collectSendOptions(opts)
+	msg, err := c.profileDefaults(msg, o.profile)
+	if err != nil {
+		return nil, err
+	}
+	msg = c.messageDefaults(msg)
+	if o.correlationID != "" {
+		withID := *msg
+		withID.Headers = o.correlationHeaders(msg.Headers)
+		msg = &withID
+	}
 	if err := validation.ValidateMessage(msg); err != nil {
+		return nil, c.redactError(err, messageIdentifiers(msg)...)
+	}
+	if err := validation.ValidateMessageSize(msg, c.sizeLimits()); err != nil {
 		return nil, err
 	}
-
-	req := &transport.Request{
-		Method: http.MethodPost,
-		Path:   "send/message",
-		Body:   msg,
+	c.warn("send/message", validation.AlignmentWarning(msg.From, c.verifiedDomains()))
+	for _, att := range msg.Attachments {
+		c.warn("send/message", validation.AttachmentNameWarnings(att.Name)...)
 	}
 
-	return c.transport.Do(ctx, req)
+	req, err := c.messageRequest(msg, o)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendAndRecord(ctx, req, func() *resultstore.Record {
+		r := messageRecord(msg)
+		r.Path = req.Path
+		return r
+	})
 }
 
 // SendRawMessage implements Client
-func (c *clientImpl) SendRawMessage(ctx context.Context, raw *types.RawMessage) (*types.Result, error) {
-	if err := validation.ValidateRawMessage(raw); err != nil {
+func (c *clientImpl) SendRawMessage(ctx context.Context, raw *types.RawMessage, opts ...SendOption) (*types.Result, error) {
+	o := collectSendOptions(opts)
+	raw, err := c.rawProfileDefaults(raw, o.profile)
+	if err != nil {
 		return nil, err
 	}
-
-	req := &transport.Request{
-		Method: http.MethodPost,
-		Path:   "send/raw",
-		Body:   raw,
+	raw = c.rawMessageDefaults(raw)
+	if o.correlationID != "" {
+		withID := *raw
+		withID.Headers = o.correlationHeaders(raw.Headers)
+		raw = &withID
+	}
+	if err := validation.ValidateRawMessage(raw); err != nil {
+		return nil, c.redactError(err, append([]string{raw.From}, raw.To...)...)
 	}
+	if err := validation.ValidateRawMessageSize(raw, c.sizeLimits()); err != nil {
+		return nil, err
+	}
+	c.warn("send/raw", validation.RawMessageWarnings(raw)...)
+	c.warn("send/raw", validation.AlignmentWarning(rawHeaderFrom(raw), c.verifiedDomains()))
 
-	return c.transport.Do(ctx, req)
+	return c.sendAndRecord(ctx, c.newRequest(http.MethodPost, "send/raw", encodeRaw(raw), o), func() *resultstore.Record {
+		return rawRecord(raw.To, raw.From)
+	})
 }
 
 // WithMiddleware implements Client
-func (c *clientImpl) WithMiddleware(middleware ...Middleware) Client {
-	c.middleware = append(c.middleware, middleware...)
+func (c *clientImpl) WithMiddleware(mws ...Middleware) Client {
+	c.middleware = append(c.middleware, mws...)
+	for _, m := range mws {
+		if c.tenant != nil {
+			c.viewMiddleware = append(c.viewMiddleware, middleware.Middleware(m))
+			continue
+		}
+		c.transport.AddMiddleware(middleware.Middleware(m))
+	}
 	return c
 }
 
-// WithConfig implements Client
+// WithConfig implements Client. On tenant views only client-side settings
+// (retries, operation timeout, logging) take effect; transport settings
+// belong to the parent client.
 func (c *clientImpl) WithConfig(cfg *Config) Client {
 	c.config = cfg
-	c.httpClient.Timeout = cfg.Timeout
+	if c.tenant == nil {
+		c.applyConfig()
+	}
 	return c
 }
 
+// Clone implements Client. Clones of tenant views remain views of the same
+// parent transport.
+func (c *clientImpl) Clone(opts ...Option) Client {
+	cfg := *c.config
+	clone := &clientImpl{
+		baseURL:        c.baseURL,
+		apiKey:         c.apiKey,
+		httpClient:     c.httpClient,
+		config:         &cfg,
+		middleware:     append([]Middleware(nil), c.middleware...),
+		transport:      c.transport,
+		tenant:         c.tenant,
+		viewMiddleware: append([]middleware.Middleware(nil), c.viewMiddleware...),
+		quota:          c.quota,
+		profiles:       c.profiles,
+		budget:         c.budget,
+		resultStore:    c.resultStore,
+		domainStats:    c.domainStats,
+		inflight:       c.inflight,
+		clock:          c.clock,
+		random:         c.random,
+	}
+
+	if c.tenant == nil {
+		// A full clone limits its own sends; applyConfig creates the slots
+		clone.inflight = nil
+		clone.httpClient = &http.Client{
+			Transport:     c.httpClient.Transport,
+			CheckRedirect: c.httpClient.CheckRedirect,
+			Timeout:       c.httpClient.Timeout,
+		}
+		clone.transport = c.transport.Clone(clone.httpClient)
+	}
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+	if clone.tenant == nil {
+		clone.applyConfig()
+	}
+
+	return clone
+}
+
+// applyConfig pushes the current configuration down to the transport
+func (c *clientImpl) applyConfig() {
+	if c.config.MaxConcurrency <= 0 {
+		c.inflight = nil
+	} else if cap(c.inflight) != c.config.MaxConcurrency {
+		c.inflight = make(chan struct{}, c.config.MaxConcurrency)
+	}
+	c.transport.SetTimeout(c.config.Timeout)
+	c.transport.SetMaxRedirects(c.config.MaxRedirects)
+	c.transport.SetMaxResponseSize(c.config.MaxResponseSize)
+	c.transport.SetStrictDecode(c.config.StrictDecode)
+	c.transport.SetCompatibility(transport.Compatibility{
+		PathPrefix:   c.config.APIPathPrefix,
+		FieldAliases: c.config.FieldAliases,
+		Format:       responseFormats[c.config.Compatibility],
+	})
+
+	var debugLogger *log.Logger
+	if c.config.Debug {
+		debugLogger = c.logger()
+	}
+	c.transport.SetDebugLogger(debugLogger)
+}
+
+// responseFormats maps compatibility modes onto transport response formats
+var responseFormats = map[CompatibilityMode]transport.ResponseFormat{
+	CompatibilityAuto:     transport.FormatAuto,
+	CompatibilityFlat:     transport.FormatFlat,
+	CompatibilityEnvelope: transport.FormatEnvelope,
+}
+
+// warn logs non-fatal problems with a send when debug output is enabled
+func (c *clientImpl) warn(path string, warnings ...string) {
+	if !c.config.Debug {
+		return
+	}
+	for _, warning := range warnings {
+		if warning != "" {
+			if c.config.PrivacyMode {
+				warning = privacy.Redact(warning)
+			}
+			c.logger().Printf("[WARN] %s: %s", path, warning)
+		}
+	}
+}
+
+// redactError hides recipient addresses and the known values in err when
+// privacy mode is on
+func (c *clientImpl) redactError(err error, known ...string) error {
+	if !c.config.PrivacyMode {
+		return err
+	}
+	return privacy.RedactError(err, known...)
+}
+
+// messageIdentifiers returns the subject and addresses of msg, which privacy
+// mode redacts even when they are not well-formed addresses
+func messageIdentifiers(msg *types.Message) []string {
+	ids := []string{msg.Subject, msg.From, msg.Sender, msg.ReplyTo}
+	ids = append(ids, msg.To...)
+	ids = append(ids, msg.CC...)
+	return append(ids, msg.BCC...)
+}
+
+// sizeLimits returns the configured encoded size limits
+func (c *clientImpl) sizeLimits() validation.SizeLimits {
+	return validation.SizeLimits{MaxAttachmentSize: c.config.MaxAttachmentSize, MaxMessageSize: c.config.MaxMessageSize}
+}
+
+// verifiedDomains returns Config.VerifiedDomains plus the sender profiles'
+// domains, or nil when no verified domains are configured
+func (c *clientImpl) verifiedDomains() []string {
+	if len(c.config.VerifiedDomains) == 0 {
+		return nil
+	}
+	domains := append([]string(nil), c.config.VerifiedDomains...)
+	for _, p := range c.profiles {
+		if domain := validation.AddressDomain(p.From); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// rawHeaderFrom returns the From header of raw mail, falling back to the
+// envelope sender
+func rawHeaderFrom(raw *types.RawMessage) string {
+	if msg, err := mail.ReadMessage(strings.NewReader(raw.Mail)); err == nil {
+		if from := msg.Header.Get("From"); from != "" {
+			return from
+		}
+	}
+	return raw.From
+}
+
+// logger returns the configured logger or the standard logger
+func (c *clientImpl) logger() *log.Logger {
+	if c.config.Logger != nil {
+		return c.config.Logger
+	}
+	return log.Default()
+}
+
 // Ensure clientImpl implements Client interface
 var _ Client = (*clientImpl)(nil)
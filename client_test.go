@@ -1,8 +1,10 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -58,6 +60,22 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestMustNewClient(t *testing.T) {
+	if c := MustNewClient("https://postal.example.com", "test-api-key"); c == nil {
+		t.Fatal("MustNewClient() returned nil")
+	}
+
+	defer func() {
+		r := recover()
+		msg, _ := r.(string)
+		if !contains(msg, "MustNewClient") || !contains(msg, "invalid base URL") {
+			t.Errorf("panic = %v, want a message describing the invalid URL", r)
+		}
+	}()
+	MustNewClient("://invalid-url", "test-api-key")
+	t.Error("MustNewClient() with an invalid URL did not panic")
+}
+
 func TestSendMessage(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -344,6 +362,188 @@ func TestClientWithMiddleware(t *testing.T) {
 	}
 }
 
+func TestClientMiddlewareApplied(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Middleware") != "applied" {
+			t.Error("expected header set by middleware")
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12350", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	calls := 0
+	client.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			r.Header.Set("X-Middleware", "applied")
+			return next.RoundTrip(r)
+		})
+	})
+
+	msg := &types.Message{
+		To:       []string{"recipient@example.com"},
+		From:     "sender@example.com",
+		Subject:  "Test Subject",
+		HTMLBody: "Test Body",
+	}
+	if _, err := client.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("middleware called %d times, want 1", calls)
+	}
+}
+
+func TestClientDebugSchemaLogging(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12352", "status": "success", "unexpected": 1}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cfg := DefaultConfig()
+	cfg.Debug = true
+	cfg.Logger = log.New(&buf, "", 0)
+	client.WithConfig(cfg)
+
+	msg := &types.Message{
+		To:       []string{"recipient@example.com"},
+		From:     "sender@example.com",
+		Subject:  "Test Subject",
+		HTMLBody: "Test Body",
+	}
+	if _, err := client.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if !contains(buf.String(), `unknown field "unexpected"`) {
+		t.Errorf("expected schema mismatch in debug log, got %q", buf.String())
+	}
+}
+
+func TestClientRawMessageWarnings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12353", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cfg := DefaultConfig()
+	cfg.Debug = true
+	cfg.Logger = log.New(&buf, "", 0)
+	client.WithConfig(cfg)
+
+	raw := &types.RawMessage{
+		Mail: "From: sender@example.com\r\nTo: recipient@example.com\r\nSubject: Test\r\n\r\nBody",
+		To:   []string{"recipient@example.com", "bcc@example.com"},
+		From: "sender@example.com",
+	}
+	if _, err := client.SendRawMessage(context.Background(), raw); err != nil {
+		t.Fatalf("SendRawMessage() error = %v", err)
+	}
+	if !contains(buf.String(), "[WARN] send/raw: envelope recipient bcc@example.com") {
+		t.Errorf("expected envelope warning in debug log, got %q", buf.String())
+	}
+}
+
+func TestClientSizeLimits(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"message_id": "12354", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.MaxAttachmentSize = 8
+	cfg.MaxMessageSize = 64
+	client.WithConfig(cfg)
+
+	msg := compatTestMessage()
+	msg.Attachments = []types.Attachment{{Name: "a.txt", ContentType: "text/plain", Data: "SGVsbG8gd29ybGQ="}}
+	if _, err := client.SendMessage(context.Background(), msg); !errors.Is(err, types.ErrMessageTooLarge) {
+		t.Errorf("SendMessage() error = %v, want ErrMessageTooLarge", err)
+	}
+	if _, err := client.SendRawMessage(context.Background(), rawTestMessage()); !errors.Is(err, types.ErrMessageTooLarge) {
+		t.Errorf("SendRawMessage() error = %v, want ErrMessageTooLarge", err)
+	}
+	if _, err := client.Prepare(msg); !errors.Is(err, types.ErrMessageTooLarge) {
+		t.Errorf("Prepare() error = %v, want ErrMessageTooLarge", err)
+	}
+	files := []BulkFile{{Name: "b.txt", ContentType: "text/plain", Data: []byte("Hello world")}}
+	if _, err := NewBulk(client, compatTestMessage(), files); !errors.Is(err, types.ErrMessageTooLarge) {
+		t.Errorf("NewBulk() error = %v, want ErrMessageTooLarge", err)
+	}
+	if requests != 0 {
+		t.Errorf("server got %d requests, want 0", requests)
+	}
+}
+
+func TestClientPrivacyMode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code": "InvalidRecipient", "message": "recipient@example.com is suppressed"}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	client, err := NewClient(ts.URL, "test-key",
+		WithPrivacyMode(true),
+		WithMaxRetries(0),
+		WithDebug(true),
+		WithLogger(log.New(&buf, "", 0)),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	msg := compatTestMessage()
+	msg.To = []string{"not-an-address", "recipient@example.com"}
+	msg.From = "sender@"
+	_, err = client.SendMessage(ctx, msg)
+	if !errors.Is(err, types.ErrInvalidMessage) || contains(err.Error(), "not-an-address") || contains(err.Error(), "sender@") {
+		t.Errorf("SendMessage() validation error = %v, want redacted ErrInvalidMessage", err)
+	}
+
+	_, err = client.SendMessage(ctx, compatTestMessage())
+	var postalErr *types.PostalError
+	if !errors.As(err, &postalErr) || contains(postalErr.Message, "recipient@example.com") {
+		t.Errorf("SendMessage() API error = %v, want redacted PostalError", err)
+	}
+
+	raw := &types.RawMessage{
+		Mail: "From: sender@example.com\r\nTo: recipient@example.com\r\nSubject: Test\r\n\r\nBody",
+		To:   []string{"bcc@example.com"},
+		From: "sender@example.com",
+	}
+	client.SendRawMessage(ctx, raw)
+	if !contains(buf.String(), "[WARN] send/raw: envelope recipient anon-") || contains(buf.String(), "bcc@example.com") {
+		t.Errorf("expected redacted envelope warning in debug log, got %q", buf.String())
+	}
+}
+
 func TestConcurrentSending(t *testing.T) {
 	// Create test server
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -386,6 +586,62 @@ func TestConcurrentSending(t *testing.T) {
 	}
 }
 
+func TestMaxConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Write([]byte(`{"message_id": "12349", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	for _, mode := range []ConcurrencyMode{ConcurrencyWait, ConcurrencyFail} {
+		client, err := NewClient(ts.URL, "test-key")
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		cfg := DefaultConfig()
+		cfg.MaxConcurrency = 2
+		cfg.ConcurrencyMode = mode
+		client.WithConfig(cfg)
+
+		errs := make(chan error, 3)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := client.SendMessage(context.Background(), compatTestMessage())
+				errs <- err
+			}()
+		}
+		<-started
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err = client.SendMessage(ctx, compatTestMessage())
+		cancel()
+		want := context.DeadlineExceeded
+		if mode == ConcurrencyFail {
+			want = types.ErrTooManyInFlight
+		}
+		if !errors.Is(err, want) {
+			t.Errorf("mode %d: third send error = %v, want %v", mode, err, want)
+		}
+		select {
+		case <-started:
+			t.Errorf("mode %d: third send reached the server", mode)
+		default:
+		}
+
+		release <- struct{}{}
+		release <- struct{}{}
+		for i := 0; i < 2; i++ {
+			if err := <-errs; err != nil {
+				t.Errorf("mode %d: send error = %v", mode, err)
+			}
+		}
+	}
+}
+
 func TestContextCancellation(t *testing.T) {
 	// Create test server with delay
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -434,3 +690,37 @@ type roundTripperFunc func(*http.Request) (*http.Response, error)
 func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
 	return f(r)
 }
+
+// stubSender records messages instead of sending them
+type stubSender struct {
+	subjects []string
+}
+
+func (s *stubSender) SendMessage(ctx context.Context, msg *types.Message, opts ...SendOption) (*types.Result, error) {
+	s.subjects = append(s.subjects, msg.Subject)
+	return &types.Result{MessageID: "stub"}, nil
+}
+
+func TestNarrowInterfaces(t *testing.T) {
+	notify := func(s MessageSender) error {
+		_, err := s.SendMessage(context.Background(), compatTestMessage())
+		return err
+	}
+
+	stub := &stubSender{}
+	if err := notify(stub); err != nil {
+		t.Fatalf("notify() error = %v", err)
+	}
+	if len(stub.subjects) != 1 {
+		t.Errorf("stub received %d messages, want 1", len(stub.subjects))
+	}
+
+	// A Client can be passed wherever a narrow interface is expected
+	c, err := NewClient("https://postal.example.com", "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	var _ MessageSender = c
+	var _ RawSender = c
+	var _ MessageFetcher = c
+}
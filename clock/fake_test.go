@@ -0,0 +1,75 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeAdvance(t *testing.T) {
+	f := NewFake(start)
+	short := f.NewTimer(time.Second)
+	long := f.NewTimer(time.Minute)
+
+	f.Advance(500 * time.Millisecond)
+	select {
+	case <-short.C():
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	f.Advance(time.Second)
+	select {
+	case at := <-short.C():
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("timer fired with %v, want its deadline", at)
+		}
+	default:
+		t.Fatal("timer did not fire at its deadline")
+	}
+	if got := f.Now(); !got.Equal(start.Add(1500 * time.Millisecond)) {
+		t.Errorf("Now() = %v", got)
+	}
+
+	if !long.Stop() {
+		t.Error("Stop() of a pending timer = false")
+	}
+	if long.Stop() {
+		t.Error("Stop() of a stopped timer = true")
+	}
+	f.Advance(time.Hour)
+	select {
+	case <-long.C():
+		t.Error("stopped timer fired")
+	default:
+	}
+	if f.Pending() != 0 {
+		t.Errorf("Pending() = %d, want 0", f.Pending())
+	}
+}
+
+func TestFakeZeroTimer(t *testing.T) {
+	f := NewFake(start)
+	select {
+	case <-f.NewTimer(0).C():
+	default:
+		t.Error("zero duration timer did not fire immediately")
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	f := NewFake(start)
+	done := make(chan time.Time)
+	go func() {
+		done <- <-f.NewTimer(24 * time.Hour).C()
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(24 * time.Hour)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutine waiting on the fake timer was not woken")
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestClone(t *testing.T) {
+	var headers []http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12360", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	setHeader := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				r.Header.Set(name, "1")
+				return next.RoundTrip(r)
+			})
+		}
+	}
+
+	pool := &http.Transport{}
+	parent, err := NewClient(ts.URL, "test-key", WithMiddleware(setHeader("X-Parent")))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	parent.(*clientImpl).httpClient.Transport = pool
+
+	clone := parent.Clone(WithMiddleware(setHeader("X-Clone")), WithMaxRetries(7))
+
+	ctx := context.Background()
+	if _, err := clone.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("clone SendMessage() error = %v", err)
+	}
+	if _, err := parent.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("parent SendMessage() error = %v", err)
+	}
+
+	if headers[0].Get("X-Parent") != "1" || headers[0].Get("X-Clone") != "1" {
+		t.Errorf("clone request headers = %v, want parent and clone middleware", headers[0])
+	}
+	if headers[1].Get("X-Clone") != "" {
+		t.Error("clone middleware leaked into parent")
+	}
+
+	if got := parent.(*clientImpl).config.MaxRetries; got != 3 {
+		t.Errorf("parent MaxRetries = %d, want unchanged 3", got)
+	}
+	if got := clone.(*clientImpl).config.MaxRetries; got != 7 {
+		t.Errorf("clone MaxRetries = %d, want 7", got)
+	}
+	if clone.(*clientImpl).httpClient.Transport != pool {
+		t.Error("clone does not share the parent's connection pool")
+	}
+}
+
+func TestCloneConfigIndependence(t *testing.T) {
+	parent, err := NewClient("https://postal.example.com", "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	clone := parent.Clone(WithTimeout(5 * time.Second))
+	if got := clone.(*clientImpl).transport.HTTPClient().Timeout; got != 5*time.Second {
+		t.Errorf("clone timeout = %v, want 5s", got)
+	}
+	if got := parent.(*clientImpl).transport.HTTPClient().Timeout; got != 30*time.Second {
+		t.Errorf("parent timeout = %v, want unchanged 30s", got)
+	}
+}
+
+func TestCloneTenantViewConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Write([]byte(`{"message_id": "12360", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	parent, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.MaxConcurrency = 1
+	cfg.ConcurrencyMode = ConcurrencyFail
+	parent.WithConfig(cfg)
+	clone := parent.ForTenant("tenant-key", TenantDefaults{Name: "acme"}).Clone()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := parent.SendMessage(context.Background(), compatTestMessage())
+		errs <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	_, err = clone.SendMessage(ctx, compatTestMessage())
+	cancel()
+	if !errors.Is(err, types.ErrTooManyInFlight) {
+		t.Errorf("clone of tenant view send error = %v, want ErrTooManyInFlight", err)
+	}
+	close(release)
+	if err := <-errs; err != nil {
+		t.Errorf("parent send error = %v", err)
+	}
+}
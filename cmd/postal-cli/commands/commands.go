@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+
+	"github.com/sachin-duhan/postal-go/deliverability"
+)
+
+// command is a postal-cli subcommand
+type command struct {
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) int
+}
+
+// resolver is used for DNS lookups; tests replace it
+var resolver deliverability.Resolver = net.DefaultResolver
+
+// registry holds the available subcommands by name
+var registry = map[string]command{
+	"doctor":       {usage: "check a sending domain's SPF, DKIM and DMARC records", run: runDoctor},
+	"placement":    {usage: "send probes to seed mailboxes and report inbox placement", run: runPlacement},
+	"send-eml":     {usage: "send a directory of .eml files via send/raw", run: runSendEML},
+	"webhook-test": {usage: "post a signed test event to a webhook endpoint", run: runWebhookTest},
+}
+
+// Run dispatches args to a subcommand and returns the process exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	cmd, ok := registry[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+// printUsage lists the available subcommands
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: postal-cli <command> [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, registry[name].usage)
+	}
+}
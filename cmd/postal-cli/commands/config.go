@@ -1 +1,30 @@
 package commands
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	client "github.com/sachin-duhan/postal-go"
+)
+
+// config holds the connection settings shared by all subcommands
+type config struct {
+	baseURL string
+	apiKey  string
+}
+
+// register adds the connection flags to fs, defaulting to POSTAL_URL and
+// POSTAL_API_KEY from the environment
+func (c *config) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.baseURL, "url", os.Getenv("POSTAL_URL"), "Postal server URL (env POSTAL_URL)")
+	fs.StringVar(&c.apiKey, "key", os.Getenv("POSTAL_API_KEY"), "server API key (env POSTAL_API_KEY)")
+}
+
+// newClient creates a Postal client from the configured settings
+func (c *config) newClient() (client.Client, error) {
+	if c.baseURL == "" || c.apiKey == "" {
+		return nil, errors.New("both -url and -key are required")
+	}
+	return client.NewClient(c.baseURL, c.apiKey)
+}
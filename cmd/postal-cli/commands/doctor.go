@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/sachin-duhan/postal-go/deliverability"
+)
+
+// runDoctor checks a sending domain's SPF, DKIM and DMARC records
+func runDoctor(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	selector := fs.String("selector", "", "DKIM selector to check (e.g. postal-AbCdEf)")
+	spfInclude := fs.String("spf-include", "", "include the SPF record must contain (e.g. spf.postal.example.com)")
+	dkimRecord := fs.String("dkim-record", "", "DKIM record Postal shows for the domain, to compare keys and suggest fixes")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: postal-cli doctor [flags] <domain>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	checker := &deliverability.Checker{Resolver: resolver, SPFInclude: *spfInclude, DKIMRecord: *dkimRecord}
+	report, err := checker.Check(context.Background(), fs.Arg(0), *selector)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "domain: %s\n", report.Domain)
+	for _, record := range []struct{ name, value string }{{"spf", report.SPF}, {"dkim", report.DKIM}, {"dmarc", report.DMARC}} {
+		if record.value != "" {
+			fmt.Fprintf(stdout, "%-6s %s\n", record.name+":", record.value)
+		}
+	}
+	for _, p := range report.Problems {
+		fmt.Fprintln(stdout, p)
+		if p.Fix != "" {
+			fmt.Fprintf(stdout, "  fix: %s\n", p.Fix)
+		}
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	fmt.Fprintln(stdout, "ok")
+	return 0
+}
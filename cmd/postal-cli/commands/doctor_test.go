@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/deliverability"
+)
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestDoctor(t *testing.T) {
+	defer func(r deliverability.Resolver) { resolver = r }(resolver)
+
+	tests := []struct {
+		name     string
+		records  fakeResolver
+		wantCode int
+		wantOut  []string
+	}{
+		{
+			name: "healthy",
+			records: fakeResolver{
+				"example.com":                   {"v=spf1 include:spf.postal.example.com -all"},
+				"postal._domainkey.example.com": {"v=DKIM1; p=abc"},
+				"_dmarc.example.com":            {"v=DMARC1; p=reject"},
+			},
+			wantCode: 0,
+			wantOut:  []string{"spf:   v=spf1 include:spf.postal.example.com -all", "ok"},
+		},
+		{
+			name:     "missing records",
+			records:  fakeResolver{},
+			wantCode: 1,
+			wantOut: []string{
+				"error spf: no SPF record found",
+				`  fix: example.com TXT "v=spf1 a mx include:spf.postal.example.com ~all"`,
+				"warning dmarc: no DMARC record found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver = tt.records
+
+			var stdout, stderr bytes.Buffer
+			args := []string{"doctor", "-selector", "postal", "-spf-include", "spf.postal.example.com", "example.com"}
+			if code := Run(args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("Run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("output %q does not contain %q", stdout.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	client "github.com/sachin-duhan/postal-go"
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// runSendEML walks a directory of .eml files and sends each via send/raw
+func runSendEML(args []string, stdout, stderr io.Writer) int {
+	var cfg config
+	fs := flag.NewFlagSet("send-eml", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cfg.register(fs)
+	concurrency := fs.Int("concurrency", 4, "number of messages sent in parallel")
+	from := fs.String("from", "", "envelope sender overriding each message's From header")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: postal-cli send-eml [flags] <dir>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *concurrency < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	c, err := cfg.newClient()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	paths, err := findEMLFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var (
+		mu     sync.Mutex
+		done   int
+		failed atomic.Int64
+		jobs   = make(chan string)
+		wg     sync.WaitGroup
+	)
+	report := func(path, outcome string) {
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		fmt.Fprintf(stdout, "[%d/%d] %s: %s\n", done, len(paths), path, outcome)
+	}
+
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				result, err := sendEML(context.Background(), c, path, *from)
+				if err != nil {
+					failed.Add(1)
+					report(path, "error: "+err.Error())
+					continue
+				}
+				report(path, "sent "+result.MessageID)
+			}
+		}()
+	}
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+
+	fmt.Fprintf(stdout, "%d sent, %d failed\n", int64(len(paths))-failed.Load(), failed.Load())
+	if failed.Load() > 0 {
+		return 1
+	}
+	return 0
+}
+
+// findEMLFiles returns the .eml files under dir in lexical order
+func findEMLFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".eml") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .eml files found in %s", dir)
+	}
+	return paths, nil
+}
+
+// sendEML streams one message file, taking the envelope from its headers
+func sendEML(ctx context.Context, c client.Client, path, from string) (*types.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env, err := emlEnvelope(f)
+	if err != nil {
+		return nil, err
+	}
+	if from != "" {
+		env.From = from
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	msg, err := withoutBcc(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SendRawMessageFrom(ctx, msg, env)
+}
+
+// withoutBcc returns the message read from r with its Bcc headers removed,
+// so that Bcc recipients get the message through the envelope without
+// being disclosed to the others. Only the header section is buffered.
+func withoutBcc(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	var header bytes.Buffer
+	inBcc := false
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if len(bytes.TrimRight(line, "\r\n")) == 0 {
+			// End of the header section
+			header.Write(line)
+			break
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			inBcc = len(line) >= 4 && strings.EqualFold(string(line[:4]), "bcc:")
+		}
+		if !inBcc {
+			header.Write(line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return io.MultiReader(&header, br), nil
+}
+
+// emlEnvelope derives the envelope from the From, To, Cc and Bcc headers
+func emlEnvelope(r io.Reader) (types.Envelope, error) {
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return types.Envelope{}, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	var env types.Envelope
+	if from, err := msg.Header.AddressList("From"); err == nil && len(from) > 0 {
+		env.From = from[0].Address
+	}
+	for _, name := range []string{"To", "Cc", "Bcc"} {
+		list, err := msg.Header.AddressList(name)
+		if err != nil && !errors.Is(err, mail.ErrHeaderNotPresent) {
+			return types.Envelope{}, fmt.Errorf("invalid %s header: %w", name, err)
+		}
+		for _, addr := range list {
+			env.To = append(env.To, addr.Address)
+		}
+	}
+	return env, nil
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSendEML(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.eml":        "From: a@example.com\r\nTo: x@example.com\r\nSubject: A\r\n\r\nBody A",
+		"nested/b.EML": "From: b@example.com\r\nTo: y@example.com\r\nBcc: z@example.com\r\nSubject: B\r\n\r\nBody B",
+		"notes.txt":    "ignored",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Mail string   `json:"mail"`
+			To   []string `json:"to"`
+			From string   `json:"from"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mail, _ := base64.StdEncoding.DecodeString(body.Mail)
+		if bytes.Contains(mail, []byte("Bcc:")) {
+			t.Errorf("sent message discloses Bcc: %q", mail)
+		}
+
+		mu.Lock()
+		received = append(received, body.From+" -> "+strings.Join(body.To, ",")+": "+string(mail[len(mail)-6:]))
+		mu.Unlock()
+		w.Write([]byte(`{"message_id": "1", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"send-eml", "-url", ts.URL, "-key", "test-key", "-concurrency", "2", dir}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, want 0; stderr: %s", code, stderr.String())
+	}
+
+	sort.Strings(received)
+	want := []string{
+		"a@example.com -> x@example.com: Body A",
+		"b@example.com -> y@example.com,z@example.com: Body B",
+	}
+	if strings.Join(received, "\n") != strings.Join(want, "\n") {
+		t.Errorf("received %q, want %q", received, want)
+	}
+	for _, line := range []string{"[1/2]", "[2/2]", "2 sent, 0 failed"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Errorf("output %q does not contain %q", stdout.String(), line)
+		}
+	}
+}
+
+func TestWithoutBcc(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "crlf",
+			in:   "From: a@example.com\r\nBcc: z@example.com\r\nSubject: A\r\n\r\nBcc: in the body\r\n",
+			want: "From: a@example.com\r\nSubject: A\r\n\r\nBcc: in the body\r\n",
+		},
+		{
+			name: "folded and lowercase",
+			in:   "From: a@example.com\nbcc: y@example.com,\n\tz@example.com\nSubject: A\n\nBody",
+			want: "From: a@example.com\nSubject: A\n\nBody",
+		},
+		{
+			name: "headers only",
+			in:   "Subject: A\r\nBCC: z@example.com",
+			want: "Subject: A\r\n",
+		},
+		{
+			name: "no bcc",
+			in:   "From: a@example.com\r\nBcc-Note: kept\r\n\r\nBody",
+			want: "From: a@example.com\r\nBcc-Note: kept\r\n\r\nBody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := withoutBcc(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("withoutBcc() error = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("withoutBcc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendEMLFailures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.eml"), []byte("Subject: no envelope\r\n\r\nBody"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"send-eml", "-url", "http://localhost", "-key", "test-key", dir}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("Run() = %d, want 1", code)
+	}
+	if !strings.Contains(stdout.String(), "0 sent, 1 failed") {
+		t.Errorf("output %q does not report the failure", stdout.String())
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"nope"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Run() = %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "send-eml") {
+		t.Errorf("usage %q does not list send-eml", stderr.String())
+	}
+}
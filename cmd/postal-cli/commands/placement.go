@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/placement"
+)
+
+// seedConfig is one entry of the -seeds file
+type seedConfig struct {
+	Address     string   `json:"address"`
+	IMAPAddr    string   `json:"imap_addr"`
+	Username    string   `json:"username"`
+	Password    string   `json:"password"`
+	SpamFolders []string `json:"spam_folders"`
+}
+
+// runPlacement sends probes to seed mailboxes and reports inbox placement
+func runPlacement(args []string, stdout, stderr io.Writer) int {
+	var cfg config
+	fs := flag.NewFlagSet("placement", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cfg.register(fs)
+	seedsFile := fs.String("seeds", "", "JSON file listing seed mailboxes and their IMAP credentials")
+	from := fs.String("from", "", "probe sender address")
+	subject := fs.String("subject", "Placement test {{.Token}}", "probe subject template")
+	body := fs.String("body", "This is an inbox placement test.", "probe body template")
+	timeout := fs.Duration("timeout", 5*time.Minute, "how long to wait for probes to arrive")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: postal-cli placement -seeds <file> -from <address> [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *seedsFile == "" || *from == "" {
+		fs.Usage()
+		return 2
+	}
+
+	c, err := cfg.newClient()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	seeds, err := loadSeeds(*seedsFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	h := &placement.Harness{
+		Client:  c,
+		From:    *from,
+		Subject: *subject,
+		Body:    *body,
+		Seeds:   seeds,
+		Timeout: *timeout,
+	}
+	results, err := h.Run(context.Background())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	code := 0
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(stdout, "%-40s error: %v\n", r.Seed, r.Err)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "%-40s %s\n", r.Seed, r.Placement)
+	}
+	return code
+}
+
+// loadSeeds reads the seed mailbox list
+func loadSeeds(path string) ([]placement.Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var configs []seedConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid seeds file %s: %w", path, err)
+	}
+
+	seeds := make([]placement.Seed, 0, len(configs))
+	for _, sc := range configs {
+		seeds = append(seeds, placement.Seed{
+			Address: sc.Address,
+			Mailbox: &placement.IMAPMailbox{
+				Addr:        sc.IMAPAddr,
+				Username:    sc.Username,
+				Password:    sc.Password,
+				SpamFolders: sc.SpamFolders,
+			},
+		})
+	}
+	return seeds, nil
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/placement"
+)
+
+func TestLoadSeeds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seeds.json")
+	data := `[{"address": "seed@gmail.example", "imap_addr": "imap.gmail.example:993", "username": "seed", "password": "pw", "spam_folders": ["[Gmail]/Spam"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	seeds, err := loadSeeds(path)
+	if err != nil {
+		t.Fatalf("loadSeeds() error = %v", err)
+	}
+	if len(seeds) != 1 || seeds[0].Address != "seed@gmail.example" {
+		t.Fatalf("loadSeeds() = %+v", seeds)
+	}
+	mb, ok := seeds[0].Mailbox.(*placement.IMAPMailbox)
+	if !ok || mb.Addr != "imap.gmail.example:993" || mb.SpamFolders[0] != "[Gmail]/Spam" {
+		t.Errorf("Mailbox = %+v", seeds[0].Mailbox)
+	}
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/webhooks"
+)
+
+// runWebhookTest posts a signed self-test event to a webhook endpoint
+func runWebhookTest(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("webhook-test", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	keyPath := fs.String("key", "", "PEM file with the RSA private key to sign the event with (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: postal-cli webhook-test -key <private-key.pem> <endpoint-url>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *keyPath == "" {
+		fs.Usage()
+		return 2
+	}
+
+	data, err := os.ReadFile(*keyPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	key, err := webhooks.ParsePrivateKey(string(data))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	result, err := webhooks.SelfTest(context.Background(), fs.Arg(0), webhooks.SelfTestOptions{Key: key})
+	if result != nil && result.RejectedStatus != 0 {
+		fmt.Fprintf(stdout, "invalid signature: status %d\n", result.RejectedStatus)
+	}
+	if result != nil && result.StatusCode != 0 {
+		fmt.Fprintf(stdout, "status: %d (%s)\n", result.StatusCode, result.Duration.Round(time.Millisecond))
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintln(stdout, "ok")
+	return 0
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/webhooks"
+)
+
+func TestWebhookTest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(webhooks.Handler(&key.PublicKey, func(ctx context.Context, env *webhooks.Envelope, ev webhooks.Event) error {
+		return nil
+	}))
+	defer ts.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"webhook-test", "-key", keyPath, ts.URL}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run() = %d, want 0; stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "invalid signature: status 401") || !strings.Contains(stdout.String(), "status: 200") {
+		t.Errorf("stdout = %q, want both endpoint statuses", stdout.String())
+	}
+}
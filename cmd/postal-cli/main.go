@@ -1 +1,11 @@
 package main
+
+import (
+	"os"
+
+	"github.com/sachin-duhan/postal-go/cmd/postal-cli/commands"
+)
+
+func main() {
+	os.Exit(commands.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,136 @@
+// Package privacy replaces recipient addresses and subjects in log lines,
+// labels and error strings with stable hashed identifiers
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// hashPrefix marks hashed identifiers so they are recognizable in logs
+const hashPrefix = "anon-"
+
+// addressPattern matches anything shaped like an email address
+var addressPattern = regexp.MustCompile(`[^\s<>"'(),;:\[\]]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+`)
+
+// Hash returns a stable identifier for value. Addresses differing only in
+// case or surrounding space hash the same, so log lines can be correlated.
+func Hash(value string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hashPrefix + hex.EncodeToString(sum[:6])
+}
+
+// Redact replaces the known values (such as a message's subject or
+// malformed recipients) and any email address in s with their hashed
+// identifiers
+func Redact(s string, known ...string) string {
+	// Longer values go first so a value that contains another is replaced whole
+	known = append([]string(nil), known...)
+	sort.SliceStable(known, func(i, j int) bool { return len(known[i]) > len(known[j]) })
+	for _, value := range known {
+		if value != "" {
+			s = strings.ReplaceAll(s, value, Hash(value))
+		}
+	}
+	return addressPattern.ReplaceAllStringFunc(s, Hash)
+}
+
+// RedactLabels returns labels with their values redacted
+func RedactLabels(labels map[string]string) map[string]string {
+	redacted := make(map[string]string, len(labels))
+	for k, v := range labels {
+		redacted[k] = Redact(v)
+	}
+	return redacted
+}
+
+// RedactError returns err with its message redacted. The client's typed
+// errors are copied with their text fields redacted so errors.As still
+// finds them; other errors are wrapped.
+func RedactError(err error, known ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	var retryErr *types.RetryError
+	if errors.As(err, &retryErr) {
+		stats := retryErr.RetryStats
+		stats.LastError = RedactError(stats.LastError, known...)
+		return &types.RetryError{RetryStats: stats, Err: RedactError(retryErr.Err, known...)}
+	}
+
+	var rateLimitErr *types.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return &types.RateLimitError{
+			RetryAfter: rateLimitErr.RetryAfter,
+			Err:        RedactError(rateLimitErr.Err, known...),
+		}
+	}
+
+	var validationErr *types.ValidationError
+	if errors.As(err, &validationErr) {
+		problems := make([]string, len(validationErr.Problems))
+		for i, p := range validationErr.Problems {
+			problems[i] = Redact(p, known...)
+		}
+		return &types.ValidationError{Problems: problems}
+	}
+
+	var postalErr *types.PostalError
+	if errors.As(err, &postalErr) {
+		redacted := *postalErr
+		redacted.Message = Redact(postalErr.Message, known...)
+		if postalErr.Details != nil {
+			redacted.Details = make(map[string]interface{}, len(postalErr.Details))
+			for k, v := range postalErr.Details {
+				if s, ok := v.(string); ok {
+					v = Redact(s, known...)
+				}
+				redacted.Details[k] = v
+			}
+		}
+		return &redacted
+	}
+
+	var unexpectedErr *types.UnexpectedResponseError
+	if errors.As(err, &unexpectedErr) {
+		redacted := *unexpectedErr
+		redacted.Body = Redact(unexpectedErr.Body, known...)
+		return &redacted
+	}
+
+	var gatewayErr *types.GatewayError
+	if errors.As(err, &gatewayErr) {
+		redacted := *gatewayErr
+		redacted.Body = Redact(gatewayErr.Body, known...)
+		return &redacted
+	}
+
+	msg := Redact(err.Error(), known...)
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactedError hides the message of an error it wraps
+type redactedError struct {
+	msg string
+	err error
+}
+
+// Error implements the error interface
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the original error, so errors.Is keeps working
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
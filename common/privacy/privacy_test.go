@@ -0,0 +1,96 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestHash(t *testing.T) {
+	a := Hash("User@Example.com")
+	if a != Hash(" user@example.com ") {
+		t.Error("Hash() differs for the same address in different case")
+	}
+	if a == Hash("other@example.com") {
+		t.Error("Hash() is the same for different addresses")
+	}
+	if !strings.HasPrefix(a, hashPrefix) || len(a) != len(hashPrefix)+12 {
+		t.Errorf("Hash() = %q, want %s followed by 12 hex digits", a, hashPrefix)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		known []string
+		want  string
+	}{
+		{"address", "invalid recipient email: bob@example.com", nil, "invalid recipient email: " + Hash("bob@example.com")},
+		{"display name", "From: Bob <bob@mail.example.org>", nil, "From: Bob <" + Hash("bob@mail.example.org") + ">"},
+		{"several", "a@example.com, b@example.com", nil, Hash("a@example.com") + ", " + Hash("b@example.com")},
+		{"subject", `subject "Your invoice" rejected`, []string{"Your invoice"}, `subject "` + Hash("Your invoice") + `" rejected`},
+		{"no address", "attachment name is required", nil, "attachment name is required"},
+		{"domain only", "sender domain example.com is not verified", nil, "sender domain example.com is not verified"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.in, tt.known...); got != tt.want {
+				t.Errorf("Redact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	const addr = "bob@example.com"
+	tests := []struct {
+		name string
+		err  error
+		is   error
+	}{
+		{"validation", &types.ValidationError{Problems: []string{"invalid recipient email: " + addr}}, types.ErrInvalidMessage},
+		{"postal", types.NewPostalError("InvalidRecipient", addr+" is suppressed", http.StatusUnprocessableEntity).WithDetails(map[string]interface{}{"to": addr}), nil},
+		{"unexpected response", types.NewUnexpectedResponseError("decode", http.StatusOK, []byte(`{"to":"`+addr+`"}`), errors.New("bad json")), types.ErrUnexpectedResponse},
+		{"gateway", types.NewGatewayError(http.StatusBadGateway, "text/html", []byte(addr)), types.ErrGateway},
+		{"wrapped", fmt.Errorf("send to %s: %w", addr, types.ErrQuotaExceeded), types.ErrQuotaExceeded},
+		{"retried", &types.RetryError{
+			RetryStats: types.RetryStats{Attempts: 3, LastError: types.NewGatewayError(http.StatusBadGateway, "text/html", []byte(addr))},
+			Err:        types.NewPostalError("ServerError", addr+" failed", http.StatusInternalServerError),
+		}, types.ErrServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RedactError(tt.err)
+			if strings.Contains(fmt.Sprintf("%v %+v", got, got), addr) {
+				t.Errorf("RedactError() = %+v, still contains the address", got)
+			}
+			if tt.is != nil && !errors.Is(got, tt.is) {
+				t.Errorf("errors.Is(%v, %v) = false", got, tt.is)
+			}
+			var catErr types.CategorizedError
+			if errors.As(tt.err, &catErr) && !errors.As(got, &catErr) {
+				t.Error("RedactError() lost the error's type")
+			}
+		})
+	}
+
+	var retryErr *types.RetryError
+	if got := RedactError(tests[len(tests)-1].err); !errors.As(got, &retryErr) || retryErr.Attempts != 3 || strings.Contains(retryErr.LastError.Error()+string(retryErr.LastError.(*types.GatewayError).Body), addr) {
+		t.Errorf("RedactError() = %v, want a RetryError with its stats kept and last error redacted", got)
+	}
+
+	plain := errors.New("connection refused")
+	if got := RedactError(plain); got != plain {
+		t.Errorf("RedactError() = %v, want the original error when nothing is redacted", got)
+	}
+	if RedactError(nil) != nil {
+		t.Error("RedactError(nil) != nil")
+	}
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotSupported is returned when the server does not support a feature
+var ErrNotSupported = errors.New("not supported by server")
+
+// ErrEndpointNotSupported is returned when a server answers a request to an
+// optional endpoint with 404, 405 or 501, as servers predating it do. It
+// matches ErrNotSupported too.
+var ErrEndpointNotSupported = fmt.Errorf("endpoint %w", ErrNotSupported)
+
+// Capability names an optional server feature, identified by its API endpoint
+type Capability string
+
+const (
+	// CapabilitySendMessage is the structured send endpoint
+	CapabilitySendMessage Capability = "send/message"
+
+	// CapabilitySendRaw is the raw MIME send endpoint
+	CapabilitySendRaw Capability = "send/raw"
+
+	// CapabilityMessageDetails is the message details endpoint
+	CapabilityMessageDetails Capability = "messages/message"
+
+	// CapabilityDeliveries is the message deliveries endpoint
+	CapabilityDeliveries Capability = "messages/deliveries"
+)
+
+// AllCapabilities lists every capability probed by the client
+var AllCapabilities = []Capability{
+	CapabilitySendMessage,
+	CapabilitySendRaw,
+	CapabilityMessageDetails,
+	CapabilityDeliveries,
+}
+
+// Capabilities records which optional features the server supports
+type Capabilities struct {
+	Supported map[Capability]bool `json:"supported"`
+}
+
+// Supports reports whether the server supports the capability
+func (c *Capabilities) Supports(capability Capability) bool {
+	return c != nil && c.Supported[capability]
+}
+
+// Require returns an error wrapping ErrNotSupported if the server does not
+// support the capability
+func (c *Capabilities) Require(capability Capability) error {
+	if !c.Supports(capability) {
+		return fmt.Errorf("%s: %w", capability, ErrNotSupported)
+	}
+	return nil
+}
+
+// EndpointNotSupportedError is returned for requests to an optional
+// endpoint the server does not have. It wraps the error parsed from the
+// response.
+type EndpointNotSupportedError struct {
+	Endpoint   string
+	StatusCode int
+	Err        error
+}
+
+// Error implements the error interface
+func (e *EndpointNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: %v (status %d)", e.Endpoint, ErrEndpointNotSupported, e.StatusCode)
+}
+
+// Unwrap returns the error parsed from the response
+func (e *EndpointNotSupportedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrEndpointNotSupported or
+// ErrNotSupported
+func (e *EndpointNotSupportedError) Is(target error) bool {
+	return target == ErrEndpointNotSupported || target == ErrNotSupported
+}
+
+// Category implements CategorizedError
+func (e *EndpointNotSupportedError) Category() ErrorCategory {
+	return CategoryAPI
+}
+
+// Temporary implements CategorizedError. Retrying cannot add the endpoint,
+// even when the server answered 501.
+func (e *EndpointNotSupportedError) Temporary() bool {
+	return false
+}
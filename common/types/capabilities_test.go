@@ -0,0 +1,39 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	caps := &Capabilities{Supported: map[Capability]bool{
+		CapabilitySendMessage: true,
+		CapabilitySendRaw:     false,
+	}}
+
+	if !caps.Supports(CapabilitySendMessage) {
+		t.Error("Supports(send/message) = false, want true")
+	}
+	if caps.Supports(CapabilitySendRaw) {
+		t.Error("Supports(send/raw) = true, want false")
+	}
+	if caps.Supports(CapabilityDeliveries) {
+		t.Error("Supports() = true for unprobed capability")
+	}
+
+	if err := caps.Require(CapabilitySendMessage); err != nil {
+		t.Errorf("Require(send/message) error = %v", err)
+	}
+	err := caps.Require(CapabilitySendRaw)
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Require(send/raw) error = %v, want ErrNotSupported", err)
+	}
+	if err.Error() != "send/raw: not supported by server" {
+		t.Errorf("Require(send/raw) error = %q", err.Error())
+	}
+
+	var nilCaps *Capabilities
+	if nilCaps.Supports(CapabilitySendMessage) {
+		t.Error("nil Capabilities should support nothing")
+	}
+}
@@ -0,0 +1,43 @@
+package types
+
+import "context"
+
+type contextKey int
+
+const (
+	labelsKey contextKey = iota
+	apiKeyKey
+)
+
+// ContextWithLabels returns a context carrying metrics labels for requests
+// made with it. Labels already on ctx are kept unless overridden.
+func ContextWithLabels(ctx context.Context, labels map[string]string) context.Context {
+	merged := make(map[string]string, len(labels))
+	for k, v := range LabelsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, labelsKey, merged)
+}
+
+// LabelsFromContext returns the metrics labels carried by ctx. Middleware can
+// read them from the outgoing request's context.
+func LabelsFromContext(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(labelsKey).(map[string]string)
+	return labels
+}
+
+// ContextWithAPIKey returns a context whose requests are sent with key
+// instead of the client's or tenant view's API key, for request-scoped
+// tenancy without a client per request. An empty key removes the override.
+func ContextWithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyKey, key)
+}
+
+// APIKeyFromContext returns the API key override carried by ctx, if any
+func APIKeyFromContext(ctx context.Context) (string, bool) {
+	key, _ := ctx.Value(apiKeyKey).(string)
+	return key, key != ""
+}
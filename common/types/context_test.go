@@ -0,0 +1,40 @@
+package types
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextLabels(t *testing.T) {
+	ctx := context.Background()
+	if labels := LabelsFromContext(ctx); labels != nil {
+		t.Errorf("LabelsFromContext() = %v, want nil", labels)
+	}
+
+	ctx = ContextWithLabels(ctx, map[string]string{"tenant": "acme", "env": "prod"})
+	ctx = ContextWithLabels(ctx, map[string]string{"tenant": "globex"})
+
+	labels := LabelsFromContext(ctx)
+	if labels["tenant"] != "globex" {
+		t.Errorf("labels[tenant] = %q, want globex", labels["tenant"])
+	}
+	if labels["env"] != "prod" {
+		t.Errorf("labels[env] = %q, want prod", labels["env"])
+	}
+}
+
+func TestContextAPIKey(t *testing.T) {
+	ctx := context.Background()
+	if key, ok := APIKeyFromContext(ctx); ok {
+		t.Errorf("APIKeyFromContext() = %q, want no key", key)
+	}
+
+	ctx = ContextWithAPIKey(ctx, "tenant-key")
+	if key, ok := APIKeyFromContext(ctx); !ok || key != "tenant-key" {
+		t.Errorf("APIKeyFromContext() = %q, %v; want tenant-key", key, ok)
+	}
+
+	if key, ok := APIKeyFromContext(ContextWithAPIKey(ctx, "")); ok {
+		t.Errorf("APIKeyFromContext() after clearing = %q, want no key", key)
+	}
+}
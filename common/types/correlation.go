@@ -0,0 +1,21 @@
+package types
+
+import "strings"
+
+// CorrelationHeader carries an application correlation ID on sent mail so
+// that later events for the message can be matched to the send
+const CorrelationHeader = "X-Correlation-ID"
+
+// CorrelationID returns the correlation ID from a message's headers, as
+// found in webhook payloads. Header names are matched case-insensitively.
+func CorrelationID(headers map[string]string) string {
+	if id, ok := headers[CorrelationHeader]; ok {
+		return id
+	}
+	for name, value := range headers {
+		if strings.EqualFold(name, CorrelationHeader) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,24 @@
+package types
+
+import "testing"
+
+func TestCorrelationID(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"exact", map[string]string{"X-Correlation-ID": "order-1"}, "order-1"},
+		{"lower case", map[string]string{"x-correlation-id": "order-2"}, "order-2"},
+		{"missing", map[string]string{"X-Other": "value"}, ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CorrelationID(tt.headers); got != tt.want {
+				t.Errorf("CorrelationID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery statuses reported by the messages/deliveries endpoint
+const (
+	DeliverySent     = "Sent"
+	DeliverySoftFail = "SoftFail"
+	DeliveryHardFail = "HardFail"
+	DeliveryHeld     = "Held"
+	DeliveryBounced  = "Bounced"
+)
+
+// Delivery is one attempt to deliver a message
+type Delivery struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	// Details describes the attempt, e.g. "Message for x@example.com
+	// accepted by mx.example.com"
+	Details string `json:"details"`
+	// Output is the remote server's SMTP response, e.g. "250 2.0.0 OK"
+	Output      string  `json:"output"`
+	SentWithSSL bool    `json:"sent_with_ssl"`
+	LogID       string  `json:"log_id"`
+	Duration    float64 `json:"time"` // Seconds
+	Timestamp   float64 `json:"timestamp"`
+}
+
+// Time returns when the attempt was made
+func (d *Delivery) Time() time.Time {
+	return unixTime(d.Timestamp)
+}
+
+// IsHardFail reports whether the attempt failed permanently, so the message
+// will not be retried
+func (d *Delivery) IsHardFail() bool {
+	return d.Status == DeliveryHardFail || d.Status == DeliveryBounced
+}
+
+// IsSoftFail reports whether the attempt failed temporarily and will be
+// retried
+func (d *Delivery) IsSoftFail() bool {
+	return d.Status == DeliverySoftFail
+}
+
+// SMTPCode returns the reply code at the start of Output, or 0 if there
+// is none
+func (d *Delivery) SMTPCode() int {
+	output := strings.TrimSpace(d.Output)
+	if len(output) < 3 {
+		return 0
+	}
+	code, err := strconv.Atoi(output[:3])
+	if err != nil || code < 200 || code > 599 {
+		return 0
+	}
+	return code
+}
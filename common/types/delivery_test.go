@@ -0,0 +1,41 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery Delivery
+		hardFail bool
+		softFail bool
+		code     int
+	}{
+		{name: "sent", delivery: Delivery{Status: DeliverySent, Output: "250 2.0.0 OK"}, code: 250},
+		{name: "soft fail", delivery: Delivery{Status: DeliverySoftFail, Output: "451 4.7.1 Try again later"}, softFail: true, code: 451},
+		{name: "hard fail", delivery: Delivery{Status: DeliveryHardFail, Output: "550 5.1.1 User unknown"}, hardFail: true, code: 550},
+		{name: "bounced", delivery: Delivery{Status: DeliveryBounced}, hardFail: true},
+		{name: "held", delivery: Delivery{Status: DeliveryHeld, Output: "Held by policy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.delivery.IsHardFail(); got != tt.hardFail {
+				t.Errorf("IsHardFail() = %v, want %v", got, tt.hardFail)
+			}
+			if got := tt.delivery.IsSoftFail(); got != tt.softFail {
+				t.Errorf("IsSoftFail() = %v, want %v", got, tt.softFail)
+			}
+			if got := tt.delivery.SMTPCode(); got != tt.code {
+				t.Errorf("SMTPCode() = %d, want %d", got, tt.code)
+			}
+		})
+	}
+
+	d := Delivery{Timestamp: 1700000000.25}
+	if got := d.Time(); !got.Equal(time.Unix(1700000000, 25e7)) {
+		t.Errorf("Time() = %v", got)
+	}
+}
@@ -0,0 +1,175 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Difference is one field that differs between two messages. Field uses
+// the JSON names, e.g. "plain_body", "headers[X-Tag]" or "attachments[0].name".
+type Difference struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// String formats the difference as "field: old -> new"
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Field, d.Old, d.New)
+}
+
+// Diff lists the differences between two messages
+type Diff []Difference
+
+// String returns one difference per line, or "no differences"
+func (d Diff) String() string {
+	if len(d) == 0 {
+		return "no differences"
+	}
+	lines := make([]string, len(d))
+	for i, diff := range d {
+		lines[i] = diff.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffMessages compares two messages field by field. A nil message is
+// treated as empty.
+func DiffMessages(a, b *Message) Diff {
+	if a == nil {
+		a = &Message{}
+	}
+	if b == nil {
+		b = &Message{}
+	}
+
+	var diff Diff
+	diffStructs(&diff, "", reflect.ValueOf(*a), reflect.ValueOf(*b))
+	return diff
+}
+
+// DiffMessageJSON compares msg with a JSON encoded message, such as a
+// captured request body. Top-level fields the Message type does not know
+// are reported as differences too.
+func DiffMessageJSON(msg *Message, data []byte) (Diff, error) {
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("decode message: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("decode message: %w", err)
+	}
+
+	diff := DiffMessages(msg, &decoded)
+
+	known := make(map[string]bool)
+	messageType := reflect.TypeOf(Message{})
+	for i := 0; i < messageType.NumField(); i++ {
+		known[jsonName(messageType.Field(i))] = true
+	}
+	var unknown []string
+	for name := range fields {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		diff = append(diff, Difference{Field: name, Old: "<unset>", New: string(fields[name])})
+	}
+	return diff, nil
+}
+
+// diffStructs appends the differing fields of two structs of the same type
+func diffStructs(diff *Diff, prefix string, a, b reflect.Value) {
+	for i := 0; i < a.NumField(); i++ {
+		field := prefix + jsonName(a.Type().Field(i))
+		diffValues(diff, field, a.Field(i), b.Field(i))
+	}
+}
+
+// diffValues appends the differences between two values of the same type
+func diffValues(diff *Diff, field string, a, b reflect.Value) {
+	switch a.Kind() {
+	case reflect.Struct:
+		diffStructs(diff, field+".", a, b)
+	case reflect.Map:
+		keys := make(map[string]bool)
+		for _, k := range a.MapKeys() {
+			keys[k.String()] = true
+		}
+		for _, k := range b.MapKeys() {
+			keys[k.String()] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			key := reflect.ValueOf(k)
+			diffPresent(diff, fmt.Sprintf("%s[%s]", field, k), a.MapIndex(key), b.MapIndex(key))
+		}
+	case reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return
+		}
+		if a.Type().Elem().Kind() != reflect.Struct {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				*diff = append(*diff, Difference{Field: field, Old: formatValue(a), New: formatValue(b)})
+			}
+			return
+		}
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			var av, bv reflect.Value
+			if i < a.Len() {
+				av = a.Index(i)
+			}
+			if i < b.Len() {
+				bv = b.Index(i)
+			}
+			diffPresent(diff, fmt.Sprintf("%s[%d]", field, i), av, bv)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*diff = append(*diff, Difference{Field: field, Old: formatValue(a), New: formatValue(b)})
+		}
+	}
+}
+
+// diffPresent compares map or slice elements that may be missing on one side
+func diffPresent(diff *Diff, field string, a, b reflect.Value) {
+	switch {
+	case a.IsValid() && b.IsValid():
+		diffValues(diff, field, a, b)
+	case a.IsValid() || b.IsValid():
+		*diff = append(*diff, Difference{Field: field, Old: formatValue(a), New: formatValue(b)})
+	}
+}
+
+// formatValue formats a value for a difference report
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<unset>"
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Struct:
+		return fmt.Sprintf("%+v", v.Interface())
+	}
+	return fmt.Sprintf("%q", v.Interface())
+}
+
+// jsonName returns the JSON name of a struct field
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffMessages(t *testing.T) {
+	base := &Message{
+		To:      []string{"a@example.com"},
+		From:    "sender@example.com",
+		Subject: "Hello",
+		Body:    "Hi",
+		Headers: map[string]string{"X-Tag": "one", "X-Same": "same"},
+		Attachments: []Attachment{
+			{Name: "a.txt", ContentType: "text/plain", Data: "YQ=="},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(m *Message)
+		want   []string
+	}{
+		{
+			name:   "identical",
+			modify: func(m *Message) {},
+		},
+		{
+			name: "scalar and list fields",
+			modify: func(m *Message) {
+				m.Subject = "Hello Ada"
+				m.To = []string{"a@example.com", "b@example.com"}
+			},
+			want: []string{
+				`to: ["a@example.com"] -> ["a@example.com" "b@example.com"]`,
+				`subject: "Hello" -> "Hello Ada"`,
+			},
+		},
+		{
+			name: "headers",
+			modify: func(m *Message) {
+				m.Headers = map[string]string{"X-Tag": "two", "X-Same": "same", "X-New": "new"}
+			},
+			want: []string{
+				`headers[X-New]: <unset> -> "new"`,
+				`headers[X-Tag]: "one" -> "two"`,
+			},
+		},
+		{
+			name: "attachments",
+			modify: func(m *Message) {
+				m.Attachments = []Attachment{
+					{Name: "b.txt", ContentType: "text/plain", Data: "YQ=="},
+					{Name: "c.txt", ContentType: "text/plain", Data: "Yw=="},
+				}
+			},
+			want: []string{
+				`attachments[0].name: "a.txt" -> "b.txt"`,
+				`attachments[1]: <unset> -> {Name:c.txt ContentType:text/plain Data:Yw==}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := *base
+			other.Headers = map[string]string{"X-Tag": "one", "X-Same": "same"}
+			tt.modify(&other)
+
+			diff := DiffMessages(base, &other)
+			if len(diff) != len(tt.want) {
+				t.Fatalf("DiffMessages() =\n%s\nwant %d differences", diff, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := diff[i].String(); got != want {
+					t.Errorf("difference %d = %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffMessageJSON(t *testing.T) {
+	msg := &Message{To: []string{"a@example.com"}, From: "sender@example.com", Subject: "Hello"}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := DiffMessageJSON(msg, data)
+	if err != nil {
+		t.Fatalf("DiffMessageJSON() error = %v", err)
+	}
+	if len(diff) != 0 {
+		t.Errorf("round-tripped message differs:\n%s", diff)
+	}
+	if diff.String() != "no differences" {
+		t.Errorf("String() = %q", diff.String())
+	}
+
+	diff, err = DiffMessageJSON(msg, []byte(`{"to":["a@example.com"],"from":"sender@example.com","subject":"Hi","text_body":"x"}`))
+	if err != nil {
+		t.Fatalf("DiffMessageJSON() error = %v", err)
+	}
+	want := "subject: \"Hello\" -> \"Hi\"\ntext_body: <unset> -> \"x\""
+	if diff.String() != want {
+		t.Errorf("DiffMessageJSON() =\n%s\nwant\n%s", diff, want)
+	}
+
+	if _, err := DiffMessageJSON(msg, []byte(`not json`)); err == nil {
+		t.Error("DiffMessageJSON() error = nil for invalid JSON")
+	}
+}
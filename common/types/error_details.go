@@ -0,0 +1,58 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Keys servers use in error details, in order of preference
+var (
+	fieldDetailKeys = []string{"field", "attribute", "param", "parameter"}
+	valueDetailKeys = []string{"value", "given"}
+	limitDetailKeys = []string{"limit", "max", "maximum"}
+)
+
+// Field returns the name of the request field the error is about, or ""
+// if the details do not name one
+func (e *PostalError) Field() string {
+	v, _ := e.detail(fieldDetailKeys).(string)
+	return v
+}
+
+// Value returns the offending value as text, or "" if the details do not
+// include one
+func (e *PostalError) Value() string {
+	switch v := e.detail(valueDetailKeys).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Limit returns the limit the request exceeded, such as a maximum size or
+// count, and whether the details include one
+func (e *PostalError) Limit() (int64, bool) {
+	switch v := e.detail(limitDetailKeys).(type) {
+	case float64:
+		return int64(v), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// detail returns the first of keys present in Details
+func (e *PostalError) detail(keys []string) interface{} {
+	for _, key := range keys {
+		if v, ok := e.Details[key]; ok && v != nil {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package types
+
+import "testing"
+
+func TestPostalErrorDetailGetters(t *testing.T) {
+	tests := []struct {
+		name      string
+		details   map[string]interface{}
+		wantField string
+		wantValue string
+		wantLimit int64
+		wantOK    bool
+	}{
+		{"no details", nil, "", "", 0, false},
+		{
+			name:      "size limit",
+			details:   map[string]interface{}{"field": "attachments", "value": float64(30000000), "limit": float64(25000000)},
+			wantField: "attachments",
+			wantValue: "30000000",
+			wantLimit: 25000000,
+			wantOK:    true,
+		},
+		{
+			name:      "alternate keys",
+			details:   map[string]interface{}{"attribute": "to", "given": "not-an-address", "max": "50"},
+			wantField: "to",
+			wantValue: "not-an-address",
+			wantLimit: 50,
+			wantOK:    true,
+		},
+		{
+			name:    "malformed limit",
+			details: map[string]interface{}{"field": 7, "limit": "lots", "value": true},
+			// A non-string field is ignored
+			wantValue: "true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewPostalError("ValidationError", "invalid", 422).WithDetails(tt.details)
+			if got := e.Field(); got != tt.wantField {
+				t.Errorf("Field() = %q, want %q", got, tt.wantField)
+			}
+			if got := e.Value(); got != tt.wantValue {
+				t.Errorf("Value() = %q, want %q", got, tt.wantValue)
+			}
+			if got, ok := e.Limit(); got != tt.wantLimit || ok != tt.wantOK {
+				t.Errorf("Limit() = %d, %v, want %d, %v", got, ok, tt.wantLimit, tt.wantOK)
+			}
+		})
+	}
+}
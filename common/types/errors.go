@@ -1,8 +1,12 @@
 package types
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 )
 
 var (
@@ -20,8 +24,292 @@ var (
 
 	// ErrInvalidMessage represents message validation errors
 	ErrInvalidMessage = errors.New("invalid message")
+
+	// ErrMessageTooLarge represents a message or attachment over a configured size limit
+	ErrMessageTooLarge = errors.New("message too large")
+
+	// ErrUnexpectedResponse represents responses that could not be parsed
+	ErrUnexpectedResponse = errors.New("unexpected response")
+
+	// ErrQuotaExceeded represents a client-side sending quota being used up
+	ErrQuotaExceeded = errors.New("sending quota exceeded")
+
+	// ErrTooManyInFlight represents a send rejected because MaxConcurrency
+	// sends are already in flight
+	ErrTooManyInFlight = errors.New("too many sends in flight")
+
+	// ErrUnknownProfile represents a send selecting an unregistered sender profile
+	ErrUnknownProfile = errors.New("unknown sender profile")
+
+	// ErrTooManyRedirects represents a response redirected more often than allowed
+	ErrTooManyRedirects = errors.New("too many redirects")
+
+	// ErrResponseTooLarge represents a response body exceeding the configured limit
+	ErrResponseTooLarge = errors.New("response too large")
+
+	// ErrUnknownResponseField represents a response field the client does not
+	// know, reported in strict decode mode
+	ErrUnknownResponseField = errors.New("unknown response field")
+
+	// ErrGateway represents non-JSON error pages returned by a proxy in front of Postal
+	ErrGateway = errors.New("gateway error")
+)
+
+// MaxBodySnippet is the maximum number of response body bytes kept on errors
+const MaxBodySnippet = 512
+
+// ErrorCategory classifies where an error originated
+type ErrorCategory string
+
+const (
+	// CategoryValidation marks errors found before a request was sent
+	CategoryValidation ErrorCategory = "validation"
+
+	// CategoryTransport marks errors talking to the server (network, I/O)
+	CategoryTransport ErrorCategory = "transport"
+
+	// CategoryAPI marks errors returned by the Postal server
+	CategoryAPI ErrorCategory = "api"
 )
 
+// CategorizedError is implemented by all typed errors returned by the client
+type CategorizedError interface {
+	error
+	Category() ErrorCategory
+	Temporary() bool
+}
+
+// ValidationError represents a message that failed client-side validation
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation_error: %s", strings.Join(e.Problems, "; "))
+}
+
+// Is reports whether the error matches ErrInvalidMessage
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidMessage
+}
+
+// Category implements CategorizedError
+func (e *ValidationError) Category() ErrorCategory {
+	return CategoryValidation
+}
+
+// Temporary implements CategorizedError. Validation errors never go away on retry.
+func (e *ValidationError) Temporary() bool {
+	return false
+}
+
+// MessageTooLargeError represents a message or attachment exceeding a
+// client-side size limit. Sizes are as sent, after base64 and JSON encoding.
+type MessageTooLargeError struct {
+	// Part is what exceeded the limit, e.g. "message" or `attachment "a.pdf"`
+	Part  string
+	Size  int64
+	Limit int64
+}
+
+// Error implements the error interface
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("%s is %d bytes encoded, exceeds limit of %d", e.Part, e.Size, e.Limit)
+}
+
+// Is reports whether the error matches ErrMessageTooLarge or ErrInvalidMessage
+func (e *MessageTooLargeError) Is(target error) bool {
+	return target == ErrMessageTooLarge || target == ErrInvalidMessage
+}
+
+// Category implements CategorizedError
+func (e *MessageTooLargeError) Category() ErrorCategory {
+	return CategoryValidation
+}
+
+// Temporary implements CategorizedError
+func (e *MessageTooLargeError) Temporary() bool {
+	return false
+}
+
+// TransportError represents a failure to reach the server or read its response
+type TransportError struct {
+	Op  string
+	Err error
+	// NotSent reports that the request was never written to the server, e.g.
+	// because the connection could not be made, so resending it cannot
+	// deliver a message twice
+	NotSent bool
+}
+
+// Error implements the error interface
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Category implements CategorizedError
+func (e *TransportError) Category() ErrorCategory {
+	return CategoryTransport
+}
+
+// Temporary implements CategorizedError. Transport errors are temporary unless
+// the caller's context was cancelled or expired, or a client-side safeguard
+// rejected the request or response.
+func (e *TransportError) Temporary() bool {
+	for _, permanent := range []error{context.Canceled, context.DeadlineExceeded, ErrTooManyRedirects, ErrResponseTooLarge, ErrInvalidMessage} {
+		if errors.Is(e.Err, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
+// UnexpectedResponseError is returned when the server's response body cannot
+// be parsed. It carries the status code and a truncated copy of the body.
+type UnexpectedResponseError struct {
+	Op         string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+// NewUnexpectedResponseError creates an UnexpectedResponseError, truncating
+// body to MaxBodySnippet bytes
+func NewUnexpectedResponseError(op string, statusCode int, body []byte, err error) *UnexpectedResponseError {
+	return &UnexpectedResponseError{
+		Op:         op,
+		StatusCode: statusCode,
+		Body:       BodySnippet(body),
+		Err:        err,
+	}
+}
+
+// Error implements the error interface
+func (e *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("%s (status %d): %v; body: %q", e.Op, e.StatusCode, e.Err, e.Body)
+}
+
+// Unwrap returns the underlying parse error
+func (e *UnexpectedResponseError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrUnexpectedResponse
+func (e *UnexpectedResponseError) Is(target error) bool {
+	return target == ErrUnexpectedResponse
+}
+
+// Category implements CategorizedError
+func (e *UnexpectedResponseError) Category() ErrorCategory {
+	return CategoryAPI
+}
+
+// Temporary implements CategorizedError
+func (e *UnexpectedResponseError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// GatewayError is returned when a reverse proxy answers with a non-JSON error
+// page (typically HTML 502/503/504) instead of a Postal API response
+type GatewayError struct {
+	StatusCode  int
+	ContentType string
+	Body        string
+}
+
+// NewGatewayError creates a GatewayError, truncating body to MaxBodySnippet bytes
+func NewGatewayError(statusCode int, contentType string, body []byte) *GatewayError {
+	return &GatewayError{
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        BodySnippet(body),
+	}
+}
+
+// Error implements the error interface
+func (e *GatewayError) Error() string {
+	return fmt.Sprintf("gateway error: %d %s from proxy in front of Postal", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether the error matches ErrGateway, or ErrServerError for 5xx statuses
+func (e *GatewayError) Is(target error) bool {
+	return target == ErrGateway || (target == ErrServerError && e.StatusCode >= http.StatusInternalServerError)
+}
+
+// Category implements CategorizedError
+func (e *GatewayError) Category() ErrorCategory {
+	return CategoryTransport
+}
+
+// Temporary implements CategorizedError. Bad gateway, unavailable and gateway
+// timeout pages usually clear once the upstream recovers.
+func (e *GatewayError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RateLimitError is returned for 429 responses. It wraps the error parsed
+// from the response and carries the wait the server asked for.
+type RateLimitError struct {
+	// RetryAfter is the wait from the Retry-After header, or zero if the
+	// server did not send one
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error implements the error interface
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited, retry after %v: %v", e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("rate limited: %v", e.Err)
+}
+
+// Unwrap returns the error parsed from the response
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrRateLimit
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimit
+}
+
+// Category implements CategorizedError
+func (e *RateLimitError) Category() ErrorCategory {
+	return CategoryAPI
+}
+
+// Temporary implements CategorizedError
+func (e *RateLimitError) Temporary() bool {
+	return true
+}
+
+// IsGateway checks if the error is a proxy gateway error
+func IsGateway(err error) bool {
+	return errors.Is(err, ErrGateway)
+}
+
+// BodySnippet returns body truncated to MaxBodySnippet bytes
+func BodySnippet(body []byte) string {
+	if len(body) <= MaxBodySnippet {
+		return string(body)
+	}
+	return string(body[:MaxBodySnippet]) + "...(truncated)"
+}
+
+// APIError is the error category for errors returned by the Postal server
+type APIError = PostalError
+
 // PostalError represents a detailed API error
 type PostalError struct {
 	Code       string                 `json:"code"`
@@ -38,6 +326,57 @@ func (e *PostalError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// Is maps the HTTP status code onto the package's sentinel errors
+func (e *PostalError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrRateLimit:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// Category implements CategorizedError
+func (e *PostalError) Category() ErrorCategory {
+	return CategoryAPI
+}
+
+// Temporary implements CategorizedError. Rate limits and server errors are
+// temporary; other API errors describe a problem with the request itself.
+func (e *PostalError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsRetryable reports whether err is temporary and the request may be retried
+func IsRetryable(err error) bool {
+	var catErr CategorizedError
+	if errors.As(err, &catErr) {
+		return catErr.Temporary()
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status of the response err was built from, or
+// 0 if err did not come from a response
+func StatusCode(err error) int {
+	var postalErr *PostalError
+	if errors.As(err, &postalErr) {
+		return postalErr.StatusCode
+	}
+	var gatewayErr *GatewayError
+	if errors.As(err, &gatewayErr) {
+		return gatewayErr.StatusCode
+	}
+	var unexpectedErr *UnexpectedResponseError
+	if errors.As(err, &unexpectedErr) {
+		return unexpectedErr.StatusCode
+	}
+	return 0
+}
+
 // IsRateLimit checks if the error is a rate limit error
 func IsRateLimit(err error) bool {
 	return errors.Is(err, ErrRateLimit)
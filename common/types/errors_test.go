@@ -1,8 +1,10 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -262,6 +264,149 @@ func TestIsServerError(t *testing.T) {
 	}
 }
 
+func TestErrorCategories(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       CategorizedError
+		category  ErrorCategory
+		temporary bool
+	}{
+		{
+			name:     "validation error",
+			err:      &ValidationError{Problems: []string{"subject is required"}},
+			category: CategoryValidation,
+		},
+		{
+			name:      "transport error",
+			err:       &TransportError{Op: "request failed", Err: errors.New("connection reset")},
+			category:  CategoryTransport,
+			temporary: true,
+		},
+		{
+			name:     "cancelled transport error",
+			err:      &TransportError{Op: "request failed", Err: context.Canceled},
+			category: CategoryTransport,
+		},
+		{
+			name:     "client API error",
+			err:      NewPostalError("validation_error", "Invalid request", 400),
+			category: CategoryAPI,
+		},
+		{
+			name:      "rate limit API error",
+			err:       NewPostalError("rate_limit", "Too many requests", 429),
+			category:  CategoryAPI,
+			temporary: true,
+		},
+		{
+			name:      "server API error",
+			err:       NewPostalError("server_error", "Internal error", 503),
+			category:  CategoryAPI,
+			temporary: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Category(); got != tt.category {
+				t.Errorf("Category() = %v, want %v", got, tt.category)
+			}
+			if got := tt.err.Temporary(); got != tt.temporary {
+				t.Errorf("Temporary() = %v, want %v", got, tt.temporary)
+			}
+			if got := IsRetryable(fmt.Errorf("wrapped: %w", tt.err)); got != tt.temporary {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.temporary)
+			}
+		})
+	}
+}
+
+func TestIsRetryableUncategorized(t *testing.T) {
+	if IsRetryable(nil) {
+		t.Error("IsRetryable(nil) = true, want false")
+	}
+	if IsRetryable(errors.New("plain error")) {
+		t.Error("IsRetryable() = true for uncategorized error, want false")
+	}
+}
+
+func TestPostalErrorSentinels(t *testing.T) {
+	if !IsRateLimit(NewPostalError("rate_limit", "slow down", 429)) {
+		t.Error("IsRateLimit() = false for 429 PostalError")
+	}
+	if !IsUnauthorized(NewPostalError("unauthorized", "bad key", 401)) {
+		t.Error("IsUnauthorized() = false for 401 PostalError")
+	}
+	if !IsServerError(NewPostalError("server_error", "oops", 500)) {
+		t.Error("IsServerError() = false for 500 PostalError")
+	}
+	if IsServerError(NewPostalError("validation_error", "bad", 400)) {
+		t.Error("IsServerError() = true for 400 PostalError")
+	}
+}
+
+func TestValidationErrorIs(t *testing.T) {
+	err := &ValidationError{Problems: []string{"a", "b"}}
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Error("ValidationError should match ErrInvalidMessage")
+	}
+	if got, want := err.Error(), "validation_error: a; b"; got != want {
+		t.Errorf("ValidationError.Error() = %v, want %v", got, want)
+	}
+}
+
+func TestUnexpectedResponseError(t *testing.T) {
+	parseErr := errors.New("invalid character 'i'")
+	err := NewUnexpectedResponseError("failed to parse response", 200, []byte("invalid json"), parseErr)
+
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Error("UnexpectedResponseError should match ErrUnexpectedResponse")
+	}
+	if !errors.Is(err, parseErr) {
+		t.Error("UnexpectedResponseError should unwrap to the parse error")
+	}
+	want := `failed to parse response (status 200): invalid character 'i'; body: "invalid json"`
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+}
+
+func TestGatewayError(t *testing.T) {
+	err := NewGatewayError(502, "text/html", []byte("<h1>Bad Gateway</h1>"))
+
+	if !IsGateway(err) {
+		t.Error("IsGateway() = false for GatewayError")
+	}
+	if !IsServerError(err) {
+		t.Error("IsServerError() = false for 502 GatewayError")
+	}
+	if !IsRetryable(err) {
+		t.Error("IsRetryable() = false for 502 GatewayError")
+	}
+	if err.Category() != CategoryTransport {
+		t.Errorf("Category() = %v, want %v", err.Category(), CategoryTransport)
+	}
+	if want := "gateway error: 502 Bad Gateway from proxy in front of Postal"; err.Error() != want {
+		t.Errorf("Error() = %v, want %v", err.Error(), want)
+	}
+}
+
+func TestBodySnippet(t *testing.T) {
+	short := []byte("short body")
+	if got := BodySnippet(short); got != "short body" {
+		t.Errorf("BodySnippet() = %q, want %q", got, "short body")
+	}
+
+	long := make([]byte, MaxBodySnippet*2)
+	for i := range long {
+		long[i] = 'x'
+	}
+	got := BodySnippet(long)
+	if len(got) != MaxBodySnippet+len("...(truncated)") {
+		t.Errorf("BodySnippet() length = %d, want %d", len(got), MaxBodySnippet+len("...(truncated)"))
+	}
+}
+
 func TestErrorConstants(t *testing.T) {
 	// Test that all error constants are properly defined
 	if ErrInvalidConfig == nil {
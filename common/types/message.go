@@ -23,10 +23,17 @@ type Attachment struct {
 	Data        string `json:"data"` // Base64 encoded
 }
 
-// RawMessage represents a pre-formatted email message
+// RawMessage represents a pre-formatted email message. Mail holds the plain
+// MIME content; the client base64 encodes it into the send/raw request.
 type RawMessage struct {
 	Mail    string            `json:"mail"`
 	To      []string          `json:"to"`
 	From    string            `json:"from"`
 	Headers map[string]string `json:"headers,omitempty"`
 }
+
+// Envelope holds the recipients and sender for raw mail streamed from a reader
+type Envelope struct {
+	To   []string `json:"to"`
+	From string   `json:"from"`
+}
@@ -0,0 +1,118 @@
+package types
+
+import (
+	"encoding/base64"
+	"math"
+	"strings"
+	"time"
+)
+
+// MessageExpansion selects an optional section of a message details response
+type MessageExpansion string
+
+// Expansions accepted by the messages/message endpoint
+const (
+	ExpandStatus      MessageExpansion = "status"
+	ExpandDetails     MessageExpansion = "details"
+	ExpandInspection  MessageExpansion = "inspection"
+	ExpandPlainBody   MessageExpansion = "plain_body"
+	ExpandHTMLBody    MessageExpansion = "html_body"
+	ExpandAttachments MessageExpansion = "attachments"
+	ExpandHeaders     MessageExpansion = "headers"
+	ExpandRawMessage  MessageExpansion = "raw_message"
+)
+
+// MessageDetails is a message as returned by the messages/message endpoint.
+// Sections that were not requested as expansions are nil or empty.
+type MessageDetails struct {
+	ID          int                 `json:"id"`
+	Token       string              `json:"token"`
+	Status      *MessageStatus      `json:"status,omitempty"`
+	Details     *MessageInfo        `json:"details,omitempty"`
+	Inspection  *MessageInspection  `json:"inspection,omitempty"`
+	PlainBody   string              `json:"plain_body,omitempty"`
+	HTMLBody    string              `json:"html_body,omitempty"`
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+	// Headers maps lowercased header names to their values
+	Headers    map[string][]string `json:"headers,omitempty"`
+	RawMessage string              `json:"raw_message,omitempty"` // Base64 encoded
+}
+
+// MessageStatus is the delivery state of a message
+type MessageStatus struct {
+	// Status is Pending, Sent, Held, SoftFail, HardFail or Bounced
+	Status              string   `json:"status"`
+	LastDeliveryAttempt float64  `json:"last_delivery_attempt,omitempty"`
+	Held                bool     `json:"held"`
+	HoldExpiry          *float64 `json:"hold_expiry,omitempty"`
+}
+
+// MessageInfo holds the envelope and metadata of a message
+type MessageInfo struct {
+	RcptTo          string  `json:"rcpt_to"`
+	MailFrom        string  `json:"mail_from"`
+	Subject         string  `json:"subject"`
+	MessageID       string  `json:"message_id"`
+	Timestamp       float64 `json:"timestamp"`
+	Direction       string  `json:"direction"`
+	Size            string  `json:"size"`
+	Bounce          bool    `json:"bounce"`
+	BounceForID     int     `json:"bounce_for_id,omitempty"`
+	Tag             string  `json:"tag,omitempty"`
+	ReceivedWithSSL bool    `json:"received_with_ssl,omitempty"`
+}
+
+// MessageInspection holds the spam and threat checks of a message
+type MessageInspection struct {
+	Inspected     bool    `json:"inspected"`
+	Spam          bool    `json:"spam"`
+	SpamScore     float64 `json:"spam_score"`
+	Threat        bool    `json:"threat"`
+	ThreatDetails string  `json:"threat_details,omitempty"`
+}
+
+// MessageAttachment is an attachment of a stored message
+type MessageAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        string `json:"data,omitempty"` // Base64 encoded
+	Size        int    `json:"size"`
+	Hash        string `json:"hash,omitempty"`
+}
+
+// Header returns the first value of the named header, matched case
+// insensitively, or "" if absent
+func (m *MessageDetails) Header(name string) string {
+	if values := m.Headers[strings.ToLower(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Raw returns the decoded raw message, or nil if it was not expanded
+func (m *MessageDetails) Raw() ([]byte, error) {
+	if m.RawMessage == "" {
+		return nil, nil
+	}
+	return base64.StdEncoding.DecodeString(m.RawMessage)
+}
+
+// LastDeliveryAttemptTime returns when delivery was last attempted, or the
+// zero time if it has not been
+func (s *MessageStatus) LastDeliveryAttemptTime() time.Time {
+	return unixTime(s.LastDeliveryAttempt)
+}
+
+// Time returns when the message was received by the server
+func (i *MessageInfo) Time() time.Time {
+	return unixTime(i.Timestamp)
+}
+
+// unixTime converts a fractional Unix timestamp, treating zero as unset
+func unixTime(ts float64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
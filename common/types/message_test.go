@@ -394,4 +394,32 @@ func messageContainsHelper(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestMessageJSONOmitsEmptyCopies(t *testing.T) {
+	tests := []struct {
+		name string
+		cc   []string
+		bcc  []string
+	}{
+		{name: "nil", cc: nil, bcc: nil},
+		{name: "empty", cc: []string{}, bcc: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(&Message{To: []string{"a@example.com"}, CC: tt.cc, BCC: tt.bcc})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"cc", "bcc"} {
+				if v, ok := fields[name]; ok {
+					t.Errorf("%s = %s, want field omitted", name, v)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,27 @@
 package types
 
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
 // Result represents the response from the Postal API
 type Result struct {
 	MessageID string                 `json:"message_id"`
 	Status    string                 `json:"status"`
 	Data      map[string]interface{} `json:"data,omitempty"`
 	Errors    []string               `json:"errors,omitempty"`
+
+	// ServerTime is the seconds the server reported spending on the request,
+	// from the envelope's time field
+	ServerTime float64 `json:"server_time,omitempty"`
+	// Flags holds the envelope's flags, hints some deployments add about
+	// rate limits or processing
+	Flags map[string]interface{} `json:"server_flags,omitempty"`
+
+	// Retry reports the attempts made by the client's retry layer
+	Retry *RetryStats `json:"-"`
 }
 
 // Success returns true if the API call was successful
@@ -17,3 +33,46 @@ func (r *Result) Success() bool {
 func (r *Result) Failed() bool {
 	return !r.Success()
 }
+
+// QueueID returns the queue identifier from Data, or "" if absent
+func (r *Result) QueueID() string {
+	switch v := r.Data["queue_id"].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// ServerDuration returns ServerTime as a duration
+func (r *Result) ServerDuration() time.Duration {
+	return time.Duration(r.ServerTime * float64(time.Second))
+}
+
+// SentAt returns the send time from Data, accepting RFC 3339 strings and
+// Unix timestamps. It returns the zero time if absent or malformed.
+func (r *Result) SentAt() time.Time {
+	switch v := r.Data["sent_at"].(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9))
+	}
+	return time.Time{}
+}
+
+// RecipientsCount returns the number of recipients from Data, falling back
+// to the size of the per-recipient messages map
+func (r *Result) RecipientsCount() int {
+	if v, ok := r.Data["recipients_count"].(float64); ok {
+		return int(v)
+	}
+	if messages, ok := r.Data["messages"].(map[string]interface{}); ok {
+		return len(messages)
+	}
+	return 0
+}
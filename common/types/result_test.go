@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestResult_Success(t *testing.T) {
@@ -346,6 +347,57 @@ func BenchmarkResultJSONMarshal(b *testing.B) {
 	}
 }
 
+func TestResult_DataAccessors(t *testing.T) {
+	tests := []struct {
+		name           string
+		json           string
+		wantQueueID    string
+		wantSentAt     time.Time
+		wantRecipients int
+	}{
+		{
+			name:           "string fields",
+			json:           `{"data": {"queue_id": "queue_12345", "sent_at": "2023-12-01T10:00:00Z", "recipients_count": 3}}`,
+			wantQueueID:    "queue_12345",
+			wantSentAt:     time.Date(2023, 12, 1, 10, 0, 0, 0, time.UTC),
+			wantRecipients: 3,
+		},
+		{
+			name:           "numeric fields",
+			json:           `{"data": {"queue_id": 98765, "sent_at": 1701424800.5, "messages": {"a@example.com": {"id": 1}, "b@example.com": {"id": 2}}}}`,
+			wantQueueID:    "98765",
+			wantSentAt:     time.Unix(1701424800, 5e8),
+			wantRecipients: 2,
+		},
+		{
+			name: "missing data",
+			json: `{"status": "success"}`,
+		},
+		{
+			name: "wrong types",
+			json: `{"data": {"queue_id": true, "sent_at": "yesterday", "recipients_count": "3"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Result
+			if err := json.Unmarshal([]byte(tt.json), &r); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got := r.QueueID(); got != tt.wantQueueID {
+				t.Errorf("QueueID() = %q, want %q", got, tt.wantQueueID)
+			}
+			if got := r.SentAt(); !got.Equal(tt.wantSentAt) {
+				t.Errorf("SentAt() = %v, want %v", got, tt.wantSentAt)
+			}
+			if got := r.RecipientsCount(); got != tt.wantRecipients {
+				t.Errorf("RecipientsCount() = %d, want %d", got, tt.wantRecipients)
+			}
+		})
+	}
+}
+
 // Helper function for string contains check
 func resultContains(s, substr string) bool {
 	return len(s) >= len(substr) && substr != "" && 
@@ -359,4 +411,10 @@ func resultContainsHelper(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestResult_ServerDuration(t *testing.T) {
+	r := &Result{ServerTime: 0.125}
+	if got := r.ServerDuration(); got != 125*time.Millisecond {
+		t.Errorf("ServerDuration() = %v, want 125ms", got)
+	}
+}
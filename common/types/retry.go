@@ -0,0 +1,36 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryStats describes how hard the client's retry layer worked on a request
+type RetryStats struct {
+	// Attempts is the number of requests made, including the first
+	Attempts int
+	// Elapsed is the time from the first attempt to the last response,
+	// including waits between attempts
+	Elapsed time.Duration
+	// LastError is the last transient error that was retried, or nil if
+	// the first attempt got a final answer
+	LastError error
+}
+
+// RetryError is returned when a request still failed after being attempted
+// more than once. It wraps the final error, so errors.Is and errors.As see
+// through it.
+type RetryError struct {
+	RetryStats
+	Err error
+}
+
+// Error implements the error interface
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (after %d attempts in %v)", e.Err, e.Attempts, e.Elapsed)
+}
+
+// Unwrap returns the final error
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
@@ -6,6 +6,9 @@ import (
 	"strings"
 )
 
+// DefaultAPIPrefix is the path prefix of the Postal HTTP API
+const DefaultAPIPrefix = "api/v1"
+
 // URLBuilder helps construct valid Postal API URLs
 type URLBuilder struct {
 	baseURL string
@@ -21,7 +24,12 @@ func NewURLBuilder(baseURL string) (*URLBuilder, error) {
 
 // BuildPath joins the base URL with the given path
 func (b *URLBuilder) BuildPath(path string) string {
-	return fmt.Sprintf("%s/api/v1/%s", b.baseURL, strings.TrimPrefix(path, "/"))
+	return b.BuildAPIPath(DefaultAPIPrefix, path)
+}
+
+// BuildAPIPath joins the base URL, an API prefix and the given path
+func (b *URLBuilder) BuildAPIPath(prefix, path string) string {
+	return fmt.Sprintf("%s/%s/%s", b.baseURL, strings.Trim(prefix, "/"), strings.TrimPrefix(path, "/"))
 }
 
 // ValidateURL checks if the URL is valid and returns parsed URL
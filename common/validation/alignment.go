@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// AlignmentWarning reports when the From domain is not covered by any of the
+// verified sending domains, which is likely to fail DMARC alignment. A
+// subdomain of a verified domain is treated as covered, as with relaxed
+// alignment. It returns "" when aligned or when no domains are verified.
+func AlignmentWarning(from string, verified []string) string {
+	if len(verified) == 0 {
+		return ""
+	}
+	domain := AddressDomain(from)
+	if domain == "" {
+		return ""
+	}
+
+	for _, v := range verified {
+		v = strings.ToLower(strings.TrimSuffix(v, "."))
+		if domain == v || strings.HasSuffix(domain, "."+v) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("sender domain %s is not a verified sending domain; DMARC alignment may fail", domain)
+}
+
+// AddressDomain returns the lower-cased domain of an address, accepting
+// both bare addresses and "Name <address>" forms
+func AddressDomain(address string) string {
+	if addr, err := mail.ParseAddress(address); err == nil {
+		address = addr.Address
+	}
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return strings.ToLower(address[i+1:])
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package validation
+
+import "testing"
+
+func TestAlignmentWarning(t *testing.T) {
+	verified := []string{"example.com", "Mail.Example.org."}
+
+	tests := []struct {
+		name     string
+		from     string
+		verified []string
+		wantWarn bool
+	}{
+		{name: "exact domain", from: "news@example.com", verified: verified},
+		{name: "subdomain", from: "news@eu.example.com", verified: verified},
+		{name: "case and trailing dot", from: "Team <team@MAIL.example.org>", verified: verified},
+		{name: "suffix but not subdomain", from: "news@badexample.com", verified: verified, wantWarn: true},
+		{name: "unverified domain", from: "news@other.net", verified: verified, wantWarn: true},
+		{name: "no verified domains", from: "news@other.net"},
+		{name: "no domain", from: "invalid", verified: verified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AlignmentWarning(tt.from, tt.verified)
+			if (got != "") != tt.wantWarn {
+				t.Errorf("AlignmentWarning() = %q, wantWarn %v", got, tt.wantWarn)
+			}
+		})
+	}
+}
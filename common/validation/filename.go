@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFilenameLength is the longest filename, in bytes, most filesystems accept
+const maxFilenameLength = 255
+
+// reservedFilenames are device names Windows will not open as files
+var reservedFilenames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// blockedExtensions are executable types commonly stripped or rejected by
+// receiving mail servers
+var blockedExtensions = map[string]bool{
+	".exe": true, ".bat": true, ".cmd": true, ".com": true, ".scr": true, ".pif": true,
+	".js": true, ".vbs": true, ".jar": true, ".msi": true, ".ps1": true,
+}
+
+// AttachmentNameWarnings reports attachment filenames likely to be mangled,
+// renamed or rejected by mail clients and servers. Non-ASCII names are fine:
+// they are encoded per RFC 2231.
+func AttachmentNameWarnings(name string) []string {
+	if name == "" {
+		return []string{"attachment has no filename"}
+	}
+
+	var warnings []string
+	if !utf8.ValidString(name) {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q is not valid UTF-8", name))
+	}
+	if len(name) > maxFilenameLength {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q is longer than %d bytes", name, maxFilenameLength))
+	}
+	if strings.ContainsAny(name, `/\`) {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q contains a path separator", name))
+	}
+	if strings.ContainsAny(name, `<>:"|?*`) {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q contains characters Windows does not allow", name))
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q contains control characters", name))
+	}
+	if strings.TrimRight(name, ". ") != name || strings.TrimLeft(name, " ") != name {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q starts or ends with a space or dot", name))
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.ToUpper(strings.TrimSuffix(name, ext))
+	if reservedFilenames[base] {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q is a reserved Windows device name", name))
+	}
+	if blockedExtensions[strings.ToLower(ext)] {
+		warnings = append(warnings, fmt.Sprintf("attachment filename %q has an executable extension many servers reject", name))
+	}
+	return warnings
+}
@@ -0,0 +1,38 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAttachmentNameWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{name: "plain", filename: "report.pdf"},
+		{name: "non-ascii", filename: "résumé.pdf"},
+		{name: "empty", filename: "", want: "has no filename"},
+		{name: "invalid utf-8", filename: "r\xe9sum\xe9.pdf", want: "not valid UTF-8"},
+		{name: "too long", filename: strings.Repeat("a", 252) + ".pdf", want: "longer than 255 bytes"},
+		{name: "path", filename: "../etc/passwd", want: "path separator"},
+		{name: "windows characters", filename: "a:b?.txt", want: "Windows does not allow"},
+		{name: "control character", filename: "a\tb.txt", want: "control characters"},
+		{name: "trailing dot", filename: "report.", want: "space or dot"},
+		{name: "reserved name", filename: "con.txt", want: "reserved Windows device name"},
+		{name: "executable", filename: "setup.EXE", want: "executable extension"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(AttachmentNameWarnings(tt.filename), "\n")
+			if tt.want == "" && got != "" {
+				t.Errorf("AttachmentNameWarnings(%q) = %q, want none", tt.filename, got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("AttachmentNameWarnings(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
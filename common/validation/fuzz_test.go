@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func FuzzValidateMessage(f *testing.F) {
+	f.Add("recipient@example.com", "Sender <sender@example.com>", "Subject", "body", "report.pdf")
+	f.Add("", "", "", "", "")
+	f.Add("a@b", "@", "\r\n", "\x00", "CON.txt")
+	f.Add("\"quoted\"@example.com, other@example.com", "=?utf-8?q?x?= <x@example.com>", "é", "<p>", "../../etc/passwd")
+
+	f.Fuzz(func(t *testing.T, to, from, subject, body, filename string) {
+		msg := &types.Message{
+			To:       strings.Split(to, ","),
+			From:     from,
+			Subject:  subject,
+			HTMLBody: body,
+			Attachments: []types.Attachment{
+				{Name: filename, ContentType: "application/octet-stream", Data: body},
+			},
+		}
+		err := ValidateMessage(msg)
+		if err != nil && len(err.(*types.ValidationError).Problems) == 0 {
+			t.Error("ValidateMessage() returned an error with no problems")
+		}
+		AttachmentNameWarnings(filename)
+		AlignmentWarning(from, []string{"example.com"})
+		AddressDomain(from)
+	})
+}
+
+func FuzzValidateRawMessage(f *testing.F) {
+	f.Add("From: sender@example.com\r\nTo: recipient@example.com\r\n\r\nBody", "sender@example.com", "recipient@example.com")
+	f.Add("", "", "")
+	f.Add("From: \r\n\r\n", "@", ",")
+	f.Add("To: =?utf-8?b?invalid?= <\r\n\r\n", "a@b", "c@d")
+
+	f.Fuzz(func(t *testing.T, mail, from, to string) {
+		raw := &types.RawMessage{Mail: mail, From: from, To: strings.Split(to, ",")}
+		ValidateRawMessage(raw)
+		RawMessageWarnings(raw)
+		ValidateEnvelope(&types.Envelope{From: from, To: raw.To})
+	})
+}
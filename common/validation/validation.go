@@ -1,12 +1,18 @@
 package validation
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/mail"
 	"strings"
 
 	"github.com/sachin-duhan/postal-go/common/types"
 )
 
+// MaxRawMailSize is the largest raw mail content accepted for sending
+const MaxRawMailSize = 25 << 20
+
 // ValidateMessage validates a message before sending
 func ValidateMessage(msg *types.Message) error {
 	var errors []string
@@ -54,44 +60,172 @@ func ValidateMessage(msg *types.Message) error {
 	}
 
 	if len(errors) > 0 {
-		return types.NewPostalError("validation_error", strings.Join(errors, "; "), 400)
+		return &types.ValidationError{Problems: errors}
 	}
 
 	return nil
 }
 
-// ValidateRawMessage validates a raw message before sending
+// ValidateRawMessage validates a raw message before sending. Mail is checked
+// as plain MIME, before the client base64 encodes it for send/raw.
 func ValidateRawMessage(msg *types.RawMessage) error {
 	var errors []string
 
 	if msg.Mail == "" {
 		errors = append(errors, "raw mail content is required")
+	} else {
+		if problem := rawMailSizeProblem(int64(len(msg.Mail))); problem != "" {
+			errors = append(errors, problem)
+		}
+		if !hasHeaderBodySeparator(msg.Mail) {
+			errors = append(errors, "raw mail content has no blank line separating headers from body")
+		}
 	}
 
-	if len(msg.To) == 0 {
+	errors = append(errors, envelopeProblems(msg.To, msg.From)...)
+
+	if len(errors) > 0 {
+		return &types.ValidationError{Problems: errors}
+	}
+
+	return nil
+}
+
+// ValidateRawMailSize checks size bytes of plain MIME content against
+// MaxRawMailSize, as ValidateRawMessage does, for mail streamed from a reader
+func ValidateRawMailSize(size int64) error {
+	if problem := rawMailSizeProblem(size); problem != "" {
+		return &types.ValidationError{Problems: []string{problem}}
+	}
+	return nil
+}
+
+// rawMailSizeProblem describes raw mail content over MaxRawMailSize
+func rawMailSizeProblem(size int64) string {
+	if size > MaxRawMailSize {
+		return fmt.Sprintf("raw mail content is %d bytes, exceeds limit of %d", size, MaxRawMailSize)
+	}
+	return ""
+}
+
+// SizeLimits bounds the encoded size of outgoing mail. Zero disables a limit.
+type SizeLimits struct {
+	// MaxAttachmentSize limits each attachment's base64 data
+	MaxAttachmentSize int64
+	// MaxMessageSize limits the whole send request body
+	MaxMessageSize int64
+}
+
+// ValidateMessageSize checks msg against limits, returning a
+// *types.MessageTooLargeError for the first part over its limit
+func ValidateMessageSize(msg *types.Message, limits SizeLimits) error {
+	if limits.MaxAttachmentSize > 0 {
+		for _, att := range msg.Attachments {
+			if size := int64(len(att.Data)); size > limits.MaxAttachmentSize {
+				return &types.MessageTooLargeError{Part: fmt.Sprintf("attachment %q", att.Name), Size: size, Limit: limits.MaxAttachmentSize}
+			}
+		}
+	}
+	if limits.MaxMessageSize > 0 {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		if size := int64(len(body)); size > limits.MaxMessageSize {
+			return &types.MessageTooLargeError{Part: "message", Size: size, Limit: limits.MaxMessageSize}
+		}
+	}
+	return nil
+}
+
+// ValidateRawMessageSize checks the size of msg's mail once base64 encoded
+// for send/raw against limits.MaxMessageSize
+func ValidateRawMessageSize(msg *types.RawMessage, limits SizeLimits) error {
+	if size := int64(base64.StdEncoding.EncodedLen(len(msg.Mail))); limits.MaxMessageSize > 0 && size > limits.MaxMessageSize {
+		return &types.MessageTooLargeError{Part: "raw message", Size: size, Limit: limits.MaxMessageSize}
+	}
+	return nil
+}
+
+// ValidateEnvelope validates the envelope of raw mail sent from a stream
+func ValidateEnvelope(env *types.Envelope) error {
+	if errors := envelopeProblems(env.To, env.From); len(errors) > 0 {
+		return &types.ValidationError{Problems: errors}
+	}
+	return nil
+}
+
+// envelopeProblems checks raw message recipients and sender
+func envelopeProblems(to []string, from string) []string {
+	var errors []string
+
+	if len(to) == 0 {
 		errors = append(errors, "recipient (To) is required")
 	}
 
-	if msg.From == "" {
+	if from == "" {
 		errors = append(errors, "sender (From) is required")
 	}
 
 	// Email format validation
-	for _, to := range msg.To {
-		if !isValidEmail(to) {
-			errors = append(errors, fmt.Sprintf("invalid recipient email: %s", to))
+	for _, rcpt := range to {
+		if !isValidEmail(rcpt) {
+			errors = append(errors, fmt.Sprintf("invalid recipient email: %s", rcpt))
 		}
 	}
 
-	if !isValidEmail(msg.From) {
-		errors = append(errors, fmt.Sprintf("invalid sender email: %s", msg.From))
+	if !isValidEmail(from) {
+		errors = append(errors, fmt.Sprintf("invalid sender email: %s", from))
 	}
 
-	if len(errors) > 0 {
-		return types.NewPostalError("validation_error", strings.Join(errors, "; "), 400)
+	return errors
+}
+
+// RawMessageWarnings reports inconsistencies between the envelope and the
+// raw mail headers. They do not prevent sending, since envelope recipients
+// legitimately differ from the headers for Bcc or forwarding.
+func RawMessageWarnings(msg *types.RawMessage) []string {
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.Mail))
+	if err != nil {
+		return nil
 	}
 
-	return nil
+	var warnings []string
+
+	senders := headerAddresses(parsed.Header, "From", "Sender")
+	if msg.From != "" && !senders[strings.ToLower(msg.From)] {
+		warnings = append(warnings, fmt.Sprintf("envelope sender %s does not appear in the From or Sender header", msg.From))
+	}
+
+	recipients := headerAddresses(parsed.Header, "To", "Cc")
+	for _, to := range msg.To {
+		if !recipients[strings.ToLower(to)] {
+			warnings = append(warnings, fmt.Sprintf("envelope recipient %s does not appear in the To or Cc header", to))
+		}
+	}
+
+	return warnings
+}
+
+// hasHeaderBodySeparator reports whether mail contains the blank line that
+// ends the header section
+func hasHeaderBodySeparator(content string) bool {
+	return strings.Contains(content, "\r\n\r\n") || strings.Contains(content, "\n\n")
+}
+
+// headerAddresses collects the lower-cased addresses found in the named headers
+func headerAddresses(header mail.Header, names ...string) map[string]bool {
+	addresses := make(map[string]bool)
+	for _, name := range names {
+		list, err := header.AddressList(name)
+		if err != nil {
+			continue
+		}
+		for _, addr := range list {
+			addresses[strings.ToLower(addr.Address)] = true
+		}
+	}
+	return addresses
 }
 
 // isValidEmail performs basic email format validation
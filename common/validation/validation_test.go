@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -316,6 +317,35 @@ func TestValidateRawMessage(t *testing.T) {
 			wantErr:     true,
 			errContains: []string{"invalid sender email: invalid"},
 		},
+		{
+			name: "LF line endings",
+			message: &types.RawMessage{
+				Mail: "From: sender@example.com\nTo: recipient@example.com\nSubject: Test\n\nBody",
+				To:   []string{"recipient@example.com"},
+				From: "sender@example.com",
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing header/body separator",
+			message: &types.RawMessage{
+				Mail: "From: sender@example.com\r\nTo: recipient@example.com\r\nSubject: Test",
+				To:   []string{"recipient@example.com"},
+				From: "sender@example.com",
+			},
+			wantErr:     true,
+			errContains: []string{"no blank line separating headers from body"},
+		},
+		{
+			name: "mail content too large",
+			message: &types.RawMessage{
+				Mail: "Subject: Test\r\n\r\n" + strings.Repeat("a", MaxRawMailSize),
+				To:   []string{"recipient@example.com"},
+				From: "sender@example.com",
+			},
+			wantErr:     true,
+			errContains: []string{"exceeds limit of"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -336,6 +366,60 @@ func TestValidateRawMessage(t *testing.T) {
 	}
 }
 
+func TestRawMessageWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *types.RawMessage
+		want    []string
+	}{
+		{
+			name: "consistent envelope",
+			message: &types.RawMessage{
+				Mail: "From: Sender <Sender@Example.com>\r\nTo: a@example.com, B <b@example.com>\r\nCc: c@example.com\r\n\r\nBody",
+				To:   []string{"a@example.com", "b@example.com", "c@example.com"},
+				From: "sender@example.com",
+			},
+		},
+		{
+			name: "sender header match",
+			message: &types.RawMessage{
+				Mail: "From: author@example.com\r\nSender: sender@example.com\r\nTo: a@example.com\r\n\r\nBody",
+				To:   []string{"a@example.com"},
+				From: "sender@example.com",
+			},
+		},
+		{
+			name: "envelope not in headers",
+			message: &types.RawMessage{
+				Mail: "From: other@example.com\r\nTo: a@example.com\r\n\r\nBody",
+				To:   []string{"a@example.com", "hidden@example.com"},
+				From: "sender@example.com",
+			},
+			want: []string{
+				"envelope sender sender@example.com does not appear in the From or Sender header",
+				"envelope recipient hidden@example.com does not appear in the To or Cc header",
+			},
+		},
+		{
+			name: "unparseable mail",
+			message: &types.RawMessage{
+				Mail: "not a message",
+				To:   []string{"a@example.com"},
+				From: "sender@example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RawMessageWarnings(tt.message)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("RawMessageWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIsValidEmail(t *testing.T) {
 	tests := []struct {
 		email string
@@ -396,6 +480,13 @@ func TestValidationErrorAggregation(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected validation error")
 	}
+	validationErr, ok := err.(*types.ValidationError)
+	if !ok {
+		t.Fatalf("expected *types.ValidationError, got %T", err)
+	}
+	if len(validationErr.Problems) != 8 {
+		t.Errorf("expected 8 problems, got %d: %v", len(validationErr.Problems), validationErr.Problems)
+	}
 
 	expectedErrors := []string{
 		"subject is required",
@@ -459,4 +550,59 @@ func BenchmarkIsValidEmail(b *testing.B) {
 			_ = isValidEmail(email)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateMessageSize(t *testing.T) {
+	msg := &types.Message{
+		To:      []string{"recipient@example.com"},
+		From:    "sender@example.com",
+		Subject: "Report",
+		Body:    "Attached",
+		Attachments: []types.Attachment{
+			{Name: "small.txt", ContentType: "text/plain", Data: strings.Repeat("A", 100)},
+			{Name: "big.pdf", ContentType: "application/pdf", Data: strings.Repeat("A", 1000)},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		limits   SizeLimits
+		wantPart string
+	}{
+		{"no limits", SizeLimits{}, ""},
+		{"within limits", SizeLimits{MaxAttachmentSize: 1000, MaxMessageSize: 2000}, ""},
+		{"attachment too large", SizeLimits{MaxAttachmentSize: 999}, `attachment "big.pdf"`},
+		{"message too large", SizeLimits{MaxMessageSize: 1100}, "message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMessageSize(msg, tt.limits)
+			if tt.wantPart == "" {
+				if err != nil {
+					t.Fatalf("ValidateMessageSize() error = %v", err)
+				}
+				return
+			}
+			var tooLarge *types.MessageTooLargeError
+			if !errors.As(err, &tooLarge) || tooLarge.Part != tt.wantPart {
+				t.Fatalf("ValidateMessageSize() error = %v, want MessageTooLargeError for %s", err, tt.wantPart)
+			}
+			if !errors.Is(err, types.ErrMessageTooLarge) || !errors.Is(err, types.ErrInvalidMessage) {
+				t.Errorf("error %v does not match ErrMessageTooLarge and ErrInvalidMessage", err)
+			}
+		})
+	}
+
+	// 500 bytes of mail encode to 668
+	raw := &types.RawMessage{Mail: strings.Repeat("A", 500)}
+	var tooLarge *types.MessageTooLargeError
+	if err := ValidateRawMessageSize(raw, SizeLimits{MaxMessageSize: 600}); !errors.As(err, &tooLarge) || tooLarge.Size != 668 {
+		t.Errorf("ValidateRawMessageSize() error = %v, want size 668", err)
+	}
+	if err := ValidateRawMessageSize(raw, SizeLimits{MaxMessageSize: 668}); err != nil {
+		t.Errorf("ValidateRawMessageSize() at the limit error = %v, want nil", err)
+	}
+	if err := ValidateRawMessageSize(raw, SizeLimits{MaxAttachmentSize: 1}); err != nil {
+		t.Errorf("ValidateRawMessageSize() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestCompatibilityModes(t *testing.T) {
+	envelope := `{"status": "success", "time": 0.02, "flags": {}, "data": {"message_id": "env-1", "messages": {}}}`
+	flat := `{"message_id": "flat-1", "status": "success"}`
+
+	tests := []struct {
+		name   string
+		mode   CompatibilityMode
+		body   string
+		wantID string
+	}{
+		{name: "auto with envelope", mode: CompatibilityAuto, body: envelope, wantID: "env-1"},
+		{name: "auto with flat", mode: CompatibilityAuto, body: flat, wantID: "flat-1"},
+		{name: "flat ignores envelope", mode: CompatibilityFlat, body: envelope, wantID: ""},
+		{name: "envelope", mode: CompatibilityEnvelope, body: envelope, wantID: "env-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(200)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			client, err := NewClient(ts.URL, "test-key")
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			cfg := DefaultConfig()
+			cfg.Compatibility = tt.mode
+			client.WithConfig(cfg)
+
+			result, err := client.SendMessage(context.Background(), compatTestMessage())
+			if err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			if result.MessageID != tt.wantID {
+				t.Errorf("MessageID = %q, want %q", result.MessageID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestCompatibilityEnvelopeError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"status": "parameter-error", "time": 0.01, "flags": {}, "data": {"code": "ValidationError", "message": "From address is missing"}}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.SendMessage(context.Background(), compatTestMessage())
+	postalErr, ok := err.(*types.PostalError)
+	if !ok {
+		t.Fatalf("expected *types.PostalError, got %T: %v", err, err)
+	}
+	if postalErr.Code != "ValidationError" || postalErr.Message != "From address is missing" {
+		t.Errorf("unexpected error %v", postalErr)
+	}
+}
+
+func TestCompatibilityPathAndFieldAliases(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/send/message" {
+			t.Errorf("expected path /api/v2/send/message, got %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			t.Fatalf("failed to unmarshal request body: %v", err)
+		}
+		if _, ok := fields["plain_body"]; ok {
+			t.Error("expected plain_body to be renamed")
+		}
+		if fields["text_body"] != "Test Body" {
+			t.Errorf("expected text_body field, got %v", fields)
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "alias-1", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.APIPathPrefix = "/api/v2/"
+	cfg.FieldAliases = map[string]string{"plain_body": "text_body"}
+	client.WithConfig(cfg)
+
+	msg := compatTestMessage()
+	msg.HTMLBody = ""
+	msg.Body = "Test Body"
+	if _, err := client.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+}
+
+func compatTestMessage() *types.Message {
+	return &types.Message{
+		To:       []string{"recipient@example.com"},
+		From:     "sender@example.com",
+		Subject:  "Compatibility Test",
+		HTMLBody: "Test Body",
+	}
+}
+
+func rawTestMessage() *types.RawMessage {
+	return &types.RawMessage{
+		Mail: "From: sender@example.com\r\nTo: recipient@example.com\r\nSubject: Test\r\n\r\nBody",
+		To:   []string{"recipient@example.com"},
+		From: "sender@example.com",
+	}
+}
+
+func TestFieldNamingMatrix(t *testing.T) {
+	msg := compatTestMessage()
+	msg.Body = "Plain"
+	msg.HTMLBody = "<p>HTML</p>"
+	msg.ReplyTo = "reply@example.com"
+	msg.CC = []string{}
+
+	tests := []struct {
+		name    string
+		aliases map[string]string
+		want    []string
+		absent  []string
+	}{
+		{
+			name:   "postal names",
+			want:   []string{"to", "from", "subject", "plain_body", "html_body", "reply_to"},
+			absent: []string{"cc", "bcc"},
+		},
+		{
+			name:    "text and html body",
+			aliases: map[string]string{"plain_body": "text_body", "html_body": "html"},
+			want:    []string{"to", "from", "subject", "text_body", "html", "reply_to"},
+			absent:  []string{"plain_body", "html_body", "cc", "bcc"},
+		},
+		{
+			name:    "camel case reply-to",
+			aliases: map[string]string{"reply_to": "replyTo"},
+			want:    []string{"plain_body", "html_body", "replyTo"},
+			absent:  []string{"reply_to", "cc", "bcc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields map[string]json.RawMessage
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&fields)
+				w.WriteHeader(200)
+				w.Write([]byte(`{"message_id": "matrix-1", "status": "success"}`))
+			}))
+			defer ts.Close()
+
+			client, err := NewClient(ts.URL, "test-key", WithFieldAliases(tt.aliases))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			if _, err := client.SendMessage(context.Background(), msg); err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+
+			for _, name := range tt.want {
+				if _, ok := fields[name]; !ok {
+					t.Errorf("field %q missing from %v", name, fields)
+				}
+			}
+			for _, name := range tt.absent {
+				if _, ok := fields[name]; ok {
+					t.Errorf("field %q present, want it absent", name)
+				}
+			}
+		})
+	}
+}
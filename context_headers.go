@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// applyContextHeaders copies configured context values into request headers.
+// Headers already set on the request, such as a per-call idempotency key,
+// are left untouched. Names are canonicalized so that a header configured
+// in another case is recognised as the same one.
+func (c *clientImpl) applyContextHeaders(ctx context.Context, req *transport.Request) {
+	for _, ch := range c.config.ContextHeaders {
+		value := ctx.Value(ch.Key)
+		if value == nil {
+			continue
+		}
+		name := http.CanonicalHeaderKey(ch.Header)
+		if _, ok := req.Headers[name]; ok {
+			continue
+		}
+
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[name] = headerValue(value)
+	}
+}
+
+// headerValue formats a context value for use in a header
+func headerValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
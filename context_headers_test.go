@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ctxKey string
+
+type requestID int
+
+func (id requestID) String() string {
+	return "req-" + string(rune('0'+int(id)))
+}
+
+func TestContextHeaders(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12355", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.ContextHeaders = []ContextHeader{
+		{Key: ctxKey("trace"), Header: "X-Trace-ID"},
+		{Key: ctxKey("tenant"), Header: "X-Tenant-ID"},
+		{Key: ctxKey("request"), Header: "X-Request-ID"},
+		{Key: ctxKey("missing"), Header: "X-Missing"},
+	}
+	client.WithConfig(cfg)
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "abc123")
+	ctx = context.WithValue(ctx, ctxKey("tenant"), 42)
+	ctx = context.WithValue(ctx, ctxKey("request"), requestID(7))
+
+	if _, err := client.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	want := map[string]string{
+		"X-Trace-ID":   "abc123",
+		"X-Tenant-ID":  "42",
+		"X-Request-ID": "req-7",
+		"X-Missing":    "",
+	}
+	for header, value := range want {
+		if got.Get(header) != value {
+			t.Errorf("header %s = %q, want %q", header, got.Get(header), value)
+		}
+	}
+}
+
+func TestContextHeaderCaseWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Values("X-Request-Key")...)
+		w.Write([]byte(`{"message_id": "12355", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key", WithIdempotencyKeys("X-Request-Key"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := *client.(*clientImpl).config
+	cfg.ContextHeaders = []ContextHeader{{Key: ctxKey("request"), Header: "X-REQUEST-KEY"}}
+	client.WithConfig(&cfg)
+	ctx := context.WithValue(context.Background(), ctxKey("request"), "from-context")
+
+	// Repeat so that a nondeterministic pick between the two would show
+	for i := 0; i < 20; i++ {
+		if _, err := client.SendMessage(ctx, compatTestMessage(), WithIdempotencyKey("per-call")); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	if _, err := client.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	for i, key := range keys[:20] {
+		if key != "per-call" {
+			t.Fatalf("send %d: key = %q, want the per-call key", i, key)
+		}
+	}
+	if len(keys) != 21 || keys[20] != "from-context" {
+		t.Errorf("keys = %q, want the context key without a per-call one", keys)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// ErrNoDefaultClient is returned by the package-level send helpers when no
+// default client has been set
+var ErrNoDefaultClient = errors.New("no default client set")
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient Client
+)
+
+// SetDefault sets the client used by the package-level send helpers and
+// returns the previous one. Passing nil clears it. It is safe to call
+// concurrently with sends; in-flight sends keep the client they started with.
+func SetDefault(c Client) Client {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	prev := defaultClient
+	defaultClient = c
+	return prev
+}
+
+// Default returns the client set with SetDefault, or nil if there is none
+func Default() Client {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultClient
+}
+
+// SendMessage sends msg with the default client
+func SendMessage(ctx context.Context, msg *types.Message, opts ...SendOption) (*types.Result, error) {
+	c := Default()
+	if c == nil {
+		return nil, ErrNoDefaultClient
+	}
+	return c.SendMessage(ctx, msg, opts...)
+}
+
+// SendRawMessage sends raw with the default client
+func SendRawMessage(ctx context.Context, raw *types.RawMessage, opts ...SendOption) (*types.Result, error) {
+	c := Default()
+	if c == nil {
+		return nil, ErrNoDefaultClient
+	}
+	return c.SendRawMessage(ctx, raw, opts...)
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDefaultClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12356", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	prev := SetDefault(nil)
+	defer SetDefault(prev)
+
+	ctx := context.Background()
+	if _, err := SendMessage(ctx, compatTestMessage()); !errors.Is(err, ErrNoDefaultClient) {
+		t.Fatalf("SendMessage() without default error = %v, want ErrNoDefaultClient", err)
+	}
+	if _, err := SendRawMessage(ctx, rawTestMessage()); !errors.Is(err, ErrNoDefaultClient) {
+		t.Fatalf("SendRawMessage() without default error = %v, want ErrNoDefaultClient", err)
+	}
+
+	c, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if got := SetDefault(c); got != nil {
+		t.Errorf("SetDefault() returned %v, want nil", got)
+	}
+	if Default() != c {
+		t.Error("Default() did not return the client just set")
+	}
+
+	// Concurrent swaps and sends must be race free
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefault(c)
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := SendMessage(ctx, compatTestMessage()); err != nil {
+				t.Errorf("SendMessage() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := SendRawMessage(ctx, rawTestMessage()); err != nil {
+		t.Errorf("SendRawMessage() error = %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+// Package deliverability checks a sending domain's DNS authentication
+// records (SPF, DKIM and DMARC)
+package deliverability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Severity ranks a reported problem
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is an issue found with a domain's DNS records
+type Problem struct {
+	Check    string // "spf", "dkim", "dmarc", "mx" or "return_path"
+	Severity Severity
+	Message  string
+	// Fix is the DNS record that would resolve the problem, when known
+	Fix string
+}
+
+// String formats the problem for display
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s: %s", p.Severity, p.Check, p.Message)
+}
+
+// Report is the result of checking a domain
+type Report struct {
+	Domain   string
+	SPF      string // the SPF record, if found
+	DKIM     string // the DKIM record for the selector, if found
+	DMARC    string // the DMARC record, if found
+	Problems []Problem
+}
+
+// OK returns true if no errors were found. Warnings do not affect OK.
+func (r *Report) OK() bool {
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolver looks up TXT records. *net.Resolver implements it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Checker checks domains' DNS authentication records
+type Checker struct {
+	// Resolver defaults to net.DefaultResolver
+	Resolver Resolver
+	// SPFInclude, when set, is the include mechanism the SPF record must
+	// contain to authorize the Postal server, e.g. "spf.postal.example.com"
+	SPFInclude string
+	// DKIMRecord, when set, is the DKIM record Postal shows for the domain.
+	// The published key must match it, and it is suggested as the fix.
+	DKIMRecord string
+	// ReturnPathHost, when set, is the host the domain's return path CNAME
+	// must point to, e.g. "rp.postal.example.com"
+	ReturnPathHost string
+}
+
+// Check looks up the SPF record of domain, the DKIM record for selector
+// and the DMARC policy, reporting any problems. An empty selector skips
+// the DKIM check.
+func (c *Checker) Check(ctx context.Context, domain, selector string) (*Report, error) {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	r := &Report{Domain: domain}
+
+	var err error
+	if r.SPF, err = c.lookup(ctx, domain, "v=spf1"); err != nil {
+		return nil, err
+	}
+	c.checkSPF(r)
+
+	if selector != "" {
+		if r.DKIM, err = c.lookup(ctx, selector+"._domainkey."+domain, ""); err != nil {
+			return nil, err
+		}
+		c.checkDKIM(r, selector)
+	}
+
+	if r.DMARC, err = c.lookup(ctx, "_dmarc."+domain, "v=DMARC1"); err != nil {
+		return nil, err
+	}
+	checkDMARC(r)
+
+	return r, nil
+}
+
+// lookup returns the first TXT record at name starting with prefix. A
+// missing name is not an error and yields "".
+func (c *Checker) lookup(ctx context.Context, name, prefix string) (string, error) {
+	resolver := c.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	records, err := resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to look up TXT records for %s: %w", name, err)
+	}
+
+	for _, record := range records {
+		if prefix == "" || strings.HasPrefix(strings.ToLower(record), strings.ToLower(prefix)) {
+			return record, nil
+		}
+	}
+	return "", nil
+}
+
+// checkSPF reports a missing SPF record, a missing include for the Postal
+// server and overly permissive policies
+func (c *Checker) checkSPF(r *Report) {
+	if r.SPF == "" {
+		r.addFix("spf", SeverityError, "no SPF record found", txtFix(r.Domain, c.spfRecord("")))
+		return
+	}
+
+	fields := strings.Fields(strings.ToLower(r.SPF))
+	if c.SPFInclude != "" && !contains(fields, "include:"+strings.ToLower(c.SPFInclude)) {
+		r.addFix("spf", SeverityError, fmt.Sprintf("record does not include %s", c.SPFInclude), txtFix(r.Domain, c.spfRecord(r.SPF)))
+	}
+	switch last := fields[len(fields)-1]; last {
+	case "+all", "all":
+		r.add("spf", SeverityError, "record allows any server to send (+all)")
+	case "?all":
+		r.add("spf", SeverityWarning, "record ends with neutral ?all")
+	}
+}
+
+// checkDKIM reports a missing, revoked or mismatched DKIM key
+func (c *Checker) checkDKIM(r *Report, selector string) {
+	fix := c.dkimFix(r.Domain, selector)
+	if r.DKIM == "" {
+		r.addFix("dkim", SeverityError, fmt.Sprintf("no DKIM record found for selector %s", selector), fix)
+		return
+	}
+	p, ok := parseTags(r.DKIM)["p"]
+	switch {
+	case !ok || p == "":
+		r.addFix("dkim", SeverityError, "DKIM record has no public key (p=)", fix)
+	case c.DKIMRecord != "" && p != parseTags(c.DKIMRecord)["p"]:
+		r.addFix("dkim", SeverityError, "DKIM public key does not match the server's key", fix)
+	}
+}
+
+// checkDMARC reports a missing DMARC record or a policy that does not act
+// on failures
+func checkDMARC(r *Report) {
+	if r.DMARC == "" {
+		r.addFix("dmarc", SeverityWarning, "no DMARC record found", txtFix("_dmarc."+r.Domain, "v=DMARC1; p=none; rua=mailto:dmarc@"+r.Domain))
+		return
+	}
+	tags := parseTags(r.DMARC)
+	switch strings.ToLower(tags["p"]) {
+	case "reject", "quarantine":
+	case "none":
+		r.add("dmarc", SeverityWarning, "policy is p=none, failures are only monitored")
+	default:
+		r.add("dmarc", SeverityError, "record has no valid policy (p=)")
+	}
+}
+
+// add records a problem
+func (r *Report) add(check string, severity Severity, message string) {
+	r.addFix(check, severity, message, "")
+}
+
+// addFix records a problem with a suggested fix
+func (r *Report) addFix(check string, severity Severity, message, fix string) {
+	r.Problems = append(r.Problems, Problem{Check: check, Severity: severity, Message: message, Fix: fix})
+}
+
+// spfRecord returns current with the Postal include added before its "all"
+// mechanism, or a new record when current is empty
+func (c *Checker) spfRecord(current string) string {
+	include := ""
+	if c.SPFInclude != "" {
+		include = " include:" + c.SPFInclude
+	}
+	if current == "" {
+		return "v=spf1 a mx" + include + " ~all"
+	}
+	fields := strings.Fields(current)
+	last := strings.ToLower(fields[len(fields)-1])
+	if strings.HasSuffix(last, "all") && len(fields) > 1 {
+		return strings.Join(fields[:len(fields)-1], " ") + include + " " + fields[len(fields)-1]
+	}
+	return current + include
+}
+
+// dkimFix suggests the server's DKIM record, when configured
+func (c *Checker) dkimFix(domain, selector string) string {
+	if c.DKIMRecord == "" || selector == "" {
+		return ""
+	}
+	return txtFix(selector+"._domainkey."+domain, c.DKIMRecord)
+}
+
+// txtFix formats a suggested TXT record
+func txtFix(name, value string) string {
+	return fmt.Sprintf("%s TXT %q", name, value)
+}
+
+// parseTags parses "k=v; k=v" records such as DKIM and DMARC
+func parseTags(record string) map[string]string {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(record, ";") {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			tags[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+		}
+	}
+	return tags
+}
+
+// contains reports whether s is in list
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package deliverability
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeResolver serves TXT records from a map; missing names are NXDOMAIN
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		records fakeResolver
+		want    []string
+		wantOK  bool
+	}{
+		{
+			name: "healthy domain",
+			records: fakeResolver{
+				"example.com":                   {"google-site-verification=abc", "v=spf1 a mx include:spf.postal.example.com ~all"},
+				"postal._domainkey.example.com": {"v=DKIM1; t=s; h=sha256; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQ"},
+				"_dmarc.example.com":            {"v=DMARC1; p=reject; rua=mailto:dmarc@example.com"},
+			},
+			wantOK: true,
+		},
+		{
+			name:    "nothing published",
+			records: fakeResolver{},
+			want: []string{
+				"error spf: no SPF record found",
+				"error dkim: no DKIM record found for selector postal",
+				"warning dmarc: no DMARC record found",
+			},
+		},
+		{
+			name: "weak policies",
+			records: fakeResolver{
+				"example.com":                   {"v=spf1 include:_spf.other.net +all"},
+				"postal._domainkey.example.com": {"v=DKIM1; p="},
+				"_dmarc.example.com":            {"v=DMARC1; p=none"},
+			},
+			want: []string{
+				"error spf: record does not include spf.postal.example.com",
+				"error spf: record allows any server to send (+all)",
+				"error dkim: DKIM record has no public key (p=)",
+				"warning dmarc: policy is p=none, failures are only monitored",
+			},
+		},
+		{
+			name: "warnings only",
+			records: fakeResolver{
+				"example.com":                   {"v=spf1 include:spf.postal.example.com ?all"},
+				"postal._domainkey.example.com": {"p=abc"},
+				"_dmarc.example.com":            {"v=DMARC1; p=quarantine"},
+			},
+			want:   []string{"warning spf: record ends with neutral ?all"},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Checker{Resolver: tt.records, SPFInclude: "spf.postal.example.com"}
+			report, err := c.Check(context.Background(), "Example.com.", "postal")
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			var got []string
+			for _, p := range report.Problems {
+				got = append(got, p.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Problems = %q, want %q", got, tt.want)
+			}
+			if report.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v", report.OK(), tt.wantOK)
+			}
+		})
+	}
+}
+
+type failingResolver struct{}
+
+func (failingResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+}
+
+func TestCheckerLookupFailure(t *testing.T) {
+	c := &Checker{Resolver: failingResolver{}}
+	_, err := c.Check(context.Background(), "example.com", "")
+
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		t.Errorf("Check() error = %v, want a wrapped *net.DNSError", err)
+	}
+}
+
+func TestCheckerFixes(t *testing.T) {
+	c := &Checker{
+		Resolver: fakeResolver{
+			"example.com":                   {"v=spf1 include:_spf.google.com -all"},
+			"postal._domainkey.example.com": {"v=DKIM1; t=s; h=sha256; p=OLDKEY"},
+		},
+		SPFInclude: "spf.postal.example.com",
+		DKIMRecord: "v=DKIM1; t=s; h=sha256; p=NEWKEY",
+	}
+	report, err := c.Check(context.Background(), "example.com", "postal")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	want := map[string]string{
+		"spf":   `example.com TXT "v=spf1 include:_spf.google.com include:spf.postal.example.com -all"`,
+		"dkim":  `postal._domainkey.example.com TXT "v=DKIM1; t=s; h=sha256; p=NEWKEY"`,
+		"dmarc": `_dmarc.example.com TXT "v=DMARC1; p=none; rua=mailto:dmarc@example.com"`,
+	}
+	if len(report.Problems) != len(want) {
+		t.Fatalf("Problems = %v, want one per record", report.Problems)
+	}
+	for _, p := range report.Problems {
+		if p.Fix != want[p.Check] {
+			t.Errorf("%s fix = %s, want %s", p.Check, p.Fix, want[p.Check])
+		}
+	}
+	if report.Problems[1].Message != "DKIM public key does not match the server's key" {
+		t.Errorf("dkim problem = %q", report.Problems[1].Message)
+	}
+}
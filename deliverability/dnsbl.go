@@ -0,0 +1,150 @@
+package deliverability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// DefaultBlocklists are commonly consulted DNS blocklists
+var DefaultBlocklists = []string{
+	"zen.spamhaus.org",
+	"bl.spamcop.net",
+	"b.barracudacentral.org",
+	"psbl.surriel.com",
+}
+
+// HostResolver looks up A records. *net.Resolver implements it.
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Listing is a sending IP found on a blocklist
+type Listing struct {
+	IP        string
+	Blocklist string
+	// Codes are the 127.0.0.x return codes, which identify the listing
+	// reason on most blocklists
+	Codes []string
+}
+
+// BlocklistChecker queries DNS blocklists for sending IPs
+type BlocklistChecker struct {
+	// Resolver defaults to net.DefaultResolver
+	Resolver HostResolver
+	// Blocklists defaults to DefaultBlocklists
+	Blocklists []string
+	// OnListed, when set, is called for each listing found
+	OnListed func(Listing)
+}
+
+// Check queries every blocklist for each IP in parallel and returns the
+// listings found. Lookups that fail are reported in the joined error
+// alongside any listings.
+func (c *BlocklistChecker) Check(ctx context.Context, ips ...string) ([]Listing, error) {
+	resolver := c.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	blocklists := c.Blocklists
+	if blocklists == nil {
+		blocklists = DefaultBlocklists
+	}
+
+	// Reject invalid IPs before any lookup starts appending to errs
+	var (
+		errs     []error
+		valid    []string
+		reversed = make(map[string]string, len(ips))
+	)
+	for _, ip := range ips {
+		r, err := reverseIP(ip)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		valid = append(valid, ip)
+		reversed[ip] = r
+	}
+
+	var (
+		mu       sync.Mutex
+		listings []Listing
+		wg       sync.WaitGroup
+	)
+	for _, ip := range valid {
+		for _, bl := range blocklists {
+			wg.Add(1)
+			go func(ip, bl string) {
+				defer wg.Done()
+				listing, err := query(ctx, resolver, ip, reversed[ip]+"."+bl, bl)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+				} else if listing != nil {
+					listings = append(listings, *listing)
+				}
+			}(ip, bl)
+		}
+	}
+	wg.Wait()
+
+	if c.OnListed != nil {
+		for _, l := range listings {
+			c.OnListed(l)
+		}
+	}
+	return listings, errors.Join(errs...)
+}
+
+// query looks up one blocklist name. NXDOMAIN means the IP is not listed.
+func query(ctx context.Context, resolver HostResolver, ip, name, blocklist string) (*Listing, error) {
+	addrs, err := resolver.LookupHost(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: failed to query %s: %w", blocklist, ip, err)
+	}
+
+	listing := &Listing{IP: ip, Blocklist: blocklist}
+	for _, addr := range addrs {
+		// 127.255.255.x responses signal a refused or rate limited query
+		if strings.HasPrefix(addr, "127.255.255.") {
+			return nil, fmt.Errorf("%s: query for %s refused (%s)", blocklist, ip, addr)
+		}
+		if strings.HasPrefix(addr, "127.") {
+			listing.Codes = append(listing.Codes, addr)
+		}
+	}
+	if len(listing.Codes) == 0 {
+		return nil, nil
+	}
+	return listing, nil
+}
+
+// reverseIP returns the blocklist query prefix for ip: reversed octets for
+// IPv4 and reversed nibbles for IPv6
+func reverseIP(ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", fmt.Errorf("invalid IP address %q", ip)
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d", v4[3], v4[2], v4[1], v4[0]), nil
+	}
+
+	const hexDigits = "0123456789abcdef"
+	nibbles := make([]string, 0, 32)
+	for i := len(parsed) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, string(hexDigits[parsed[i]&0xf]), string(hexDigits[parsed[i]>>4]))
+	}
+	return strings.Join(nibbles, "."), nil
+}
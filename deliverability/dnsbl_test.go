@@ -0,0 +1,83 @@
+package deliverability
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeHosts serves A records from a map; missing names are NXDOMAIN
+type fakeHosts map[string][]string
+
+func (f fakeHosts) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, ok := f[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func TestBlocklistChecker(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		notified []string
+	)
+	c := &BlocklistChecker{
+		Resolver: fakeHosts{
+			"2.2.0.192.zen.example":   {"127.0.0.2", "127.0.0.4"},
+			"2.2.0.192.other.example": {"127.255.255.254"},
+			"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.zen.example": {"127.0.0.3"},
+		},
+		Blocklists: []string{"zen.example", "other.example"},
+		OnListed: func(l Listing) {
+			mu.Lock()
+			defer mu.Unlock()
+			notified = append(notified, l.IP)
+		},
+	}
+
+	listings, err := c.Check(context.Background(), "192.0.2.2", "192.0.2.3", "2001:db8::1", "not-an-ip")
+
+	sort.Slice(listings, func(i, j int) bool { return listings[i].IP < listings[j].IP })
+	if len(listings) != 2 {
+		t.Fatalf("listings = %+v, want 2", listings)
+	}
+	if l := listings[0]; l.IP != "192.0.2.2" || l.Blocklist != "zen.example" || strings.Join(l.Codes, ",") != "127.0.0.2,127.0.0.4" {
+		t.Errorf("listings[0] = %+v", l)
+	}
+	if l := listings[1]; l.IP != "2001:db8::1" || l.Blocklist != "zen.example" {
+		t.Errorf("listings[1] = %+v", l)
+	}
+	if len(notified) != 2 {
+		t.Errorf("OnListed called %d times, want 2", len(notified))
+	}
+
+	if err == nil {
+		t.Fatal("expected errors for the refused query and invalid IP")
+	}
+	for _, want := range []string{"other.example: query for 192.0.2.2 refused", `invalid IP address "not-an-ip"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestReverseIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"192.0.2.1", "1.2.0.192"},
+		{"::ffff:192.0.2.1", "1.2.0.192"},
+		{"2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2"},
+	}
+	for _, tt := range tests {
+		got, err := reverseIP(tt.ip)
+		if err != nil || got != tt.want {
+			t.Errorf("reverseIP(%q) = %q, %v, want %q", tt.ip, got, err, tt.want)
+		}
+	}
+}
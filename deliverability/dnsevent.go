@@ -0,0 +1,99 @@
+package deliverability
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// EventDomainDNSError is the name of Postal's DNS error webhook event
+const EventDomainDNSError = "DomainDNSError"
+
+// DNSErrorEvent is the payload of a DomainDNSError webhook, sent when
+// Postal's periodic DNS check finds a domain's records broken. Statuses
+// are "OK", "Missing" or "Invalid", with the reason in the matching error.
+type DNSErrorEvent struct {
+	Domain           string  `json:"domain"`
+	UUID             string  `json:"uuid"`
+	DNSCheckedAt     float64 `json:"dns_checked_at"`
+	SPFStatus        string  `json:"spf_status"`
+	SPFError         string  `json:"spf_error"`
+	DKIMStatus       string  `json:"dkim_status"`
+	DKIMError        string  `json:"dkim_error"`
+	MXStatus         string  `json:"mx_status"`
+	MXError          string  `json:"mx_error"`
+	ReturnPathStatus string  `json:"return_path_status"`
+	ReturnPathError  string  `json:"return_path_error"`
+}
+
+// ParseDNSErrorEvent decodes a DomainDNSError payload, either bare or
+// inside the webhook's {"event", "payload"} wrapper
+func ParseDNSErrorEvent(data []byte) (*DNSErrorEvent, error) {
+	var wrapper struct {
+		Event   string          `json:"event"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid DNS error event: %w", err)
+	}
+	if wrapper.Event != "" && wrapper.Event != EventDomainDNSError {
+		return nil, fmt.Errorf("invalid DNS error event: event is %s", wrapper.Event)
+	}
+	if len(wrapper.Payload) > 0 {
+		data = wrapper.Payload
+	}
+
+	var ev DNSErrorEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, fmt.Errorf("invalid DNS error event: %w", err)
+	}
+	if ev.Domain == "" {
+		return nil, fmt.Errorf("invalid DNS error event: domain is empty")
+	}
+	return &ev, nil
+}
+
+// CheckedAt returns when Postal checked the records
+func (ev *DNSErrorEvent) CheckedAt() time.Time {
+	sec, frac := math.Modf(ev.DNSCheckedAt)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// Diagnose turns the failing records of ev into problems, with the records
+// the checker's configuration expects as fixes. Postal does not report the
+// DKIM selector, so the DKIM fix is only suggested when selector is given.
+func (c *Checker) Diagnose(ev *DNSErrorEvent, selector string) []Problem {
+	r := &Report{Domain: ev.Domain}
+	for _, record := range []struct {
+		check, status, err, fix string
+	}{
+		{"spf", ev.SPFStatus, ev.SPFError, txtFix(ev.Domain, c.spfRecord(""))},
+		{"dkim", ev.DKIMStatus, ev.DKIMError, c.dkimFix(ev.Domain, selector)},
+		{"mx", ev.MXStatus, ev.MXError, ""},
+		{"return_path", ev.ReturnPathStatus, ev.ReturnPathError, c.returnPathFix(ev.Domain)},
+	} {
+		if record.status == "" || record.status == "OK" {
+			continue
+		}
+		message := record.status
+		if record.err != "" {
+			message += ": " + record.err
+		}
+		severity := SeverityError
+		if record.check == "mx" || record.check == "return_path" {
+			// Sending still works; only replies or bounce handling suffer
+			severity = SeverityWarning
+		}
+		r.addFix(record.check, severity, message, record.fix)
+	}
+	return r.Problems
+}
+
+// returnPathFix suggests the return path CNAME, when configured
+func (c *Checker) returnPathFix(domain string) string {
+	if c.ReturnPathHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("psrp.%s CNAME %s", domain, c.ReturnPathHost)
+}
@@ -0,0 +1,75 @@
+package deliverability
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const dnsErrorWebhook = `{
+	"event": "DomainDNSError",
+	"timestamp": 1700000100.0,
+	"uuid": "a1b2",
+	"payload": {
+		"domain": "example.com",
+		"uuid": "c3d4",
+		"dns_checked_at": 1700000000.0,
+		"spf_status": "Invalid",
+		"spf_error": "An SPF record exists but it doesn't include spf.postal.example.com",
+		"dkim_status": "Missing",
+		"dkim_error": "No TXT records were returned for postal-AbCdEf._domainkey.example.com",
+		"mx_status": "OK",
+		"mx_error": null,
+		"return_path_status": "Missing",
+		"return_path_error": "There is no return path record at psrp.example.com"
+	}
+}`
+
+func TestParseDNSErrorEvent(t *testing.T) {
+	ev, err := ParseDNSErrorEvent([]byte(dnsErrorWebhook))
+	if err != nil {
+		t.Fatalf("ParseDNSErrorEvent() error = %v", err)
+	}
+	if ev.Domain != "example.com" || ev.SPFStatus != "Invalid" || ev.DKIMStatus != "Missing" {
+		t.Errorf("event = %+v", ev)
+	}
+	if !ev.CheckedAt().Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("CheckedAt() = %v", ev.CheckedAt())
+	}
+
+	// The bare payload is accepted too
+	if ev, err := ParseDNSErrorEvent([]byte(`{"domain": "example.com", "spf_status": "OK"}`)); err != nil || ev.Domain != "example.com" {
+		t.Errorf("ParseDNSErrorEvent(bare) = %+v, %v", ev, err)
+	}
+
+	for _, bad := range []string{`{`, `{"event": "MessageSent", "payload": {"domain": "example.com"}}`, `{"spf_status": "OK"}`} {
+		if _, err := ParseDNSErrorEvent([]byte(bad)); err == nil {
+			t.Errorf("ParseDNSErrorEvent(%s) expected error", bad)
+		}
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	ev, err := ParseDNSErrorEvent([]byte(dnsErrorWebhook))
+	if err != nil {
+		t.Fatalf("ParseDNSErrorEvent() error = %v", err)
+	}
+	c := &Checker{
+		SPFInclude:     "spf.postal.example.com",
+		DKIMRecord:     "v=DKIM1; t=s; h=sha256; p=KEY",
+		ReturnPathHost: "rp.postal.example.com",
+	}
+
+	var got []string
+	for _, p := range c.Diagnose(ev, "postal-AbCdEf") {
+		got = append(got, p.String()+" => "+p.Fix)
+	}
+	want := []string{
+		`error spf: Invalid: An SPF record exists but it doesn't include spf.postal.example.com => example.com TXT "v=spf1 a mx include:spf.postal.example.com ~all"`,
+		`error dkim: Missing: No TXT records were returned for postal-AbCdEf._domainkey.example.com => postal-AbCdEf._domainkey.example.com TXT "v=DKIM1; t=s; h=sha256; p=KEY"`,
+		`warning return_path: Missing: There is no return path record at psrp.example.com => psrp.example.com CNAME rp.postal.example.com`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diagnose() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// Do implements Client
+func (c *clientImpl) Do(ctx context.Context, method, path string, body, out interface{}) (*types.Result, error) {
+	path = strings.TrimPrefix(path, "/")
+	req := &transport.Request{Method: method, Path: path, Body: body, Data: out}
+	// Sends through Do count against the error budget and tenant quota like
+	// any other send
+	return c.execute(ctx, req, strings.HasPrefix(path, "send/"))
+}
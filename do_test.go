@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestDo(t *testing.T) {
+	var path, key string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("X-Server-API-Key")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/messages/missing" {
+			w.Write([]byte(`{"status": "error", "time": 0.01, "flags": {}, "data": {"code": "MessageNotFound", "message": "No message found"}}`))
+			return
+		}
+		w.Write([]byte(`{"status": "success", "time": 0.01, "flags": {}, "data": {"id": 5, "token": "abc"}}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var out struct {
+		ID    int    `json:"id"`
+		Token string `json:"token"`
+	}
+	if _, err := c.Do(context.Background(), http.MethodPost, "/messages/custom", map[string]int{"id": 5}, &out); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if path != "/api/v1/messages/custom" || key != "test-key" {
+		t.Errorf("request to %q with key %q, want /api/v1/messages/custom with test-key", path, key)
+	}
+	if out.ID != 5 || out.Token != "abc" {
+		t.Errorf("out = %+v, want id 5 and token abc", out)
+	}
+
+	result, err := c.Do(context.Background(), http.MethodPost, "messages/custom", nil, nil)
+	if err != nil {
+		t.Fatalf("Do() without out error = %v", err)
+	}
+	if result.Data["token"] != "abc" {
+		t.Errorf("Result.Data = %v, want token abc", result.Data)
+	}
+
+	_, err = c.Do(context.Background(), http.MethodPost, "messages/missing", nil, nil)
+	var postalErr *types.PostalError
+	if !errors.As(err, &postalErr) || postalErr.Code != "MessageNotFound" {
+		t.Errorf("Do() error = %v, want MessageNotFound", err)
+	}
+}
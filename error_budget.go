@@ -0,0 +1,162 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// Error budget alert kinds
+const (
+	AlertFailureRate = "failure_rate"
+	AlertBounceRate  = "bounce_rate"
+)
+
+// BudgetAlert reports an error budget threshold being crossed
+type BudgetAlert struct {
+	Kind      string
+	Rate      float64
+	Threshold float64
+	// Sends is the number of sends in the window the rate was computed over
+	Sends int
+}
+
+// ErrorBudget tracks send failures and bounces over a sliding window and
+// calls OnAlert when a rate exceeds its threshold. An alert fires once when
+// the threshold is crossed and again only after the rate has recovered.
+// Register it with WithErrorBudget; report bounces from webhook or inbound
+// processing with RecordBounce.
+type ErrorBudget struct {
+	// Window is the sliding window rates are computed over
+	Window time.Duration
+	// MaxFailureRate is the tolerated fraction of failed sends, e.g. 0.02.
+	// Zero disables the check.
+	MaxFailureRate float64
+	// MaxBounceRate is the tolerated ratio of bounces to sends, e.g. 0.05.
+	// Zero disables the check.
+	MaxBounceRate float64
+	// MinSends is the number of sends in the window required before alerting
+	MinSends int
+	// OnAlert is called when a threshold is crossed
+	OnAlert func(BudgetAlert)
+
+	mu       sync.Mutex
+	now      func() time.Time
+	sends    []budgetEvent
+	bounces  []time.Time
+	alerting map[string]bool
+}
+
+// budgetEvent is a send outcome
+type budgetEvent struct {
+	at     time.Time
+	failed bool
+}
+
+// RecordSend records the outcome of a send
+func (b *ErrorBudget) RecordSend(err error) {
+	b.record(func(now time.Time) {
+		b.sends = append(b.sends, budgetEvent{at: now, failed: err != nil})
+	})
+}
+
+// RecordBounce records a bounce reported for an earlier send
+func (b *ErrorBudget) RecordBounce() {
+	b.record(func(now time.Time) {
+		b.bounces = append(b.bounces, now)
+	})
+}
+
+// Rates returns the failure and bounce rates over the current window
+func (b *ErrorBudget) Rates() (failureRate, bounceRate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(b.clock())
+	return b.rates()
+}
+
+// record applies an event and evaluates the thresholds
+func (b *ErrorBudget) record(apply func(now time.Time)) {
+	b.mu.Lock()
+	now := b.clock()
+	apply(now)
+	b.prune(now)
+
+	var alerts []BudgetAlert
+	if len(b.sends) >= b.MinSends && len(b.sends) > 0 {
+		failureRate, bounceRate := b.rates()
+		alerts = append(alerts, b.check(AlertFailureRate, failureRate, b.MaxFailureRate)...)
+		alerts = append(alerts, b.check(AlertBounceRate, bounceRate, b.MaxBounceRate)...)
+	}
+	onAlert := b.OnAlert
+	b.mu.Unlock()
+
+	if onAlert != nil {
+		for _, alert := range alerts {
+			onAlert(alert)
+		}
+	}
+}
+
+// check returns an alert when rate first exceeds threshold
+func (b *ErrorBudget) check(kind string, rate, threshold float64) []BudgetAlert {
+	if threshold <= 0 {
+		return nil
+	}
+	if b.alerting == nil {
+		b.alerting = make(map[string]bool)
+	}
+
+	exceeded := rate > threshold
+	if !exceeded || b.alerting[kind] {
+		b.alerting[kind] = exceeded
+		return nil
+	}
+	b.alerting[kind] = true
+	return []BudgetAlert{{Kind: kind, Rate: rate, Threshold: threshold, Sends: len(b.sends)}}
+}
+
+// rates computes the rates over the retained events
+func (b *ErrorBudget) rates() (failureRate, bounceRate float64) {
+	if len(b.sends) == 0 {
+		return 0, 0
+	}
+	failed := 0
+	for _, e := range b.sends {
+		if e.failed {
+			failed++
+		}
+	}
+	total := float64(len(b.sends))
+	return float64(failed) / total, float64(len(b.bounces)) / total
+}
+
+// prune drops events older than the window
+func (b *ErrorBudget) prune(now time.Time) {
+	cutoff := now.Add(-b.Window)
+	i := 0
+	for i < len(b.sends) && !b.sends[i].at.After(cutoff) {
+		i++
+	}
+	b.sends = b.sends[i:]
+
+	j := 0
+	for j < len(b.bounces) && !b.bounces[j].After(cutoff) {
+		j++
+	}
+	b.bounces = b.bounces[j:]
+}
+
+// clock returns the current time
+func (b *ErrorBudget) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// WithErrorBudget records every send's outcome in budget
+func WithErrorBudget(budget *ErrorBudget) Option {
+	return func(c *clientImpl) {
+		c.budget = budget
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestErrorBudgetAlerts(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var alerts []BudgetAlert
+	b := &ErrorBudget{
+		Window:         time.Minute,
+		MaxFailureRate: 0.2,
+		MaxBounceRate:  0.1,
+		MinSends:       5,
+		OnAlert:        func(a BudgetAlert) { alerts = append(alerts, a) },
+		now:            func() time.Time { return now },
+	}
+
+	failure := errors.New("failed")
+	for i := 0; i < 4; i++ {
+		b.RecordSend(nil)
+	}
+	b.RecordSend(failure)
+	if len(alerts) != 0 {
+		t.Fatalf("alerts at 20%% failure rate = %+v, want none", alerts)
+	}
+
+	b.RecordSend(failure)
+	if len(alerts) != 1 || alerts[0].Kind != AlertFailureRate || alerts[0].Sends != 6 {
+		t.Fatalf("alerts = %+v, want one failure rate alert", alerts)
+	}
+
+	b.RecordSend(failure)
+	if len(alerts) != 1 {
+		t.Errorf("alert repeated while still over budget: %+v", alerts)
+	}
+
+	b.RecordBounce()
+	if len(alerts) != 2 || alerts[1].Kind != AlertBounceRate {
+		t.Errorf("alerts = %+v, want a bounce rate alert", alerts)
+	}
+
+	// Once the window slides past the failures the rates recover
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 5; i++ {
+		b.RecordSend(nil)
+	}
+	if failureRate, bounceRate := b.Rates(); failureRate != 0 || bounceRate != 0 {
+		t.Errorf("Rates() = %v, %v, want 0, 0", failureRate, bounceRate)
+	}
+	b.RecordSend(failure)
+	b.RecordSend(failure)
+	if len(alerts) != 3 || alerts[2].Kind != AlertFailureRate {
+		t.Errorf("alerts = %+v, want a new failure rate alert after recovery", alerts)
+	}
+}
+
+func TestErrorBudgetRecordsSends(t *testing.T) {
+	fail := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": "parameter-error", "message": "bad"}`))
+			return
+		}
+		w.Write([]byte(`{"message_id": "12373", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	budget := &ErrorBudget{Window: time.Minute}
+	client, err := NewClient(ts.URL, "test-key", WithErrorBudget(budget))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	client.SendMessage(context.Background(), retryTestMessage())
+	fail = false
+	client.SendMessage(context.Background(), retryTestMessage())
+	// Validation failures never reach the server and are not counted
+	client.SendMessage(context.Background(), &types.Message{})
+
+	if failureRate, _ := budget.Rates(); failureRate != 0.5 {
+		t.Errorf("failure rate = %v, want 0.5", failureRate)
+	}
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/sachin-duhan/postal-go/common/privacy"
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/common/validation"
+)
+
+// Explanation describes the request SendMessage would make for a message
+type Explanation struct {
+	Method string
+	URL    string
+	// Headers are the request headers with credentials redacted
+	Headers http.Header
+	// Payload is the exact JSON body, after profile and tenant defaults and
+	// field aliases are applied. With Config.ForceRaw, Method, URL, Headers,
+	// Payload and Middleware are empty for messages that fail validation.
+	Payload json.RawMessage
+	// Middleware names the middleware the request passes through, outermost first
+	Middleware []string
+	// Problems lists the validation failures that would stop the send
+	Problems []string
+	// Warnings lists non-fatal findings, such as DMARC alignment
+	Warnings []string
+}
+
+// Valid returns true if the message passes client-side validation
+func (e *Explanation) Valid() bool {
+	return len(e.Problems) == 0
+}
+
+// Explain implements Client
+func (c *clientImpl) Explain(ctx context.Context, msg *types.Message, opts ...SendOption) (*Explanation, error) {
+	o := collectSendOptions(opts)
+	msg, err := c.profileDefaults(msg, o.profile)
+	if err != nil {
+		return nil, err
+	}
+	msg = c.messageDefaults(msg)
+	if o.correlationID != "" {
+		withID := *msg
+		withID.Headers = o.correlationHeaders(msg.Headers)
+		msg = &withID
+	}
+
+	exp := &Explanation{}
+	var verr *types.ValidationError
+	if err := validation.ValidateMessage(msg); errors.As(err, &verr) {
+		exp.Problems = verr.Problems
+	} else if err != nil {
+		exp.Problems = []string{err.Error()}
+	}
+	if warning := validation.AlignmentWarning(msg.From, c.verifiedDomains()); warning != "" {
+		exp.Warnings = append(exp.Warnings, warning)
+	}
+	for _, att := range msg.Attachments {
+		if att.Name != "" {
+			exp.Warnings = append(exp.Warnings, validation.AttachmentNameWarnings(att.Name)...)
+		}
+	}
+
+	if c.config.PrivacyMode {
+		ids := messageIdentifiers(msg)
+		for i, p := range exp.Problems {
+			exp.Problems[i] = privacy.Redact(p, ids...)
+		}
+		for i, w := range exp.Warnings {
+			exp.Warnings[i] = privacy.Redact(w, ids...)
+		}
+	}
+
+	// Rendering MIME needs a valid message, so an invalid one can only be
+	// explained when it would be sent as structured JSON
+	if c.config.ForceRaw && !exp.Valid() {
+		return exp, nil
+	}
+	req, err := c.messageRequest(msg, o)
+	if err != nil {
+		return nil, err
+	}
+	c.applyContextHeaders(ctx, req)
+	if c.tenant != nil {
+		c.tenantRequest(req)
+	}
+
+	httpReq, err := c.transport.NewHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	exp.Method = httpReq.Method
+	exp.URL = httpReq.URL.Redacted()
+	exp.Headers = redactHeaders(httpReq.Header)
+	exp.Payload = payload
+	for _, m := range c.transport.MiddlewareChain(req) {
+		exp.Middleware = append(exp.Middleware, funcName(m))
+	}
+	return exp, nil
+}
+
+// sensitiveHeaderWords mark headers whose values are redacted in explanations
+var sensitiveHeaderWords = []string{"key", "auth", "token", "secret", "signature", "cookie"}
+
+// redactHeaders returns a copy of h with credential values replaced
+func redactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for name, values := range redacted {
+		lower := strings.ToLower(name)
+		for _, word := range sensitiveHeaderWords {
+			if strings.Contains(lower, word) {
+				for i := range values {
+					values[i] = "REDACTED"
+				}
+				break
+			}
+		}
+	}
+	return redacted
+}
+
+// closureSuffix matches the suffix Go gives anonymous functions
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcName returns the name of the function that built a middleware
+func funcName(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	return closureSuffix.ReplaceAllString(f.Name(), "")
+}
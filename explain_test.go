@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestExplain(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "secret-key",
+		WithVerifiedDomains("example.org"),
+		WithMiddleware(func(next http.RoundTripper) http.RoundTripper { return next }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	exp, err := c.Explain(context.Background(), compatTestMessage(), WithRequestMutator(func(r *http.Request) {
+		r.Header.Set("X-Trace", "abc")
+	}))
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Explain() made %d requests, want 0", requests)
+	}
+
+	if exp.Method != http.MethodPost || exp.URL != ts.URL+"/api/v1/send/message" {
+		t.Errorf("request = %s %s", exp.Method, exp.URL)
+	}
+	if got := exp.Headers.Get("X-Server-API-Key"); got != "REDACTED" {
+		t.Errorf("API key header = %q, want REDACTED", got)
+	}
+	if got := exp.Headers.Get("X-Trace"); got != "abc" {
+		t.Errorf("mutated header = %q, want abc", got)
+	}
+
+	var payload types.Message
+	if err := json.Unmarshal(exp.Payload, &payload); err != nil {
+		t.Fatalf("payload is not a message: %v", err)
+	}
+	if payload.Subject != compatTestMessage().Subject {
+		t.Errorf("payload subject = %q", payload.Subject)
+	}
+
+	if len(exp.Middleware) != 1 || !strings.HasSuffix(exp.Middleware[0], "TestExplain") {
+		t.Errorf("middleware = %v, want one from TestExplain", exp.Middleware)
+	}
+	if !exp.Valid() {
+		t.Errorf("problems = %v, want none", exp.Problems)
+	}
+	if len(exp.Warnings) != 1 {
+		t.Errorf("warnings = %v, want an alignment warning", exp.Warnings)
+	}
+}
+
+func TestExplainInvalidMessage(t *testing.T) {
+	c, err := NewClient("https://postal.example.com", "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	exp, err := c.Explain(context.Background(), &types.Message{
+		To:          []string{"not-an-address"},
+		Attachments: []types.Attachment{{Name: "run.exe", ContentType: "application/octet-stream", Data: "AA=="}},
+	})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if exp.Valid() {
+		t.Fatal("Valid() = true for an invalid message")
+	}
+	if !contains(strings.Join(exp.Problems, "\n"), "invalid recipient email") {
+		t.Errorf("problems = %v, want an invalid recipient", exp.Problems)
+	}
+	if len(exp.Warnings) != 1 || !contains(exp.Warnings[0], "run.exe") {
+		t.Errorf("warnings = %v, want one for run.exe", exp.Warnings)
+	}
+	if len(exp.Payload) == 0 {
+		t.Error("invalid message has no payload")
+	}
+}
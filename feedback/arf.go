@@ -0,0 +1,136 @@
+// Package feedback handles complaint (feedback loop) reports so that
+// complained addresses can be suppressed automatically
+package feedback
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ErrNotFeedbackReport is returned when a message is not an ARF report
+var ErrNotFeedbackReport = errors.New("feedback: not a feedback report")
+
+// Feedback types defined by RFC 5965
+const (
+	TypeAbuse       = "abuse"
+	TypeAuthFailure = "auth-failure"
+	TypeFraud       = "fraud"
+	TypeNotSpam     = "not-spam"
+	TypeOther       = "other"
+	TypeVirus       = "virus"
+)
+
+// Complaint is a recipient's complaint about a message
+type Complaint struct {
+	// Recipient is the address that complained
+	Recipient string
+	// FeedbackType is the ARF feedback type, e.g. TypeAbuse
+	FeedbackType string
+	// UserAgent identifies the software that generated the report
+	UserAgent string
+	// OriginalMailFrom is the envelope sender of the complained message
+	OriginalMailFrom string
+	// OriginalMessageID is the Message-ID of the complained message
+	OriginalMessageID string
+	// ReportedDomain is the domain the report is about
+	ReportedDomain string
+	// SourceIP is the IP address the complained message was sent from
+	SourceIP string
+	// ArrivalDate is when the complained message was received
+	ArrivalDate time.Time
+}
+
+// ParseARF parses an Abuse Reporting Format (RFC 5965) report
+func ParseARF(r io.Reader) (*Complaint, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("feedback: failed to parse message: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || !strings.EqualFold(params["report-type"], "feedback-report") {
+		return nil, ErrNotFeedbackReport
+	}
+
+	var (
+		complaint Complaint
+		found     bool
+	)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("feedback: failed to read report: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/feedback-report":
+			if err := parseReportFields(part, &complaint); err != nil {
+				return nil, err
+			}
+			found = true
+		case "message/rfc822", "text/rfc822-headers":
+			parseOriginalHeaders(part, &complaint)
+		}
+	}
+
+	if !found {
+		return nil, ErrNotFeedbackReport
+	}
+	if complaint.Recipient == "" {
+		return nil, fmt.Errorf("feedback: report does not identify the recipient")
+	}
+	return &complaint, nil
+}
+
+// parseReportFields reads the machine-readable feedback report part
+func parseReportFields(r io.Reader, c *Complaint) error {
+	header, err := textproto.NewReader(bufio.NewReader(r)).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("feedback: invalid feedback report: %w", err)
+	}
+
+	c.FeedbackType = strings.ToLower(header.Get("Feedback-Type"))
+	c.UserAgent = header.Get("User-Agent")
+	c.OriginalMailFrom = trimAddress(header.Get("Original-Mail-From"))
+	c.Recipient = trimAddress(header.Get("Original-Rcpt-To"))
+	c.ReportedDomain = header.Get("Reported-Domain")
+	c.SourceIP = header.Get("Source-Ip")
+	if date, err := mail.ParseDate(header.Get("Arrival-Date")); err == nil {
+		c.ArrivalDate = date
+	}
+	return nil
+}
+
+// parseOriginalHeaders fills gaps from the headers of the complained message
+func parseOriginalHeaders(r io.Reader, c *Complaint) {
+	original, err := mail.ReadMessage(r)
+	if err != nil {
+		return
+	}
+	if c.OriginalMessageID == "" {
+		c.OriginalMessageID = original.Header.Get("Message-Id")
+	}
+	if c.Recipient == "" {
+		if to, err := original.Header.AddressList("To"); err == nil && len(to) == 1 {
+			c.Recipient = to[0].Address
+		}
+	}
+}
+
+// trimAddress strips angle brackets from an address field
+func trimAddress(addr string) string {
+	return strings.Trim(strings.TrimSpace(addr), "<>")
+}
@@ -0,0 +1,95 @@
+package feedback
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const arfReport = "From: fbl@isp.example\r\n" +
+	"To: abuse@example.com\r\n" +
+	"Subject: FW: Newsletter\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=feedback-report; boundary=\"part\"\r\n" +
+	"\r\n" +
+	"--part\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"This is an email abuse report.\r\n" +
+	"--part\r\n" +
+	"Content-Type: message/feedback-report\r\n" +
+	"\r\n" +
+	"Feedback-Type: abuse\r\n" +
+	"User-Agent: ISP-FBL/1.0\r\n" +
+	"Version: 1\r\n" +
+	"Original-Mail-From: <bounces@example.com>\r\n" +
+	"Original-Rcpt-To: <user@isp.example>\r\n" +
+	"Arrival-Date: Thu, 8 Mar 2005 14:00:00 +0000\r\n" +
+	"Reported-Domain: example.com\r\n" +
+	"Source-IP: 192.0.2.1\r\n" +
+	"\r\n" +
+	"--part\r\n" +
+	"Content-Type: message/rfc822\r\n" +
+	"\r\n" +
+	"From: news@example.com\r\n" +
+	"To: user@isp.example\r\n" +
+	"Message-ID: <orig@example.com>\r\n" +
+	"Subject: Newsletter\r\n" +
+	"\r\n" +
+	"Hello\r\n" +
+	"--part--\r\n"
+
+func TestParseARF(t *testing.T) {
+	c, err := ParseARF(strings.NewReader(arfReport))
+	if err != nil {
+		t.Fatalf("ParseARF() error = %v", err)
+	}
+
+	want := Complaint{
+		Recipient:         "user@isp.example",
+		FeedbackType:      TypeAbuse,
+		UserAgent:         "ISP-FBL/1.0",
+		OriginalMailFrom:  "bounces@example.com",
+		OriginalMessageID: "<orig@example.com>",
+		ReportedDomain:    "example.com",
+		SourceIP:          "192.0.2.1",
+		ArrivalDate:       time.Date(2005, 3, 8, 14, 0, 0, 0, time.UTC),
+	}
+	if !c.ArrivalDate.Equal(want.ArrivalDate) {
+		t.Errorf("ArrivalDate = %v, want %v", c.ArrivalDate, want.ArrivalDate)
+	}
+	c.ArrivalDate = want.ArrivalDate
+	if *c != want {
+		t.Errorf("ParseARF() = %+v, want %+v", *c, want)
+	}
+}
+
+func TestParseARFRecipientFromOriginal(t *testing.T) {
+	report := strings.Replace(arfReport, "Original-Rcpt-To: <user@isp.example>\r\n", "", 1)
+	c, err := ParseARF(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("ParseARF() error = %v", err)
+	}
+	if c.Recipient != "user@isp.example" {
+		t.Errorf("Recipient = %q, want the original To address", c.Recipient)
+	}
+}
+
+func TestParseARFNotReport(t *testing.T) {
+	tests := []struct {
+		name string
+		mail string
+	}{
+		{name: "plain message", mail: "Subject: hi\r\n\r\nbody"},
+		{name: "delivery report", mail: strings.Replace(arfReport, "report-type=feedback-report", "report-type=delivery-status", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseARF(strings.NewReader(tt.mail)); !errors.Is(err, ErrNotFeedbackReport) {
+				t.Errorf("ParseARF() error = %v, want ErrNotFeedbackReport", err)
+			}
+		})
+	}
+}
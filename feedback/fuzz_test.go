@@ -0,0 +1,17 @@
+package feedback
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParseARF(f *testing.F) {
+	f.Add(arfReport)
+	f.Add("")
+	f.Add("Content-Type: multipart/report; report-type=feedback-report; boundary=x\r\n\r\n--x\r\n\r\n--x--\r\n")
+	f.Add("Content-Type: multipart/report; report-type=feedback-report\r\n\r\n")
+
+	f.Fuzz(func(t *testing.T, report string) {
+		ParseARF(strings.NewReader(report))
+	})
+}
@@ -0,0 +1,111 @@
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/sachin-duhan/postal-go/inbound"
+)
+
+// Suppressor stops further mail to a complained address
+type Suppressor interface {
+	Suppress(ctx context.Context, c *Complaint) error
+}
+
+// SuppressorFunc adapts a function to the Suppressor interface
+type SuppressorFunc func(ctx context.Context, c *Complaint) error
+
+// Suppress implements Suppressor
+func (f SuppressorFunc) Suppress(ctx context.Context, c *Complaint) error {
+	return f(ctx, c)
+}
+
+// Collector receives complaint metrics
+type Collector interface {
+	IncComplaint(feedbackType, domain string)
+}
+
+// Processor suppresses complained addresses and records complaint metrics
+type Processor struct {
+	Suppressor Suppressor
+	Collector  Collector
+	// OnSkipped, when set, is called for inbound mail that HandleInbound
+	// acknowledges without processing: mail that is not an ARF report
+	// (err wraps ErrNotFeedbackReport) or could not be decoded or parsed
+	OnSkipped func(msg *inbound.RawMessage, err error)
+}
+
+// Process handles a parsed complaint. Complaints with feedback type
+// "not-spam" are counted but not suppressed.
+func (p *Processor) Process(ctx context.Context, c *Complaint) error {
+	if p.Collector != nil {
+		p.Collector.IncComplaint(c.FeedbackType, recipientDomain(c.Recipient))
+	}
+	if p.Suppressor == nil || c.FeedbackType == TypeNotSpam {
+		return nil
+	}
+	return p.Suppressor.Suppress(ctx, c)
+}
+
+// HandleInbound parses an ARF report delivered to a raw format inbound
+// endpoint and processes it. It can be passed to inbound.RawHandler.
+// Mail that is not a report or cannot be parsed is acknowledged and passed
+// to OnSkipped, since Postal would otherwise redeliver it forever; only
+// failures to process a report are returned.
+func (p *Processor) HandleInbound(ctx context.Context, msg *inbound.RawMessage) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		p.skip(msg, err)
+		return nil
+	}
+	c, err := ParseARF(bytes.NewReader(data))
+	if err != nil {
+		p.skip(msg, err)
+		return nil
+	}
+	return p.Process(ctx, c)
+}
+
+// skip reports mail HandleInbound acknowledges without processing
+func (p *Processor) skip(msg *inbound.RawMessage, err error) {
+	if p.OnSkipped != nil {
+		p.OnSkipped(msg, err)
+	}
+}
+
+// SuppressionList is an in-memory Suppressor
+type SuppressionList struct {
+	mu        sync.RWMutex
+	addresses map[string]*Complaint
+}
+
+// NewSuppressionList creates an empty SuppressionList
+func NewSuppressionList() *SuppressionList {
+	return &SuppressionList{addresses: make(map[string]*Complaint)}
+}
+
+// Suppress implements Suppressor
+func (l *SuppressionList) Suppress(ctx context.Context, c *Complaint) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.addresses[strings.ToLower(c.Recipient)] = c
+	return nil
+}
+
+// Contains returns true if the address has been suppressed
+func (l *SuppressionList) Contains(address string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.addresses[strings.ToLower(address)]
+	return ok
+}
+
+// recipientDomain returns the lower-cased domain of an address
+func recipientDomain(addr string) string {
+	if i := strings.LastIndex(addr, "@"); i >= 0 {
+		return strings.ToLower(addr[i+1:])
+	}
+	return ""
+}
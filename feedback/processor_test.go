@@ -0,0 +1,94 @@
+package feedback
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/inbound"
+)
+
+type countingCollector map[string]int
+
+func (c countingCollector) IncComplaint(feedbackType, domain string) {
+	c[feedbackType+"@"+domain]++
+}
+
+func TestProcessor(t *testing.T) {
+	list := NewSuppressionList()
+	collector := countingCollector{}
+	p := &Processor{Suppressor: list, Collector: collector}
+
+	ctx := context.Background()
+	if err := p.Process(ctx, &Complaint{Recipient: "User@ISP.example", FeedbackType: TypeAbuse}); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if err := p.Process(ctx, &Complaint{Recipient: "other@isp.example", FeedbackType: TypeNotSpam}); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if !list.Contains("user@isp.example") {
+		t.Error("expected abuse complaint to be suppressed")
+	}
+	if list.Contains("other@isp.example") {
+		t.Error("not-spam reports should not be suppressed")
+	}
+	if collector["abuse@isp.example"] != 1 || collector["not-spam@isp.example"] != 1 {
+		t.Errorf("collector = %v, want one abuse and one not-spam complaint", collector)
+	}
+}
+
+func TestProcessorHandleInbound(t *testing.T) {
+	var suppressed string
+	p := &Processor{Suppressor: SuppressorFunc(func(ctx context.Context, c *Complaint) error {
+		suppressed = c.Recipient
+		return nil
+	})}
+
+	msg := &inbound.RawMessage{Message: base64.StdEncoding.EncodeToString([]byte(arfReport)), Base64: true}
+	if err := p.HandleInbound(context.Background(), msg); err != nil {
+		t.Fatalf("HandleInbound() error = %v", err)
+	}
+	if suppressed != "user@isp.example" {
+		t.Errorf("suppressed %q, want %q", suppressed, "user@isp.example")
+	}
+}
+
+func TestProcessorHandleInboundSkips(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *inbound.RawMessage
+		is   error
+	}{
+		{"not a report", &inbound.RawMessage{Message: base64.StdEncoding.EncodeToString([]byte("From: a@example.com\r\nSubject: Hi\r\n\r\nHello")), Base64: true}, ErrNotFeedbackReport},
+		{"bad base64", &inbound.RawMessage{Message: "%%%", Base64: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var skipped error
+			p := &Processor{
+				Suppressor: SuppressorFunc(func(ctx context.Context, c *Complaint) error {
+					t.Error("Suppress() called for skipped mail")
+					return nil
+				}),
+				OnSkipped: func(msg *inbound.RawMessage, err error) { skipped = err },
+			}
+			if err := p.HandleInbound(context.Background(), tt.msg); err != nil {
+				t.Fatalf("HandleInbound() error = %v, want nil so Postal does not redeliver", err)
+			}
+			if skipped == nil || (tt.is != nil && !errors.Is(skipped, tt.is)) {
+				t.Errorf("OnSkipped error = %v, want %v", skipped, tt.is)
+			}
+		})
+	}
+
+	// Processing failures are still returned so the mail is redelivered
+	p := &Processor{Suppressor: SuppressorFunc(func(ctx context.Context, c *Complaint) error {
+		return errors.New("store unavailable")
+	})}
+	msg := &inbound.RawMessage{Message: base64.StdEncoding.EncodeToString([]byte(arfReport)), Base64: true}
+	if err := p.HandleInbound(context.Background(), msg); err == nil {
+		t.Error("HandleInbound() error = nil, want the suppressor's error")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build postal_fips
+
+package client
+
+// Builds with the postal_fips tag restrict TLS to FIPS-approved settings
+// and reject HMAC secrets shorter than 112 bits. The tag requires a Go
+// toolchain built with GOEXPERIMENT=boringcrypto, which provides this
+// package; other toolchains fail to build rather than silently using
+// unvalidated primitives.
+import _ "crypto/tls/fipsonly"
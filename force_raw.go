@@ -0,0 +1,65 @@
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+	"github.com/sachin-duhan/postal-go/rawmail"
+)
+
+// tagHeader is the header Postal reads a message tag from on send/raw,
+// which has no tag field
+const tagHeader = "X-Postal-Tag"
+
+// messageRequest returns the request SendMessage makes for a validated
+// message: send/message, or send/raw with the message rendered as MIME
+// when Config.ForceRaw is set
+func (c *clientImpl) messageRequest(msg *types.Message, o *sendOptions) (*transport.Request, error) {
+	if !c.config.ForceRaw {
+		return c.newRequest(http.MethodPost, "send/message", msg, o), nil
+	}
+	raw, err := messageToRaw(msg)
+	if err != nil {
+		return nil, err
+	}
+	return c.newRequest(http.MethodPost, "send/raw", encodeRaw(raw), o), nil
+}
+
+// messageToRaw renders a message as MIME for send/raw
+func messageToRaw(msg *types.Message) (*types.RawMessage, error) {
+	b := rawmail.NewRawBuilder().
+		WithFrom(msg.From).
+		WithTo(msg.To...).
+		WithCC(msg.CC...).
+		WithBCC(msg.BCC...).
+		WithReplyTo(msg.ReplyTo).
+		WithSubject(msg.Subject).
+		WithText(msg.Body).
+		WithHTML(msg.HTMLBody)
+	if msg.Sender != "" {
+		b.WithHeader("Sender", msg.Sender)
+	}
+	if msg.Tag != "" {
+		b.WithHeader(tagHeader, msg.Tag)
+	}
+	names := make([]string, 0, len(msg.Headers))
+	for name := range msg.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WithHeader(name, msg.Headers[name])
+	}
+	for _, att := range msg.Attachments {
+		data, err := base64.StdEncoding.DecodeString(att.Data)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %q: invalid base64 data: %w", att.Name, err)
+		}
+		b.WithAttachment(att.Name, att.ContentType, data)
+	}
+	return b.Build()
+}
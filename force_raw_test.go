@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestForceRaw(t *testing.T) {
+	var path string
+	var raw types.RawMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&raw)
+		mail, _ := base64.StdEncoding.DecodeString(raw.Mail)
+		raw.Mail = string(mail)
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "raw-1", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key", WithForceRaw(true))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg := &types.Message{
+		To:       []string{"to@example.com"},
+		BCC:      []string{"bcc@example.com"},
+		From:     "sender@example.com",
+		Subject:  "Forced raw",
+		Tag:      "welcome",
+		Body:     "Hello",
+		HTMLBody: "<p>Hello</p>",
+		Headers:  map[string]string{"X-Campaign": "spring"},
+		Attachments: []types.Attachment{
+			{Name: "a.txt", ContentType: "text/plain", Data: base64.StdEncoding.EncodeToString([]byte("attached"))},
+		},
+	}
+	result, err := c.SendMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if result.MessageID != "raw-1" {
+		t.Errorf("MessageID = %q", result.MessageID)
+	}
+
+	if path != "/api/v1/send/raw" {
+		t.Fatalf("path = %s, want /api/v1/send/raw", path)
+	}
+	if raw.From != "sender@example.com" || strings.Join(raw.To, ",") != "to@example.com,bcc@example.com" {
+		t.Errorf("envelope = %s -> %v", raw.From, raw.To)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(raw.Mail))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	for name, want := range map[string]string{"Subject": "Forced raw", "X-Postal-Tag": "welcome", "X-Campaign": "spring"} {
+		if got := parsed.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	body, _ := io.ReadAll(parsed.Body)
+	if !strings.Contains(string(body), `filename="a.txt"`) || !strings.Contains(string(body), "attached") {
+		t.Error("attachment missing from MIME body")
+	}
+}
+
+func TestForceRawInvalidAttachment(t *testing.T) {
+	c, err := NewClient("https://postal.example.com", "test-key", WithForceRaw(true))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	msg := compatTestMessage()
+	msg.Attachments = []types.Attachment{{Name: "a.txt", ContentType: "text/plain", Data: "not base64!"}}
+
+	if _, err := c.SendMessage(context.Background(), msg); err == nil || !strings.Contains(err.Error(), "invalid base64") {
+		t.Errorf("SendMessage() error = %v, want invalid base64", err)
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// DefaultIdempotencyHeader is the header idempotency keys are sent in when
+// Config.IdempotencyHeader is empty
+const DefaultIdempotencyHeader = "X-Idempotency-Key"
+
+// WithIdempotencyKeys sends a generated key in header (DefaultIdempotencyHeader
+// when empty) with every send. The key is the same for all retries of one
+// send, so a proxy or gateway in front of Postal that deduplicates on it
+// delivers a message once even if a timed out attempt reached the server.
+// Sends with a key are retried after any retryable failure; see
+// WithMaxRetries.
+func WithIdempotencyKeys(header string) Option {
+	return func(c *clientImpl) {
+		if header == "" {
+			header = DefaultIdempotencyHeader
+		}
+		c.config.IdempotencyHeader = http.CanonicalHeaderKey(header)
+	}
+}
+
+// WithIdempotencyKey sends key as this call's idempotency key instead of a
+// generated one, e.g. an ID from the caller's own database so that sends
+// repeated after a crash are deduplicated too
+func WithIdempotencyKey(key string) SendOption {
+	return func(o *sendOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// idempotencyHeader returns the canonical name of the header idempotency
+// keys are sent in
+func (c *clientImpl) idempotencyHeader() string {
+	if c.config.IdempotencyHeader != "" {
+		return http.CanonicalHeaderKey(c.config.IdempotencyHeader)
+	}
+	return DefaultIdempotencyHeader
+}
+
+// idempotencyKey returns the key req carries. Request header names are
+// canonicalized as they are set, so a context header configured in any case
+// is found too.
+func (c *clientImpl) idempotencyKey(req *transport.Request) string {
+	return req.Headers[c.idempotencyHeader()]
+}
+
+// applyIdempotencyKey sets the caller's idempotency key on a send request
+func (c *clientImpl) applyIdempotencyKey(req *transport.Request, o *sendOptions) {
+	if o.idempotencyKey != "" {
+		req.Headers = mergeHeaders(req.Headers, map[string]string{c.idempotencyHeader(): o.idempotencyKey})
+	}
+}
+
+// hasIdempotencyKey reports whether req carries an idempotency key, which
+// makes retrying a send safe
+func (c *clientImpl) hasIdempotencyKey(req *transport.Request) bool {
+	return c.idempotencyKey(req) != ""
+}
+
+// ensureIdempotencyKey generates a key for a send request without one when
+// keys are enabled. It runs once per send, before any retries.
+func (c *clientImpl) ensureIdempotencyKey(req *transport.Request) error {
+	if c.config.IdempotencyHeader == "" || c.idempotencyKey(req) != "" {
+		return nil
+	}
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	req.Headers = mergeHeaders(req.Headers, map[string]string{c.idempotencyHeader(): key})
+	return nil
+}
+
+// newIdempotencyKey returns a random (version 4) UUID
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
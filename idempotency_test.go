@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	fail := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get("X-Request-Key")+"|"+r.Header.Get(DefaultIdempotencyHeader))
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code": "server_error", "message": "Unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"message_id": "12356", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key", WithIdempotencyKeys("X-Request-Key"), WithRetryInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendMessage(context.Background(), compatTestMessage()); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	if _, err := client.SendRawMessage(context.Background(), rawTestMessage(), WithIdempotencyKey("order-42")); err != nil {
+		t.Fatalf("SendRawMessage() error = %v", err)
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\|$`)
+	if len(keys) != 4 || !uuid.MatchString(keys[0]) {
+		t.Fatalf("keys = %q, want a generated UUID on 4 requests", keys)
+	}
+	if keys[1] != keys[0] {
+		t.Errorf("retry sent key %q, want the first attempt's %q", keys[1], keys[0])
+	}
+	if keys[2] == keys[0] {
+		t.Errorf("second send reused key %q", keys[2])
+	}
+	if keys[3] != "order-42|" {
+		t.Errorf("explicit key sent as %q, want order-42 in X-Request-Key", keys[3])
+	}
+
+	// Without WithIdempotencyKeys only explicit keys are sent, in the default header
+	keys = nil
+	plain, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	plain.SendMessage(context.Background(), compatTestMessage())
+	plain.SendMessage(context.Background(), compatTestMessage(), WithIdempotencyKey("order-43"))
+	if len(keys) != 2 || keys[0] != "|" || keys[1] != "|order-43" {
+		t.Errorf("keys = %q, want none and then order-43 in %s", keys, DefaultIdempotencyHeader)
+	}
+}
+
+func TestIdempotencyHeaderCase(t *testing.T) {
+	var mu sync.Mutex
+	var keys [][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Values("X-Request-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code": "server_error", "message": "Internal error"}`))
+			return
+		}
+		w.Write([]byte(`{"message_id": "12356", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.RetryInterval = time.Millisecond
+	cfg.IdempotencyHeader = "x-request-key"
+	cfg.ContextHeaders = []ContextHeader{{Key: ctxKey("order"), Header: "X-REQUEST-KEY"}}
+	client.WithConfig(cfg)
+
+	// The key from the context header is found despite the different case,
+	// so no second key is generated and the 500 is safe to retry
+	ctx := context.WithValue(context.Background(), ctxKey("order"), "order-44")
+	if _, err := client.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("requests = %d, want the 500 retried once", len(keys))
+	}
+	for i, got := range keys {
+		if len(got) != 1 || got[0] != "order-44" {
+			t.Errorf("attempt %d sent keys %q, want only order-44", i+1, got)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func FuzzWebhookParse(f *testing.F) {
+	f.Add([]byte(`{"id": 1, "rcpt_to": "a@example.com", "from": "b@example.com", "subject": "Hi", "plain_body": "x", "attachments": [{"filename": "a.txt", "data": "aGk="}]}`))
+	f.Add([]byte(`{"id": 1, "message": "RnJvbTogYUBleGFtcGxlLmNvbQ0KDQpoaQ==", "base64": true}`))
+	f.Add([]byte(`{"timestamp": 1e400}`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if msg, err := Parse(data); err == nil {
+			msg.Time()
+			msg.IsSpam()
+			msg.IsAutoSubmitted()
+			for i := range msg.Attachments {
+				msg.Attachments[i].Decode()
+			}
+			Reply(msg, ReplyOptions{From: "me@example.com", Body: "thanks"})
+			Forward(msg, "me@example.com", []string{"you@example.com"}, "fyi")
+		}
+		if raw, err := ParseRaw(data); err == nil {
+			raw.Decode()
+			raw.AuthResults()
+		}
+	})
+}
+
+func FuzzParseEML(f *testing.F) {
+	f.Add("From: a@example.com\r\nAuthentication-Results: mx; dkim=pass; spf=fail; dmarc=none\r\n\r\nBody")
+	f.Add("")
+	f.Add("Authentication-Results: ;;= dkim= ;spf\r\n\r\n")
+	f.Add("From: <\r\n To: \r\n\r\n")
+
+	f.Fuzz(func(t *testing.T, eml string) {
+		for _, raw := range []*RawMessage{
+			{Message: eml},
+			{Message: base64.StdEncoding.EncodeToString([]byte(eml)), Base64: true},
+			{Message: eml, Base64: true},
+		} {
+			raw.AuthResults()
+		}
+		ParseAuthResults(eml)
+	})
+}
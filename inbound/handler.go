@@ -0,0 +1,72 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MaxPayloadSize is the largest request body the handlers accept
+const MaxPayloadSize = 50 << 20
+
+// HandlerFunc processes a parsed inbound message. Returning an error makes
+// the handler respond with 500 so that Postal retries the delivery.
+type HandlerFunc func(ctx context.Context, msg *Message) error
+
+// RawHandlerFunc processes a parsed raw format inbound message
+type RawHandlerFunc func(ctx context.Context, msg *RawMessage) error
+
+// Handler returns an http.Handler for an endpoint using the hash format
+func Handler(fn HandlerFunc) http.Handler {
+	return handler(Parse, fn)
+}
+
+// RawHandler returns an http.Handler for an endpoint using the raw format
+func RawHandler(fn RawHandlerFunc) http.Handler {
+	return handler(ParseRaw, fn)
+}
+
+// handler reads and parses a payload, then passes it to fn
+func handler[T any](parse func([]byte) (*T, error), fn func(context.Context, *T) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := readPayload(w, r)
+		if !ok {
+			return
+		}
+
+		msg, err := parse(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := fn(r.Context(), msg); err != nil {
+			http.Error(w, "failed to process message", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// readPayload reads the body of a POST request, responding with an error
+// and returning false if it is not one or cannot be read
+func readPayload(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxPayloadSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
@@ -0,0 +1,84 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		handlerErr error
+		wantStatus int
+		wantCalled bool
+	}{
+		{name: "valid payload", method: http.MethodPost, body: hashPayload, wantStatus: http.StatusOK, wantCalled: true},
+		{name: "handler error", method: http.MethodPost, body: hashPayload, handlerErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCalled: true},
+		{name: "invalid payload", method: http.MethodPost, body: "{", wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *Message
+			h := Handler(func(ctx context.Context, msg *Message) error {
+				got = msg
+				return tt.handlerErr
+			})
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, "/inbound", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if (got != nil) != tt.wantCalled {
+				t.Fatalf("handler called = %v, want %v", got != nil, tt.wantCalled)
+			}
+			if got != nil && got.Subject != "Help needed" {
+				t.Errorf("Subject = %q, want %q", got.Subject, "Help needed")
+			}
+		})
+	}
+}
+
+func TestRawHandler(t *testing.T) {
+	var got *RawMessage
+	h := RawHandler(func(ctx context.Context, msg *RawMessage) error {
+		got = msg
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	body := `{"id": 1, "rcpt_to": "support@example.com", "message": "U3ViamVjdDogaGkNCg0KYm9keQ==", "base64": true}`
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	msg, err := got.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if subject := msg.Header.Get("Subject"); subject != "hi" {
+		t.Errorf("Subject = %q, want %q", subject, "hi")
+	}
+}
+
+func TestHandlerPayloadTooLarge(t *testing.T) {
+	h := Handler(func(ctx context.Context, msg *Message) error { return nil })
+
+	rec := httptest.NewRecorder()
+	body := strings.NewReader(`{"subject": "` + strings.Repeat("a", MaxPayloadSize) + `"}`)
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inbound", body))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
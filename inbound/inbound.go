@@ -0,0 +1,201 @@
+// Package inbound parses mail that Postal delivers to HTTP endpoints
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Spam statuses reported by Postal
+const (
+	SpamStatusNotSpam    = "NotSpam"
+	SpamStatusSpam       = "Spam"
+	SpamStatusNotChecked = "NotChecked"
+)
+
+// Message is an inbound message delivered in Postal's default (hash) format
+type Message struct {
+	ID                 int          `json:"id"`
+	RcptTo             string       `json:"rcpt_to"`
+	MailFrom           string       `json:"mail_from"`
+	Token              string       `json:"token"`
+	Subject            string       `json:"subject"`
+	MessageID          string       `json:"message_id"`
+	Timestamp          float64      `json:"timestamp"`
+	Size               string       `json:"size"`
+	SpamStatus         string       `json:"spam_status"`
+	SpamScore          float64      `json:"spam_score,omitempty"`
+	Bounce             bool         `json:"bounce"`
+	ReceivedWithSSL    bool         `json:"received_with_ssl"`
+	To                 string       `json:"to"`
+	CC                 string       `json:"cc"`
+	From               string       `json:"from"`
+	Date               string       `json:"date"`
+	InReplyTo          string       `json:"in_reply_to"`
+	References         string       `json:"references"`
+	ReplyTo            string       `json:"reply_to"`
+	AutoSubmitted      string       `json:"auto_submitted"`
+	PlainBody          string       `json:"plain_body"`
+	HTMLBody           string       `json:"html_body"`
+	AttachmentQuantity int          `json:"attachment_quantity"`
+	Attachments        []Attachment `json:"attachments"`
+}
+
+// Attachment is a file attached to an inbound message
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Data        string `json:"data"` // Base64 encoded
+}
+
+// RawMessage is an inbound message delivered in Postal's raw format
+type RawMessage struct {
+	ID       int    `json:"id"`
+	RcptTo   string `json:"rcpt_to"`
+	MailFrom string `json:"mail_from"`
+	Token    string `json:"token"`
+	Message  string `json:"message"`
+	Base64   bool   `json:"base64"`
+	Size     int    `json:"size"`
+}
+
+// AuthResults holds the DKIM, SPF and DMARC verdicts from an
+// Authentication-Results header, e.g. "pass" or "fail"
+type AuthResults struct {
+	DKIM  string
+	SPF   string
+	DMARC string
+}
+
+// Parse decodes a hash format payload
+func Parse(data []byte) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("invalid inbound payload: %w", err)
+	}
+	return &msg, nil
+}
+
+// ParseRaw decodes a raw format payload
+func ParseRaw(data []byte) (*RawMessage, error) {
+	var msg RawMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("invalid inbound payload: %w", err)
+	}
+	if msg.Message == "" {
+		return nil, fmt.Errorf("invalid inbound payload: message is empty")
+	}
+	return &msg, nil
+}
+
+// Time returns the time Postal received the message
+func (m *Message) Time() time.Time {
+	sec, frac := math.Modf(m.Timestamp)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// IsSpam returns true if Postal classified the message as spam
+func (m *Message) IsSpam() bool {
+	return m.SpamStatus == SpamStatusSpam
+}
+
+// Header returns the named header from the fields the hash format carries:
+// From, To, Cc, Reply-To, Subject, Date, Message-Id, In-Reply-To,
+// References and Auto-Submitted. Other names return "".
+func (m *Message) Header(name string) string {
+	switch strings.ToLower(name) {
+	case "from":
+		return m.From
+	case "to":
+		return m.To
+	case "cc":
+		return m.CC
+	case "reply-to":
+		return m.ReplyTo
+	case "subject":
+		return m.Subject
+	case "date":
+		return m.Date
+	case "message-id":
+		return m.MessageID
+	case "in-reply-to":
+		return m.InReplyTo
+	case "references":
+		return m.References
+	case "auto-submitted":
+		return m.AutoSubmitted
+	}
+	return ""
+}
+
+// Decode returns the attachment's content
+func (a *Attachment) Decode() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(a.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attachment %s: %w", a.Filename, err)
+	}
+	return data, nil
+}
+
+// Bytes returns the raw RFC 5322 message
+func (r *RawMessage) Bytes() ([]byte, error) {
+	if !r.Base64 {
+		return []byte(r.Message), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(r.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode raw message: %w", err)
+	}
+	return data, nil
+}
+
+// Decode parses the raw message into headers and body
+func (r *RawMessage) Decode() (*mail.Message, error) {
+	data, err := r.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return mail.ReadMessage(bytes.NewReader(data))
+}
+
+// AuthResults returns the verdicts from the raw message's
+// Authentication-Results header. The hash format carries no headers.
+func (r *RawMessage) AuthResults() (AuthResults, error) {
+	msg, err := r.Decode()
+	if err != nil {
+		return AuthResults{}, err
+	}
+	return ParseAuthResults(msg.Header.Get("Authentication-Results")), nil
+}
+
+// ParseAuthResults extracts the dkim, spf and dmarc verdicts from an
+// Authentication-Results header value
+func ParseAuthResults(value string) AuthResults {
+	var results AuthResults
+	for _, clause := range strings.Split(value, ";") {
+		fields := strings.Fields(clause)
+		if len(fields) == 0 {
+			continue
+		}
+		method, verdict, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(method) {
+		case "dkim":
+			results.DKIM = strings.ToLower(verdict)
+		case "spf":
+			results.SPF = strings.ToLower(verdict)
+		case "dmarc":
+			results.DMARC = strings.ToLower(verdict)
+		}
+	}
+	return results
+}
@@ -0,0 +1,118 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+const hashPayload = `{
+	"id": 12345,
+	"rcpt_to": "support@example.com",
+	"mail_from": "customer@example.org",
+	"token": "rtmuzogUauKN",
+	"subject": "Help needed",
+	"message_id": "<abc@example.org>",
+	"timestamp": 1478361742.5,
+	"size": "822",
+	"spam_status": "Spam",
+	"spam_score": 7.2,
+	"bounce": false,
+	"received_with_ssl": true,
+	"to": "support@example.com",
+	"cc": null,
+	"from": "Customer <customer@example.org>",
+	"date": "Sat, 05 Nov 2016 16:02:22 +0000",
+	"in_reply_to": null,
+	"references": null,
+	"plain_body": "Hello",
+	"html_body": "<p>Hello</p>",
+	"attachment_quantity": 1,
+	"attachments": [
+		{"filename": "notes.txt", "content_type": "text/plain", "size": 5, "data": "aGVsbG8="}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	msg, err := Parse([]byte(hashPayload))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if msg.ID != 12345 || msg.RcptTo != "support@example.com" || msg.Subject != "Help needed" {
+		t.Errorf("Parse() = %+v, fields not decoded", msg)
+	}
+	if msg.CC != "" || msg.InReplyTo != "" {
+		t.Errorf("null fields should decode as empty, got CC=%q InReplyTo=%q", msg.CC, msg.InReplyTo)
+	}
+	if !msg.IsSpam() || msg.SpamScore != 7.2 {
+		t.Errorf("IsSpam() = %v, SpamScore = %v, want true, 7.2", msg.IsSpam(), msg.SpamScore)
+	}
+	if want := time.Unix(1478361742, 5e8); !msg.Time().Equal(want) {
+		t.Errorf("Time() = %v, want %v", msg.Time(), want)
+	}
+
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("len(Attachments) = %d, want 1", len(msg.Attachments))
+	}
+	data, err := msg.Attachments[0].Decode()
+	if err != nil {
+		t.Fatalf("Attachment.Decode() error = %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("Attachment.Decode() = %q, want %q", data, "hello")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("not json")); err == nil {
+		t.Error("Parse() expected error for invalid JSON")
+	}
+	if _, err := ParseRaw([]byte(`{"id": 1}`)); err == nil {
+		t.Error("ParseRaw() expected error for missing message")
+	}
+
+	att := Attachment{Filename: "bad.bin", Data: "!!!"}
+	if _, err := att.Decode(); err == nil {
+		t.Error("Attachment.Decode() expected error for invalid base64")
+	}
+}
+
+func TestRawMessage(t *testing.T) {
+	mail := "From: customer@example.org\r\n" +
+		"To: support@example.com\r\n" +
+		"Authentication-Results: mx.example.com; dkim=pass header.d=example.org; spf=softfail smtp.mailfrom=example.org; dmarc=FAIL\r\n" +
+		"Subject: Raw\r\n\r\nBody"
+
+	tests := []struct {
+		name    string
+		message string
+		base64  bool
+	}{
+		{name: "base64", message: base64.StdEncoding.EncodeToString([]byte(mail)), base64: true},
+		{name: "plain", message: mail, base64: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := &RawMessage{Message: tt.message, Base64: tt.base64}
+
+			msg, err := raw.Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got := msg.Header.Get("Subject"); got != "Raw" {
+				t.Errorf("Subject = %q, want %q", got, "Raw")
+			}
+
+			results, err := raw.AuthResults()
+			if err != nil {
+				t.Fatalf("AuthResults() error = %v", err)
+			}
+			want := AuthResults{DKIM: "pass", SPF: "softfail", DMARC: "fail"}
+			if results != want {
+				t.Errorf("AuthResults() = %+v, want %+v", results, want)
+			}
+		})
+	}
+}
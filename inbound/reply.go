@@ -0,0 +1,162 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/rawmail"
+)
+
+// ErrNoReplyAddress is returned when a message has no usable address to reply to
+var ErrNoReplyAddress = errors.New("inbound: message has no reply address")
+
+// ReplyOptions configures Reply
+type ReplyOptions struct {
+	// From is the sender of the reply
+	From string
+	// Body is the plain text written above the quoted original
+	Body string
+	// AutoReply marks the reply with Auto-Submitted: auto-replied
+	AutoReply bool
+}
+
+// IsAutoSubmitted returns true if the message was generated automatically,
+// in which case it should not be auto-replied to
+func (m *Message) IsAutoSubmitted() bool {
+	return m.AutoSubmitted != "" && !strings.EqualFold(m.AutoSubmitted, "no")
+}
+
+// Reply builds a reply to msg for SendRawMessage. It keeps the thread by
+// setting In-Reply-To and References, and quotes the original plain body.
+func Reply(msg *Message, opts ReplyOptions) (*types.RawMessage, error) {
+	to := replyAddress(msg)
+	if to == "" {
+		return nil, ErrNoReplyAddress
+	}
+
+	b := rawmail.NewRawBuilder().
+		WithFrom(opts.From).
+		WithTo(to).
+		WithSubject(prefixSubject("Re: ", msg.Subject)).
+		WithText(opts.Body + "\r\n\r\n" + fmt.Sprintf("On %s, %s wrote:\r\n", msg.Date, msg.From) + quote(msg.PlainBody))
+	if id := messageID(msg.MessageID); id != "" {
+		b.WithHeader("In-Reply-To", id).
+			WithHeader("References", references(msg.References, id))
+	}
+	if opts.AutoReply {
+		b.WithHeader("Auto-Submitted", "auto-replied")
+	}
+	return b.Build()
+}
+
+// Forward builds a message for SendRawMessage that forwards msg, including
+// its attachments, to the given recipients with an optional note
+func Forward(msg *Message, from string, to []string, note string) (*types.RawMessage, error) {
+	var body strings.Builder
+	if note != "" {
+		body.WriteString(note + "\r\n\r\n")
+	}
+	body.WriteString("---------- Forwarded message ----------\r\n")
+	for _, h := range [][2]string{{"From", msg.From}, {"Date", msg.Date}, {"Subject", msg.Subject}, {"To", msg.To}, {"Cc", msg.CC}} {
+		if h[1] != "" {
+			body.WriteString(h[0] + ": " + h[1] + "\r\n")
+		}
+	}
+	body.WriteString("\r\n" + msg.PlainBody)
+
+	b := rawmail.NewRawBuilder().
+		WithFrom(from).
+		WithTo(to...).
+		WithSubject(prefixSubject("Fwd: ", msg.Subject)).
+		WithText(body.String())
+	if id := messageID(msg.MessageID); id != "" {
+		b.WithHeader("References", references(msg.References, id))
+	}
+	for _, att := range msg.Attachments {
+		data, err := base64.StdEncoding.DecodeString(att.Data)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %q: invalid base64 data: %w", att.Filename, err)
+		}
+		name := att.Filename
+		if name == "" {
+			name = "attachment"
+		}
+		b.WithAttachment(name, attachmentType(att.ContentType), data)
+	}
+	return b.Build()
+}
+
+// replyAddress returns the Reply-To, From or envelope sender address. They
+// are parsed, so a sender cannot smuggle line breaks into the To header.
+func replyAddress(msg *Message) string {
+	for _, candidate := range []string{msg.ReplyTo, msg.From, msg.MailFrom} {
+		if addr, err := mail.ParseAddress(candidate); err == nil {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
+// messageID returns id as a <local@domain> message ID, or "" if it is not
+// one. The ID comes from the sender, so anything that could end the header
+// early or add another is rejected.
+func messageID(id string) string {
+	id = strings.TrimSpace(id)
+	if !strings.HasPrefix(id, "<") {
+		// Postal may send the ID without its angle brackets
+		id = "<" + id + ">"
+	}
+	if len(id) < 3 || !strings.HasSuffix(id, ">") || strings.ContainsAny(id[1:len(id)-1], "<> \t\r\n") {
+		return ""
+	}
+	return id
+}
+
+// references returns the References header continuing a thread: the valid
+// IDs of the original's References followed by its message ID
+func references(original, id string) string {
+	var ids []string
+	for _, ref := range strings.Fields(original) {
+		if !strings.HasPrefix(ref, "<") {
+			continue
+		}
+		if ref = messageID(ref); ref != "" {
+			ids = append(ids, ref)
+		}
+	}
+	return strings.Join(append(ids, id), " ")
+}
+
+// prefixSubject adds prefix unless the subject already starts with it
+func prefixSubject(prefix, subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), strings.ToLower(prefix)) {
+		return subject
+	}
+	return prefix + subject
+}
+
+// quote prefixes each line of text with "> "
+func quote(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// attachmentType returns a sender-supplied content type re-encoded, or
+// application/octet-stream when it is empty or invalid
+func attachmentType(contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		if formatted := mime.FormatMediaType(mediaType, params); formatted != "" {
+			return formatted
+		}
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,236 @@
+package inbound
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/validation"
+)
+
+func TestReply(t *testing.T) {
+	msg := &Message{
+		MailFrom:   "bounces@example.org",
+		From:       "Customer <customer@example.org>",
+		ReplyTo:    "Help Desk <desk@example.org>",
+		Subject:    "Order 42",
+		Date:       "Sat, 05 Nov 2016 16:02:22 +0000",
+		MessageID:  "<b@example.org>",
+		References: "<a@example.org>",
+		PlainBody:  "Where is it?\nThanks",
+	}
+
+	raw, err := Reply(msg, ReplyOptions{From: "support@example.com", Body: "On its way.", AutoReply: true})
+	if err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+	if err := validation.ValidateRawMessage(raw); err != nil {
+		t.Errorf("Reply() built an invalid raw message: %v", err)
+	}
+	if len(raw.To) != 1 || raw.To[0] != "desk@example.org" {
+		t.Errorf("To = %v, want the Reply-To address", raw.To)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(raw.Mail))
+	if err != nil {
+		t.Fatalf("failed to parse reply: %v", err)
+	}
+	headers := map[string]string{
+		"Subject":        "Re: Order 42",
+		"In-Reply-To":    "<b@example.org>",
+		"References":     "<a@example.org> <b@example.org>",
+		"Auto-Submitted": "auto-replied",
+	}
+	for name, want := range headers {
+		if got := parsed.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	body, _ := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	for _, want := range []string{"On its way.", "Customer <customer@example.org> wrote:", "> Where is it?\r\n> Thanks"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+}
+
+func TestReplySubjectAndAddress(t *testing.T) {
+	raw, err := Reply(&Message{MailFrom: "sender@example.org", Subject: "RE: Ticket"}, ReplyOptions{From: "support@example.com"})
+	if err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+	if raw.To[0] != "sender@example.org" {
+		t.Errorf("To = %v, want the envelope sender", raw.To)
+	}
+	if !strings.Contains(raw.Mail, "Subject: RE: Ticket\r\n") {
+		t.Errorf("subject was prefixed twice: %q", raw.Mail)
+	}
+
+	if _, err := Reply(&Message{}, ReplyOptions{From: "support@example.com"}); !errors.Is(err, ErrNoReplyAddress) {
+		t.Errorf("Reply() error = %v, want ErrNoReplyAddress", err)
+	}
+}
+
+func TestReplySanitizesHeaders(t *testing.T) {
+	tests := []struct {
+		name           string
+		msg            *Message
+		wantInReplyTo  string
+		wantReferences string
+	}{
+		{
+			name:           "injected message ID",
+			msg:            &Message{MailFrom: "a@example.org", MessageID: "<b@example.org>\r\nBcc: victim@example.com"},
+			wantInReplyTo:  "",
+			wantReferences: "",
+		},
+		{
+			name:           "injected references",
+			msg:            &Message{MailFrom: "a@example.org", MessageID: "<b@example.org>", References: "<a@example.org>\r\nBcc: victim@example.com <c@example.org>"},
+			wantInReplyTo:  "<b@example.org>",
+			wantReferences: "<a@example.org> <c@example.org> <b@example.org>",
+		},
+		{
+			name:           "bare message ID",
+			msg:            &Message{MailFrom: "a@example.org", MessageID: "b@example.org"},
+			wantInReplyTo:  "<b@example.org>",
+			wantReferences: "<b@example.org>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := Reply(tt.msg, ReplyOptions{From: "support@example.com"})
+			if err != nil {
+				t.Fatalf("Reply() error = %v", err)
+			}
+			parsed, err := mail.ReadMessage(strings.NewReader(raw.Mail))
+			if err != nil {
+				t.Fatalf("failed to parse reply: %v", err)
+			}
+			if got := parsed.Header.Get("Bcc"); got != "" {
+				t.Errorf("injected Bcc header %q", got)
+			}
+			if got := parsed.Header.Get("In-Reply-To"); got != tt.wantInReplyTo {
+				t.Errorf("In-Reply-To = %q, want %q", got, tt.wantInReplyTo)
+			}
+			if got := parsed.Header.Get("References"); got != tt.wantReferences {
+				t.Errorf("References = %q, want %q", got, tt.wantReferences)
+			}
+		})
+	}
+
+	// An envelope sender that is not an address is not used
+	if _, err := Reply(&Message{MailFrom: "a@example.org\r\nBcc: victim@example.com"}, ReplyOptions{From: "support@example.com"}); !errors.Is(err, ErrNoReplyAddress) {
+		t.Errorf("Reply() error = %v, want ErrNoReplyAddress", err)
+	}
+}
+
+func TestForwardAttachmentType(t *testing.T) {
+	for contentType, want := range map[string]string{
+		"":                              "application/octet-stream",
+		"text/plain\r\nX-Injected: yes": "application/octet-stream",
+		"text/plain; charset=utf-8":     "text/plain; charset=utf-8",
+	} {
+		msg := &Message{Attachments: []Attachment{{Filename: "a.txt", ContentType: contentType, Data: "aGVsbG8="}}}
+		raw, err := Forward(msg, "support@example.com", []string{"billing@example.com"}, "")
+		if err != nil {
+			t.Fatalf("Forward() error = %v", err)
+		}
+		parsed, err := mail.ReadMessage(strings.NewReader(raw.Mail))
+		if err != nil {
+			t.Fatalf("failed to parse forward: %v", err)
+		}
+		_, params, _ := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		mr := multipart.NewReader(parsed.Body, params["boundary"])
+		mr.NextPart()
+		att, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("missing attachment part: %v", err)
+		}
+		got, gotParams, _ := mime.ParseMediaType(att.Header.Get("Content-Type"))
+		wantType, wantParams, _ := mime.ParseMediaType(want)
+		if got != wantType || gotParams["charset"] != wantParams["charset"] {
+			t.Errorf("attachment Content-Type for %q = %q, want %q", contentType, att.Header.Get("Content-Type"), want)
+		}
+		if got := att.Header.Get("X-Injected"); got != "" {
+			t.Errorf("injected header X-Injected = %q", got)
+		}
+	}
+}
+
+func TestForward(t *testing.T) {
+	msg := &Message{
+		From:      "customer@example.org",
+		To:        "support@example.com",
+		Subject:   "Invoice",
+		PlainBody: "See attached",
+		Attachments: []Attachment{
+			{Filename: "invoice.pdf", ContentType: "application/pdf", Data: "aGVsbG8="},
+		},
+	}
+
+	raw, err := Forward(msg, "support@example.com", []string{"billing@example.com"}, "FYI")
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if err := validation.ValidateRawMessage(raw); err != nil {
+		t.Errorf("Forward() built an invalid raw message: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(raw.Mail))
+	if err != nil {
+		t.Fatalf("failed to parse forward: %v", err)
+	}
+	if got := parsed.Header.Get("Subject"); got != "Fwd: Invoice" {
+		t.Errorf("Subject = %q, want %q", got, "Fwd: Invoice")
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("invalid Content-Type: %v", err)
+	}
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+
+	text, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("missing text part: %v", err)
+	}
+	body, _ := io.ReadAll(text)
+	for _, want := range []string{"FYI", "---------- Forwarded message ----------", "Subject: Invoice", "See attached"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+
+	att, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("missing attachment part: %v", err)
+	}
+	if att.FileName() != "invoice.pdf" {
+		t.Errorf("FileName() = %q, want %q", att.FileName(), "invoice.pdf")
+	}
+}
+
+func TestIsAutoSubmitted(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"no", false},
+		{"auto-replied", true},
+		{"auto-generated", true},
+	}
+	for _, tt := range tests {
+		if got := (&Message{AutoSubmitted: tt.value}).IsAutoSubmitted(); got != tt.want {
+			t.Errorf("IsAutoSubmitted(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
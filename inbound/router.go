@@ -0,0 +1,123 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ErrNoRoute is returned by Router.Dispatch when no route matches and no
+// fallback is set
+var ErrNoRoute = errors.New("inbound: no route matches message")
+
+// Matcher reports whether a message should be handled by a route
+type Matcher func(msg *Message) bool
+
+// Router dispatches inbound messages to the first route whose matchers all
+// match. It implements http.Handler for endpoints using the hash format.
+type Router struct {
+	routes   []route
+	fallback HandlerFunc
+}
+
+// route pairs a handler with the matchers that select it
+type route struct {
+	matchers []Matcher
+	handler  HandlerFunc
+}
+
+// NewRouter creates an empty Router
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Handle registers a route. Routes are tried in registration order and a
+// route without matchers matches every message.
+func (r *Router) Handle(h HandlerFunc, matchers ...Matcher) *Router {
+	r.routes = append(r.routes, route{matchers: matchers, handler: h})
+	return r
+}
+
+// Fallback sets the handler for messages no route matches. Without one,
+// Dispatch returns ErrNoRoute and ServeHTTP acknowledges them unhandled.
+func (r *Router) Fallback(h HandlerFunc) *Router {
+	r.fallback = h
+	return r
+}
+
+// Dispatch passes msg to the first matching route
+func (r *Router) Dispatch(ctx context.Context, msg *Message) error {
+	for _, rt := range r.routes {
+		if matchAll(msg, rt.matchers) {
+			return rt.handler(ctx, msg)
+		}
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, msg)
+	}
+	return ErrNoRoute
+}
+
+// ServeHTTP implements http.Handler. Messages no route matches are
+// acknowledged and dropped when there is no fallback, since Postal would
+// otherwise redeliver them indefinitely; set a Fallback to keep them.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	Handler(func(ctx context.Context, msg *Message) error {
+		if err := r.Dispatch(ctx, msg); !errors.Is(err, ErrNoRoute) {
+			return err
+		}
+		return nil
+	}).ServeHTTP(w, req)
+}
+
+// Recipient matches the envelope recipient against a case-insensitive glob
+// such as "support+*@example.com" or "*@billing.example.com"
+func Recipient(pattern string) Matcher {
+	pattern = strings.ToLower(pattern)
+	return func(msg *Message) bool {
+		ok, _ := path.Match(pattern, strings.ToLower(msg.RcptTo))
+		return ok
+	}
+}
+
+// Subject matches the subject against a regular expression
+func Subject(re *regexp.Regexp) Matcher {
+	return func(msg *Message) bool {
+		return re.MatchString(msg.Subject)
+	}
+}
+
+// Header matches a header value against a regular expression. Only the
+// headers carried by the hash format are available; see Message.Header.
+func Header(name string, re *regexp.Regexp) Matcher {
+	return func(msg *Message) bool {
+		return re.MatchString(msg.Header(name))
+	}
+}
+
+// Spam matches messages Postal classified as spam
+func Spam() Matcher {
+	return func(msg *Message) bool {
+		return msg.IsSpam()
+	}
+}
+
+// Not inverts a matcher
+func Not(m Matcher) Matcher {
+	return func(msg *Message) bool {
+		return !m(msg)
+	}
+}
+
+// matchAll reports whether every matcher matches msg
+func matchAll(msg *Message, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m(msg) {
+			return false
+		}
+	}
+	return true
+}
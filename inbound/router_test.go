@@ -0,0 +1,104 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRouterDispatch(t *testing.T) {
+	var handled string
+	handle := func(name string) HandlerFunc {
+		return func(ctx context.Context, msg *Message) error {
+			handled = name
+			return nil
+		}
+	}
+
+	router := NewRouter().
+		Handle(handle("spam"), Spam()).
+		Handle(handle("billing"), Recipient("*@Billing.example.com")).
+		Handle(handle("urgent"), Recipient("support+*@example.com"), Subject(regexp.MustCompile(`(?i)urgent`))).
+		Handle(handle("auto"), Header("Auto-Submitted", regexp.MustCompile(`^auto-`)))
+
+	tests := []struct {
+		name string
+		msg  *Message
+		want string
+		err  error
+	}{
+		{name: "spam first", msg: &Message{RcptTo: "invoices@billing.example.com", SpamStatus: SpamStatusSpam}, want: "spam"},
+		{name: "recipient glob", msg: &Message{RcptTo: "Invoices@billing.example.com"}, want: "billing"},
+		{name: "all matchers", msg: &Message{RcptTo: "support+vip@example.com", Subject: "URGENT: down"}, want: "urgent"},
+		{name: "partial match", msg: &Message{RcptTo: "support+vip@example.com", Subject: "hello"}, err: ErrNoRoute},
+		{name: "header", msg: &Message{RcptTo: "x@example.com", AutoSubmitted: "auto-replied"}, want: "auto"},
+		{name: "no match", msg: &Message{RcptTo: "x@example.com"}, err: ErrNoRoute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled = ""
+			err := router.Dispatch(context.Background(), tt.msg)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Dispatch() error = %v, want %v", err, tt.err)
+			}
+			if handled != tt.want {
+				t.Errorf("handled by %q, want %q", handled, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouterFallback(t *testing.T) {
+	var fellBack bool
+	router := NewRouter().
+		Handle(func(ctx context.Context, msg *Message) error { return nil }, Not(Recipient("*@example.com"))).
+		Fallback(func(ctx context.Context, msg *Message) error {
+			fellBack = true
+			return nil
+		})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader(hashPayload)))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !fellBack {
+		t.Error("expected the fallback handler to run")
+	}
+}
+
+func TestRouterServeHTTPNoRoute(t *testing.T) {
+	failing := errors.New("failed")
+	tests := []struct {
+		name   string
+		router *Router
+		want   int
+	}{
+		{
+			name:   "unmatched is acknowledged",
+			router: NewRouter().Handle(func(ctx context.Context, msg *Message) error { return failing }, Recipient("nobody@example.net")),
+			want:   http.StatusOK,
+		},
+		{
+			name:   "handler error is retried",
+			router: NewRouter().Handle(func(ctx context.Context, msg *Message) error { return failing }),
+			want:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader(hashPayload)))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/middleware"
+)
+
+// Header is the request header Postal reads the API key from
+const Header = "X-Server-API-Key"
+
+// DefaultRefreshBefore is how long before expiry a cached token is refreshed
+const DefaultRefreshBefore = time.Minute
+
+// ErrEmptyToken is returned when a KeyProvider returns a token with no value
+var ErrEmptyToken = errors.New("auth: key provider returned an empty token")
+
+// Token is an API key obtained through a token exchange
+type Token struct {
+	Value string
+	// Expiry is when the server stops accepting the token. The zero value
+	// means the token does not expire.
+	Expiry time.Time
+}
+
+// KeyProvider exchanges credentials for API keys
+type KeyProvider interface {
+	// Token returns a fresh token. It is called by one request at a time.
+	Token(ctx context.Context) (Token, error)
+}
+
+// Config configures the auth middleware
+type Config struct {
+	Provider KeyProvider
+	// RefreshBefore is how long before expiry the token is refreshed.
+	// Zero uses DefaultRefreshBefore.
+	RefreshBefore time.Duration
+	// ReplaceKey is the key the token stands in for. Requests carrying any
+	// other key are sent unchanged; when empty every request gets the token.
+	ReplaceKey string
+}
+
+// New returns a middleware that sets the API key header from a cached token
+func New(cfg Config) middleware.Middleware {
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = DefaultRefreshBefore
+	}
+
+	// The cache is shared by every RoundTripper this middleware wraps, so
+	// rebuilt transports keep the current token
+	c := &cache{
+		provider:      cfg.Provider,
+		refreshBefore: cfg.RefreshBefore,
+		now:           time.Now,
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &transport{
+			next:       next,
+			cache:      c,
+			replaceKey: cfg.ReplaceKey,
+		}
+	}
+}
+
+type transport struct {
+	next       http.RoundTripper
+	cache      *cache
+	replaceKey string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.replaceKey != "" && req.Header.Get(Header) != t.replaceKey {
+		return t.next.RoundTrip(req)
+	}
+
+	key, err := t.cache.get(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("auth: token refresh failed: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, key)
+	resp, err := t.next.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// The server revoked the token early; fetch a new one next time
+		t.cache.invalidate(key)
+	}
+	return resp, err
+}
+
+// cache holds the current token and coordinates refreshes so concurrent
+// requests share a single call to the provider
+type cache struct {
+	provider      KeyProvider
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	token    Token
+	valid    bool
+	inflight *refresh
+}
+
+// refresh is a provider call that requests can wait on
+type refresh struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+// get returns a usable key. A token inside the refresh window is still
+// returned if its refresh fails or is already running elsewhere.
+func (c *cache) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	now := c.now()
+	if c.valid && !c.expired(now) {
+		current := c.token.Value
+		if !c.stale(now) || c.inflight != nil {
+			c.mu.Unlock()
+			return current, nil
+		}
+		r := c.start(ctx)
+		c.mu.Unlock()
+
+		if err := c.wait(ctx, r); err != nil {
+			return current, nil
+		}
+		return r.token.Value, nil
+	}
+
+	r := c.inflight
+	if r == nil {
+		r = c.start(ctx)
+	}
+	c.mu.Unlock()
+
+	if err := c.wait(ctx, r); err != nil {
+		return "", err
+	}
+	return r.token.Value, nil
+}
+
+// start begins a refresh. It must be called with mu held.
+func (c *cache) start(ctx context.Context) *refresh {
+	r := &refresh{done: make(chan struct{})}
+	c.inflight = r
+
+	// Waiting requests share the refresh, so it must outlive the request
+	// that happened to start it
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		token, err := c.provider.Token(ctx)
+		if err == nil && token.Value == "" {
+			err = ErrEmptyToken
+		}
+
+		c.mu.Lock()
+		r.token, r.err = token, err
+		if err == nil {
+			c.token, c.valid = token, true
+		}
+		c.inflight = nil
+		c.mu.Unlock()
+		close(r.done)
+	}()
+	return r
+}
+
+// wait blocks until r finishes or ctx is done
+func (c *cache) wait(ctx context.Context, r *refresh) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// invalidate drops the cached token if it is still key
+func (c *cache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.valid && c.token.Value == key {
+		c.valid = false
+	}
+}
+
+func (c *cache) expired(now time.Time) bool {
+	return !c.token.Expiry.IsZero() && !now.Before(c.token.Expiry)
+}
+
+func (c *cache) stale(now time.Time) bool {
+	return !c.token.Expiry.IsZero() && !now.Before(c.token.Expiry.Add(-c.refreshBefore))
+}
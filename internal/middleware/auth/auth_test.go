@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/middleware"
+)
+
+// countingProvider issues numbered tokens that expire after ttl
+type countingProvider struct {
+	calls atomic.Int32
+	ttl   time.Duration
+	now   func() time.Time
+	delay time.Duration
+	err   error
+}
+
+func (p *countingProvider) Token(ctx context.Context) (Token, error) {
+	n := p.calls.Add(1)
+	time.Sleep(p.delay)
+	if p.err != nil {
+		return Token{}, p.err
+	}
+	return Token{Value: fmt.Sprintf("token-%d", n), Expiry: p.now().Add(p.ttl)}, nil
+}
+
+// newTestCache returns a cache with a clock the test controls
+func newTestCache(p *countingProvider) (*cache, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	return &cache{provider: p, refreshBefore: time.Minute, now: p.now}, &now
+}
+
+func TestCacheReusesToken(t *testing.T) {
+	p := &countingProvider{ttl: time.Hour}
+	c, now := newTestCache(p)
+
+	for i := 0; i < 3; i++ {
+		key, err := c.get(context.Background())
+		if err != nil || key != "token-1" {
+			t.Fatalf("get() = %q, %v; want token-1", key, err)
+		}
+	}
+
+	// Inside the refresh window the token is renewed ahead of expiry
+	*now = now.Add(59*time.Minute + time.Second)
+	if key, _ := c.get(context.Background()); key != "token-2" {
+		t.Errorf("get() in refresh window = %q, want token-2", key)
+	}
+	if got := p.calls.Load(); got != 2 {
+		t.Errorf("provider calls = %d, want 2", got)
+	}
+}
+
+func TestCacheSingleFlight(t *testing.T) {
+	p := &countingProvider{ttl: time.Hour, delay: 20 * time.Millisecond}
+	c, _ := newTestCache(p)
+
+	var wg sync.WaitGroup
+	keys := make([]string, 20)
+	for i := range keys {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			keys[i], _ = c.get(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	if got := p.calls.Load(); got != 1 {
+		t.Errorf("provider calls = %d, want 1", got)
+	}
+	for i, key := range keys {
+		if key != "token-1" {
+			t.Errorf("keys[%d] = %q, want token-1", i, key)
+		}
+	}
+}
+
+func TestCacheRefreshFailure(t *testing.T) {
+	p := &countingProvider{ttl: time.Hour}
+	c, now := newTestCache(p)
+	if _, err := c.get(context.Background()); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+
+	// A failed early refresh keeps the still-valid token
+	p.err = errors.New("exchange failed")
+	*now = now.Add(59*time.Minute + time.Second)
+	if key, err := c.get(context.Background()); err != nil || key != "token-1" {
+		t.Errorf("get() after failed refresh = %q, %v; want token-1", key, err)
+	}
+
+	// Once expired there is nothing to fall back on
+	*now = now.Add(time.Minute)
+	if _, err := c.get(context.Background()); !errors.Is(err, p.err) {
+		t.Errorf("get() after expiry error = %v, want %v", err, p.err)
+	}
+}
+
+func TestCacheEmptyToken(t *testing.T) {
+	c := &cache{
+		provider: providerFunc(func(context.Context) (Token, error) { return Token{}, nil }),
+		now:      time.Now,
+	}
+	if _, err := c.get(context.Background()); !errors.Is(err, ErrEmptyToken) {
+		t.Errorf("get() error = %v, want ErrEmptyToken", err)
+	}
+}
+
+type providerFunc func(context.Context) (Token, error)
+
+func (f providerFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
+
+func TestMiddleware(t *testing.T) {
+	p := &countingProvider{ttl: time.Hour, now: time.Now}
+	status := http.StatusOK
+	var sent []string
+	rt := New(Config{Provider: p, ReplaceKey: "placeholder"})(middleware.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, r.Header.Get(Header))
+		return &http.Response{StatusCode: status}, nil
+	}))
+
+	send := func(key string) {
+		req, _ := http.NewRequest(http.MethodPost, "http://example.com", nil)
+		req.Header.Set(Header, key)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatalf("RoundTrip() error = %v", err)
+		}
+		if got := req.Header.Get(Header); got != key {
+			t.Errorf("caller's request header changed to %q", got)
+		}
+	}
+
+	send("placeholder")
+	send("tenant-key")
+	status = http.StatusUnauthorized
+	send("placeholder")
+	status = http.StatusOK
+	send("placeholder")
+
+	want := []string{"token-1", "tenant-key", "token-1", "token-2"}
+	if fmt.Sprint(sent) != fmt.Sprint(want) {
+		t.Errorf("sent keys = %v, want %v", sent, want)
+	}
+}
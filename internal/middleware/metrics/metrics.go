@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/sachin-duhan/postal-go/internal/middleware"
+	"github.com/sachin-duhan/postal-go/internal/middleware/timing"
 )
 
 // Collector interface for collecting metrics
@@ -14,14 +15,46 @@ type Collector interface {
 	ObserveResponseSize(method, path string, bytes int64)
 }
 
-// New returns a middleware that collects metrics
+// TimingCollector is implemented by collectors that also want each
+// request's DNS, connect, TLS and first byte timings
+type TimingCollector interface {
+	Collector
+	ObserveRequestTimings(method, path string, timings timing.Timings)
+}
+
+// ConnReuseCollector is implemented by collectors that count whether
+// requests reused a pooled connection. A low reuse rate points at keep-alive
+// being disabled or idle connections being closed too early.
+type ConnReuseCollector interface {
+	Collector
+	IncConnectionReuse(method, path string, reused bool)
+}
+
+// New returns a middleware that collects metrics. Requests are traced only
+// when collector implements TimingCollector or ConnReuseCollector.
 func New(collector Collector) middleware.Middleware {
-	return func(next http.RoundTripper) http.RoundTripper {
+	collect := func(next http.RoundTripper) http.RoundTripper {
 		return &transport{
 			next:      next,
 			collector: collector,
 		}
 	}
+	tc, timings := collector.(TimingCollector)
+	rc, reuse := collector.(ConnReuseCollector)
+	if !timings && !reuse {
+		return collect
+	}
+	return middleware.Chain(collect, timing.New(timing.Config{
+		Report: func(req *http.Request, t timing.Timings, err error) {
+			if timings {
+				tc.ObserveRequestTimings(req.Method, req.URL.Path, t)
+			}
+			// A failed dial never got a connection to count
+			if reuse && err == nil {
+				rc.IncConnectionReuse(req.Method, req.URL.Path, t.ReusedConn)
+			}
+		},
+	}))
 }
 
 type transport struct {
@@ -7,11 +7,26 @@ import (
 // Middleware represents a function that wraps an http.RoundTripper
 type Middleware func(http.RoundTripper) http.RoundTripper
 
-// Chain combines multiple middleware into a single middleware
+// Chain combines multiple middleware into a single middleware.
+// The chain is composed once when the returned middleware is applied, so the
+// resulting RoundTripper does not allocate per request.
 func Chain(middleware ...Middleware) Middleware {
+	switch len(middleware) {
+	case 0:
+		return func(next http.RoundTripper) http.RoundTripper {
+			return next
+		}
+	case 1:
+		return middleware[0]
+	}
+
+	// Copy so later changes to the caller's slice don't affect the chain
+	mws := make([]Middleware, len(middleware))
+	copy(mws, middleware)
+
 	return func(next http.RoundTripper) http.RoundTripper {
-		for i := len(middleware) - 1; i >= 0; i-- {
-			next = middleware[i](next)
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
 		}
 		return next
 	}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// passthrough is a middleware that forwards requests without allocating
+type passthrough struct {
+	next http.RoundTripper
+}
+
+func (p *passthrough) RoundTrip(req *http.Request) (*http.Response, error) {
+	return p.next.RoundTrip(req)
+}
+
+func newPassthrough(next http.RoundTripper) http.RoundTripper {
+	return &passthrough{next: next}
+}
+
+var okResponse = &http.Response{StatusCode: http.StatusOK}
+
+var terminal = RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+	return okResponse, nil
+})
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(r)
+			})
+		}
+	}
+
+	rt := Chain(tag("first"), tag("second"), tag("third"))(terminal)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+
+	want := []string{"first", "second", "third"}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("middleware order = %v, want %v", order, want)
+	}
+}
+
+func TestChainEmpty(t *testing.T) {
+	rt := Chain()(terminal)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil || resp != okResponse {
+		t.Errorf("empty chain RoundTrip() = %v, %v; want passthrough", resp, err)
+	}
+}
+
+func TestChainComposesOnce(t *testing.T) {
+	wraps := 0
+	counting := func(next http.RoundTripper) http.RoundTripper {
+		wraps++
+		return newPassthrough(next)
+	}
+
+	rt := Chain(counting, counting, counting)(terminal)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	for i := 0; i < 10; i++ {
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatalf("RoundTrip() error = %v", err)
+		}
+	}
+
+	if wraps != 3 {
+		t.Errorf("middleware wrapped %d times, want 3", wraps)
+	}
+}
+
+func TestChainRoundTripAllocs(t *testing.T) {
+	mws := make([]Middleware, 8)
+	for i := range mws {
+		mws[i] = newPassthrough
+	}
+	rt := Chain(mws...)(terminal)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = rt.RoundTrip(req)
+	})
+	if allocs != 0 {
+		t.Errorf("composed chain allocates %v times per request, want 0", allocs)
+	}
+}
+
+func BenchmarkChainCompose(b *testing.B) {
+	for _, n := range []int{1, 4, 16} {
+		mws := make([]Middleware, n)
+		for i := range mws {
+			mws[i] = newPassthrough
+		}
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = Chain(mws...)(terminal)
+			}
+		})
+	}
+}
+
+func BenchmarkChainRoundTrip(b *testing.B) {
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	for _, n := range []int{0, 1, 4, 16} {
+		mws := make([]Middleware, n)
+		for i := range mws {
+			mws[i] = newPassthrough
+		}
+		rt := Chain(mws...)(terminal)
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = rt.RoundTrip(req)
+			}
+		})
+	}
+}
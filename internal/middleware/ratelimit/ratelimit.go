@@ -23,10 +23,14 @@ func New(cfg Config) middleware.Middleware {
 		}
 	}
 
+	// The limiter is shared by every RoundTripper this middleware wraps, so
+	// the budget holds even when the middleware is applied per call
+	limiter := rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.Burst)
+
 	return func(next http.RoundTripper) http.RoundTripper {
 		return &transport{
 			next:    next,
-			limiter: rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.Burst),
+			limiter: limiter,
 		}
 	}
 }
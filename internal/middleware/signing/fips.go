@@ -0,0 +1,6 @@
+//go:build postal_fips
+
+package signing
+
+// minSecretLength is the 112-bit minimum HMAC key length of NIST SP 800-131A
+const minSecretLength = 14
@@ -0,0 +1,33 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrWeakSecret is returned when an HMAC secret is shorter than the build
+// allows
+var ErrWeakSecret = errors.New("hmac secret too short")
+
+// HMAC signs bodies with HMAC-SHA256 under a shared secret
+type HMAC struct {
+	secret []byte
+}
+
+// NewHMAC returns an HMAC-SHA256 signer. The secret is copied.
+func NewHMAC(secret []byte) *HMAC {
+	return &HMAC{secret: append([]byte(nil), secret...)}
+}
+
+// Sign implements Signer, returning the hex encoded MAC of body
+func (h *HMAC) Sign(body []byte) (string, error) {
+	if len(h.secret) < minSecretLength {
+		return "", fmt.Errorf("%w: %d bytes, need at least %d", ErrWeakSecret, len(h.secret), minSecretLength)
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
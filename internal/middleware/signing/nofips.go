@@ -0,0 +1,6 @@
+//go:build !postal_fips
+
+package signing
+
+// minSecretLength allows any secret outside FIPS builds
+const minSecretLength = 0
@@ -0,0 +1,77 @@
+package signing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sachin-duhan/postal-go/internal/middleware"
+)
+
+// DefaultHeader is the header the signature is written to when none is configured
+const DefaultHeader = "X-Postal-Signature"
+
+// Signer computes the header value for a request body. Builds that must use
+// validated cryptography can supply their own implementation.
+type Signer interface {
+	Sign(body []byte) (string, error)
+}
+
+// Config configures the signing middleware
+type Config struct {
+	// Signer signs request bodies
+	Signer Signer
+	// Header receives the signature
+	Header string
+}
+
+// New returns a middleware that signs request bodies. Streamed bodies are
+// buffered so they can be signed before sending.
+func New(cfg Config) middleware.Middleware {
+	if cfg.Header == "" {
+		cfg.Header = DefaultHeader
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &transport{
+			next:   next,
+			signer: cfg.Signer,
+			header: cfg.Header,
+		}
+	}
+}
+
+type transport struct {
+	next   http.RoundTripper
+	signer Signer
+	header string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("signing: failed to read request body: %w", err)
+		}
+	}
+
+	signature, err := t.signer.Sign(body)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+	}
+	req.Header.Set(t.header, signature)
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,87 @@
+package signing
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/internal/middleware"
+)
+
+func TestSign(t *testing.T) {
+	// RFC 4231 test case 2
+	got, err := NewHMAC([]byte("Jefe")).Sign([]byte("what do ya want for nothing?"))
+	if err != nil && minSecretLength <= len("Jefe") {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if err != nil {
+		t.Skip("short secrets are rejected in this build")
+	}
+	want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		body   io.Reader
+		want   string
+	}{
+		{"default header", "", strings.NewReader(`{"to":["a@example.com"]}`), DefaultHeader},
+		{"custom header", "X-Gateway-Signature", strings.NewReader(`{}`), "X-Gateway-Signature"},
+		{"no body", "", nil, DefaultHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSig, gotBody string
+			signer := NewHMAC([]byte("shared-gateway-secret"))
+			rt := New(Config{Signer: signer, Header: tt.header})(middleware.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				gotSig = r.Header.Get(tt.want)
+				if r.Body != nil {
+					b, _ := io.ReadAll(r.Body)
+					gotBody = string(b)
+				}
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}))
+
+			req, _ := http.NewRequest(http.MethodPost, "http://example.com", tt.body)
+			if _, err := rt.RoundTrip(req); err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+			if want, _ := signer.Sign([]byte(gotBody)); gotSig != want {
+				t.Errorf("%s = %q, want %q", tt.want, gotSig, want)
+			}
+			if req.Header.Get(tt.want) != "" {
+				t.Error("caller's request was modified")
+			}
+		})
+	}
+}
+
+type failingSigner struct{}
+
+func (failingSigner) Sign([]byte) (string, error) {
+	return "", errors.New("hsm unavailable")
+}
+
+func TestMiddlewareSignerError(t *testing.T) {
+	called := false
+	rt := New(Config{Signer: failingSigner{}})(middleware.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		called = true
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader(`{}`))
+	if _, err := rt.RoundTrip(req); err == nil {
+		t.Error("RoundTrip() error = nil, want signer error")
+	}
+	if called {
+		t.Error("unsigned request was sent")
+	}
+}
@@ -0,0 +1,151 @@
+package timing
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/middleware"
+)
+
+// Timings breaks down where an HTTP request spent its time. Phases that did
+// not happen, such as DNS and connect on a reused connection, are zero.
+type Timings struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// FirstByte is from the request being written to the first response byte,
+	// roughly the server's processing time
+	FirstByte time.Duration
+	// Total is from the start of the request to the response headers
+	Total time.Duration
+	// ReusedConn reports whether a pooled connection was used, and ConnIdle
+	// how long it sat idle in the pool before
+	ReusedConn bool
+	ConnIdle   time.Duration
+}
+
+// String formats the timings for logs
+func (t Timings) String() string {
+	s := fmt.Sprintf("dns=%v connect=%v tls=%v ttfb=%v total=%v reused=%t",
+		t.DNS, t.Connect, t.TLS, t.FirstByte, t.Total, t.ReusedConn)
+	if t.ConnIdle > 0 {
+		s += fmt.Sprintf(" idle=%v", t.ConnIdle)
+	}
+	return s
+}
+
+// Config configures the timing middleware
+type Config struct {
+	// Threshold is the total time from which requests are reported. Zero
+	// reports every request.
+	Threshold time.Duration
+	// Report receives the timings of each reported request, with the error
+	// from the round trip if it failed
+	Report func(req *http.Request, t Timings, err error)
+}
+
+// New returns a middleware that times requests with httptrace
+func New(cfg Config) middleware.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &transport{next: next, cfg: cfg}
+	}
+}
+
+type transport struct {
+	next http.RoundTripper
+	cfg  Config
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, done := Trace(req.Context())
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+
+	timings := done()
+	if timings.Total >= t.cfg.Threshold && t.cfg.Report != nil {
+		t.cfg.Report(req, timings, err)
+	}
+	return resp, err
+}
+
+// Trace returns ctx with an httptrace hook that records the request made
+// with it, and a function returning the timings once the response headers
+// have arrived
+func Trace(ctx context.Context) (context.Context, func() Timings) {
+	rec := &recorder{start: time.Now()}
+	return httptrace.WithClientTrace(ctx, rec.trace()), func() Timings {
+		return rec.timings(time.Now())
+	}
+}
+
+// recorder collects httptrace events. Connect events may arrive from dialing
+// goroutines, so fields are guarded by mu.
+type recorder struct {
+	mu                     sync.Mutex
+	start                  time.Time
+	dnsStart, dnsDone      time.Time
+	connectStart, connDone time.Time
+	tlsStart, tlsDone      time.Time
+	wrote, firstByte       time.Time
+	reused                 bool
+	idle                   time.Duration
+}
+
+// trace returns hooks that record event times
+func (r *recorder) trace() *httptrace.ClientTrace {
+	at := func(field *time.Time) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { at(&r.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { at(&r.dnsDone) },
+		ConnectStart:         func(string, string) { at(&r.connectStart) },
+		ConnectDone:          func(string, string, error) { at(&r.connDone) },
+		TLSHandshakeStart:    func() { at(&r.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { at(&r.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { at(&r.wrote) },
+		GotFirstResponseByte: func() { at(&r.firstByte) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.reused = info.Reused
+			r.idle = info.IdleTime
+		},
+	}
+}
+
+// timings computes the phase durations of a request that ended at end
+func (r *recorder) timings(end time.Time) Timings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sent := r.wrote
+	if sent.IsZero() {
+		sent = r.start
+	}
+	return Timings{
+		DNS:        span(r.dnsStart, r.dnsDone),
+		Connect:    span(r.connectStart, r.connDone),
+		TLS:        span(r.tlsStart, r.tlsDone),
+		FirstByte:  span(sent, r.firstByte),
+		Total:      end.Sub(r.start),
+		ReusedConn: r.reused,
+		ConnIdle:   r.idle,
+	}
+}
+
+// span returns the time between two events, or zero if either is missing
+func span(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
@@ -0,0 +1,68 @@
+package timing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var reported []Timings
+	var paths []string
+	rt := New(Config{
+		Threshold: 20 * time.Millisecond,
+		Report: func(req *http.Request, timings Timings, err error) {
+			paths = append(paths, req.URL.Path)
+			reported = append(reported, timings)
+		},
+	})(&http.Transport{})
+	client := &http.Client{Transport: rt}
+
+	for _, path := range []string{"/fast", "/slow"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if len(reported) != 1 || paths[0] != "/slow" {
+		t.Fatalf("reported %v, want only /slow", paths)
+	}
+	got := reported[0]
+	if got.Total < 30*time.Millisecond || got.FirstByte < 30*time.Millisecond || got.FirstByte > got.Total {
+		t.Errorf("timings = %s, want ttfb and total of at least 30ms", got)
+	}
+	if !got.ReusedConn || got.Connect != 0 || got.ConnIdle <= 0 {
+		t.Errorf("timings = %s, want the connection from the first request reused", got)
+	}
+}
+
+func TestTimingNewConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var got Timings
+	rt := New(Config{Report: func(req *http.Request, timings Timings, err error) { got = timings }})(&http.Transport{})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got.ReusedConn || got.Connect <= 0 || got.Total <= 0 {
+		t.Errorf("timings = %s, want a new connection with connect time", got)
+	}
+}
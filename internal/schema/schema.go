@@ -0,0 +1,172 @@
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+var (
+	//go:embed envelope.json
+	envelopeJSON []byte
+
+	//go:embed error.json
+	errorJSON []byte
+
+	// Envelope describes a successful Postal API response
+	Envelope = mustParse(envelopeJSON)
+
+	// Error describes a Postal API error response
+	Error = mustParse(errorJSON)
+)
+
+// Schema is the subset of JSON Schema needed to describe Postal responses
+type Schema struct {
+	Type                 string             `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+}
+
+func mustParse(data []byte) *Schema {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		panic(fmt.Sprintf("schema: invalid embedded schema: %v", err))
+	}
+	return &s
+}
+
+// Validate checks a JSON document against the schema and returns a
+// description of each divergence. An empty result means the document matches.
+func (s *Schema) Validate(data []byte) []string {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []string{fmt.Sprintf("$: invalid JSON: %v", err)}
+	}
+
+	var problems []string
+	s.validate("$", doc, &problems)
+	return problems
+}
+
+func (s *Schema) validate(path string, value interface{}, problems *[]string) {
+	if s.Type != "" && !matchesType(s.Type, value) {
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", path, s.Type, typeOf(value)))
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		*problems = append(*problems, fmt.Sprintf("%s: unexpected value %v", path, value))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: missing required field %q", path, name))
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			prop, ok := s.Properties[k]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*problems = append(*problems, fmt.Sprintf("%s: unknown field %q", path, k))
+				}
+				continue
+			}
+			prop.validate(path+"."+k, v[k], problems)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	}
+}
+
+// UnknownFields returns the paths of fields in a JSON document that the
+// schema does not describe, e.g. "$.extra". Invalid JSON returns nil.
+func (s *Schema) UnknownFields(data []byte) []string {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	var unknown []string
+	s.unknownFields("$", doc, &unknown)
+	return unknown
+}
+
+func (s *Schema) unknownFields(path string, value interface{}, unknown *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				prop.unknownFields(path+"."+k, v[k], unknown)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*unknown = append(*unknown, path+"."+k)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.unknownFields(fmt.Sprintf("%s[%d]", path, i), item, unknown)
+			}
+		}
+	}
+}
+
+func matchesType(want string, value interface{}) bool {
+	got := typeOf(value)
+	if want == "number" && got == "integer" {
+		return true
+	}
+	return want == got
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvelopeValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		problems []string
+	}{
+		{
+			name: "valid envelope",
+			body: `{"status": "success", "time": 0.05, "flags": {}, "data": {"message_id": "abc"}}`,
+		},
+		{
+			name: "valid flat result",
+			body: `{"message_id": "12345", "status": "success"}`,
+		},
+		{
+			name:     "missing status",
+			body:     `{"message_id": "12345"}`,
+			problems: []string{`$: missing required field "status"`},
+		},
+		{
+			name:     "wrong field type",
+			body:     `{"status": "success", "time": "fast"}`,
+			problems: []string{"$.time: expected number, got string"},
+		},
+		{
+			name:     "unknown status",
+			body:     `{"status": "queued"}`,
+			problems: []string{"$.status: unexpected value queued"},
+		},
+		{
+			name:     "unknown field",
+			body:     `{"status": "success", "extra": 1}`,
+			problems: []string{`$: unknown field "extra"`},
+		},
+		{
+			name:     "wrong item type",
+			body:     `{"status": "error", "errors": ["bad", 1]}`,
+			problems: []string{"$.errors[1]: expected string, got integer"},
+		},
+		{
+			name:     "not an object",
+			body:     `[]`,
+			problems: []string{"$: expected object, got array"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Envelope.Validate([]byte(tt.body))
+			if strings.Join(got, "\n") != strings.Join(tt.problems, "\n") {
+				t.Errorf("Validate() = %v, want %v", got, tt.problems)
+			}
+		})
+	}
+}
+
+func TestErrorValidate(t *testing.T) {
+	if got := Error.Validate([]byte(`{"code": "rate_limit", "message": "slow down"}`)); len(got) != 0 {
+		t.Errorf("Validate() = %v, want no problems", got)
+	}
+	if got := Error.Validate([]byte(`invalid json`)); len(got) != 1 || !strings.Contains(got[0], "invalid JSON") {
+		t.Errorf("Validate() = %v, want invalid JSON problem", got)
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "known envelope", body: `{"status": "success", "time": 0.05, "flags": {}, "data": {"x": 1}}`},
+		{name: "unknown fields", body: `{"status": "success", "zeta": 1, "alpha": {}}`, want: []string{"$.alpha", "$.zeta"}},
+		{name: "type mismatch is not unknown", body: `{"status": "success", "time": "fast"}`},
+		{name: "invalid JSON", body: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Envelope.UnknownFields([]byte(tt.body))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("UnknownFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
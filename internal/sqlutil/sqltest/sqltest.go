@@ -0,0 +1,123 @@
+// Package sqltest provides a fake database/sql driver for testing the SQL
+// stores. It records statements and serves canned rows.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+// DB records statements and serves canned rows for queries
+type DB struct {
+	mu sync.Mutex
+	// Execs and Queries are the statements run, in order
+	Execs   []Stmt
+	Queries []Stmt
+	// Columns and Rows are returned by every query
+	Columns []string
+	Rows    [][]driver.Value
+	// OnExec, when set, can fail a statement before it is recorded as
+	// succeeding
+	OnExec func(query string, args []driver.Value) error
+}
+
+// Stmt is a recorded statement and its arguments
+type Stmt struct {
+	Query string
+	Args  []driver.Value
+}
+
+var (
+	dbs   = map[string]*DB{}
+	dbsMu sync.Mutex
+)
+
+func init() {
+	sql.Register("postal-sqltest", fakeDriver{})
+}
+
+// Open opens a database backed by a new DB for the duration of the test
+func Open(t testing.TB) (*sql.DB, *DB) {
+	t.Helper()
+	d := &DB{}
+	dbsMu.Lock()
+	dbs[t.Name()] = d
+	dbsMu.Unlock()
+
+	db, err := sql.Open("postal-sqltest", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		dbsMu.Lock()
+		delete(dbs, t.Name())
+		dbsMu.Unlock()
+	})
+	return db, d
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	dbsMu.Lock()
+	defer dbsMu.Unlock()
+	return &fakeConn{d: dbs[name]}, nil
+}
+
+type fakeConn struct{ d *DB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("transactions not supported") }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	stmt := Stmt{Query: query, Args: values(args)}
+	c.d.Execs = append(c.d.Execs, stmt)
+	if c.d.OnExec != nil {
+		if err := c.d.OnExec(stmt.Query, stmt.Args); err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.Queries = append(c.d.Queries, Stmt{Query: query, Args: values(args)})
+	return &fakeRows{columns: c.d.Columns, rows: c.d.Rows}, nil
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	out := make([]driver.Value, len(args))
+	for i, a := range args {
+		out[i] = a.Value
+	}
+	return out
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package sqlutil holds the database/sql helpers shared by the SQL stores
+package sqlutil
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Placeholder styles for SQL parameters
+const (
+	// PlaceholderQuestion uses "?" (MySQL, SQLite)
+	PlaceholderQuestion = iota
+	// PlaceholderDollar uses "$1", "$2"... (PostgreSQL)
+	PlaceholderDollar
+)
+
+// RetentionInterval is how often a store with a retention period deletes
+// expired rows
+const RetentionInterval = time.Minute
+
+// Placeholder returns the n-th (1-based) parameter placeholder in style
+func Placeholder(style, n int) string {
+	if style == PlaceholderDollar {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Placeholders returns a comma separated list of placeholders
+// first..first+count-1 in style
+func Placeholders(style, first, count int) string {
+	ps := make([]string, count)
+	for i := range ps {
+		ps[i] = Placeholder(style, first+i)
+	}
+	return strings.Join(ps, ", ")
+}
+
+// PurgeSchedule spaces out retention purges so that at most one runs per
+// RetentionInterval across concurrent writers. The zero value is due
+// immediately.
+type PurgeSchedule struct {
+	// last is when a purge last started, in Unix nanoseconds
+	last atomic.Int64
+}
+
+// Due reports whether a purge should run at now, claiming it if so
+func (p *PurgeSchedule) Due(now time.Time) bool {
+	last := p.last.Load()
+	return now.Sub(time.Unix(0, last)) >= RetentionInterval && p.last.CompareAndSwap(last, now.UnixNano())
+}
@@ -0,0 +1,35 @@
+package sqlutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		style int
+		want  string
+	}{
+		{PlaceholderQuestion, "?, ?, ?"},
+		{PlaceholderDollar, "$2, $3, $4"},
+	}
+	for _, tt := range tests {
+		if got := Placeholders(tt.style, 2, 3); got != tt.want {
+			t.Errorf("Placeholders(%d, 2, 3) = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestPurgeSchedule(t *testing.T) {
+	var p PurgeSchedule
+	now := time.Now()
+	if !p.Due(now) {
+		t.Error("Due() = false for a new schedule")
+	}
+	if p.Due(now.Add(RetentionInterval / 2)) {
+		t.Error("Due() = true within RetentionInterval of the last purge")
+	}
+	if !p.Due(now.Add(RetentionInterval)) {
+		t.Error("Due() = false after RetentionInterval")
+	}
+}
@@ -4,21 +4,73 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"mime"
 	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sachin-duhan/postal-go/common/types"
 	"github.com/sachin-duhan/postal-go/common/utils"
 	"github.com/sachin-duhan/postal-go/internal/middleware"
+	"github.com/sachin-duhan/postal-go/internal/middleware/timing"
+	"github.com/sachin-duhan/postal-go/internal/schema"
 )
 
 // Transport handles HTTP communication with the Postal API
 type Transport struct {
 	urlBuilder *utils.URLBuilder
 	apiKey     string
+	// httpClient is a copy of the caller's client that settings are applied
+	// to, so the caller's client is never modified
 	httpClient *http.Client
 	middleware []middleware.Middleware
+
+	// mu guards middleware and rebuilds of client
+	mu sync.Mutex
+	// client is httpClient with the middleware chain composed in. It is
+	// rebuilt only when the chain or client settings change, never per request.
+	client atomic.Pointer[http.Client]
+
+	// debugLogger, when set, receives response schema divergences
+	debugLogger atomic.Pointer[log.Logger]
+
+	compat atomic.Pointer[Compatibility]
+
+	// maxResponseSize caps bytes read from a response body; <= 0 is unlimited
+	maxResponseSize atomic.Int64
+
+	// strict rejects successful responses with fields the schema does not know
+	strict atomic.Bool
+}
+
+// ResponseFormat describes how the server shapes successful responses
+type ResponseFormat int
+
+const (
+	// FormatAuto accepts both flat and enveloped responses
+	FormatAuto ResponseFormat = iota
+	// FormatFlat expects message_id at the top level of the response
+	FormatFlat
+	// FormatEnvelope expects results inside the data field of the envelope
+	FormatEnvelope
+)
+
+// Compatibility adjusts requests and responses for a particular Postal release
+type Compatibility struct {
+	// PathPrefix replaces the default "api/v1" prefix when non-empty
+	PathPrefix string
+	// FieldAliases renames top-level request body fields (client name -> server name)
+	FieldAliases map[string]string
+	// Format selects how successful responses are parsed
+	Format ResponseFormat
 }
 
 // Request represents an API request
@@ -27,8 +79,35 @@ type Request struct {
 	Path    string
 	Body    interface{}
 	Headers map[string]string
+
+	// APIKey overrides the transport's API key for this request when non-empty
+	APIKey string
+
+	// Mutators are applied to the HTTP request after default headers are set
+	Mutators []func(*http.Request)
+	// Middleware wraps the transport for this request only
+	Middleware []middleware.Middleware
+
+	// BodyStream, when set, supplies the request body instead of marshalling
+	// Body. It is called once per attempt and field aliases are not applied.
+	BodyStream func() (io.Reader, error)
+	// ContentLength is the length of the BodyStream body, if known. Without
+	// it the body is sent chunked.
+	ContentLength int64
+
+	// Data, when set, receives the data field of a successful enveloped
+	// response instead of Result.Data, for endpoints whose data is not a
+	// JSON object or has a typed shape
+	Data interface{}
+
+	// Optional marks endpoints older servers may lack. A 404, 405 or 501
+	// response is then returned as a types.EndpointNotSupportedError.
+	Optional bool
 }
 
+// ErrBodyConsumed is returned by a BodyStream that cannot be replayed for a retry
+var ErrBodyConsumed = errors.New("request body stream already consumed")
+
 // NewTransport creates a new Transport instance
 func NewTransport(baseURL, apiKey string, client *http.Client) (*Transport, error) {
 	// Validate and standardize the URL
@@ -42,81 +121,549 @@ func NewTransport(baseURL, apiKey string, client *http.Client) (*Transport, erro
 		return nil, fmt.Errorf("failed to create URL builder: %w", err)
 	}
 
-	return &Transport{
+	t := &Transport{
 		urlBuilder: urlBuilder,
 		apiKey:     apiKey,
-		httpClient: client,
-	}, nil
+		httpClient: copyClient(client),
+	}
+	t.client.Store(copyClient(client))
+	t.compat.Store(&Compatibility{})
+
+	return t, nil
 }
 
 // Do executes an API request
 func (t *Transport) Do(ctx context.Context, req *Request) (*types.Result, error) {
-	url := t.urlBuilder.BuildPath(req.Path)
+	compat := t.compat.Load()
+	httpReq, err := t.newHTTPRequest(ctx, compat, req)
+	if err != nil {
+		return nil, err
+	}
 
-	body, err := json.Marshal(req.Body)
+	client := t.client.Load()
+	if len(req.Middleware) > 0 {
+		client = withMiddleware(client, req.Middleware)
+	}
+
+	logger := t.debugLogger.Load()
+	var timings func() timing.Timings
+	if logger != nil {
+		var traced context.Context
+		traced, timings = timing.Trace(httpReq.Context())
+		httpReq = httpReq.WithContext(traced)
+	}
+
+	var wrote atomic.Bool
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) { wrote.Store(true) },
+	}))
+
+	resp, err := client.Do(httpReq)
+	if timings != nil {
+		logger.Printf("[DEBUG] %s %s: %s", req.Method, req.Path, timings())
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, &types.TransportError{Op: "request failed", Err: err, NotSent: !wrote.Load()}
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := t.readBody(resp)
+	if err != nil {
+		return nil, &types.TransportError{Op: "failed to read response body", Err: err}
+	}
+
+	if logger != nil {
+		validateResponse(logger, req, resp, respBody)
+	}
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		err := errorResponse(resp, respBody)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			err = &types.RateLimitError{
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+				Err:        err,
+			}
+		}
+		if req.Optional && IsMissingEndpoint(resp.StatusCode) {
+			err = &types.EndpointNotSupportedError{Endpoint: req.Path, StatusCode: resp.StatusCode, Err: err}
+		}
+		return nil, err
+	}
+
+	if t.strict.Load() {
+		if unknown := schema.Envelope.UnknownFields(respBody); len(unknown) > 0 {
+			err := fmt.Errorf("%w: %s", types.ErrUnknownResponseField, strings.Join(unknown, ", "))
+			if logger := t.debugLogger.Load(); logger != nil {
+				logger.Printf("[DEBUG] %s %s: strict decode: %v", req.Method, req.Path, err)
+			}
+			return nil, types.NewUnexpectedResponseError("strict decode", resp.StatusCode, respBody, err)
+		}
+	}
+
+	if req.Data != nil {
+		return decodeData(req.Data, resp.StatusCode, respBody)
+	}
+
+	// Parse success response
+	var result types.Result
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, types.NewUnexpectedResponseError("failed to parse response", resp.StatusCode, respBody, err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, req.Method, url, bytes.NewReader(body))
+	result.ServerTime, result.Flags = envelopeMeta(respBody)
+
+	if compat.Format != FormatFlat {
+		if err := unwrapEnvelope(&result, resp.StatusCode, compat.Format); err != nil {
+			return nil, err
+		}
+	}
+
+	return &result, nil
+}
+
+// NewHTTPRequest builds the HTTP request Do would send for req, with the
+// body encoded and default headers and mutators applied
+func (t *Transport) NewHTTPRequest(ctx context.Context, req *Request) (*http.Request, error) {
+	return t.newHTTPRequest(ctx, t.compat.Load(), req)
+}
+
+// newHTTPRequest builds the HTTP request for req under compat
+func (t *Transport) newHTTPRequest(ctx context.Context, compat *Compatibility, req *Request) (*http.Request, error) {
+	url := t.buildURL(compat, req.Path)
+
+	body, err := t.requestBody(compat, req)
 	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, url, body)
+	if err != nil {
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
+		}
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	if req.BodyStream != nil && req.ContentLength > 0 {
+		httpReq.ContentLength = req.ContentLength
+	}
+
 	// Set default headers
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("X-Server-API-Key", t.apiKey)
+	apiKey := t.apiKey
+	if req.APIKey != "" {
+		apiKey = req.APIKey
+	}
+	if key, ok := types.APIKeyFromContext(ctx); ok {
+		apiKey = key
+	}
+	httpReq.Header.Set("X-Server-API-Key", apiKey)
 
 	// Set custom headers
 	for k, v := range req.Headers {
 		httpReq.Header.Set(k, v)
 	}
 
-	// Apply middleware chain without modifying the client
-	client := t.httpClient
-	if len(t.middleware) > 0 {
-		// Create a copy of the client to avoid race conditions
-		clientCopy := *t.httpClient
-		rt := t.httpClient.Transport
-		if rt == nil {
-			rt = http.DefaultTransport
+	for _, mutate := range req.Mutators {
+		mutate(httpReq)
+	}
+	return httpReq, nil
+}
+
+// MiddlewareChain returns the middleware req passes through, outermost first
+func (t *Transport) MiddlewareChain(req *Request) []middleware.Middleware {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	chain := make([]middleware.Middleware, 0, len(req.Middleware)+len(t.middleware))
+	chain = append(chain, req.Middleware...)
+	return append(chain, t.middleware...)
+}
+
+// buildURL returns the full URL for path, honoring the compatibility path prefix
+func (t *Transport) buildURL(compat *Compatibility, path string) string {
+	prefix := compat.PathPrefix
+	if prefix == "" {
+		prefix = utils.DefaultAPIPrefix
+	}
+	return t.urlBuilder.BuildAPIPath(prefix, path)
+}
+
+// SetCompatibility sets how requests and responses are adapted to the server
+func (t *Transport) SetCompatibility(compat Compatibility) {
+	t.compat.Store(&compat)
+}
+
+// renameFields renames the top-level fields of a JSON object
+func renameFields(body []byte, aliases map[string]string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		// Not an object; nothing to rename
+		return body, nil
+	}
+
+	for from, to := range aliases {
+		if v, ok := fields[from]; ok {
+			delete(fields, from)
+			fields[to] = v
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// unwrapEnvelope lifts results out of Postal's {"status", "time", "flags", "data"}
+// envelope. Enveloped errors are reported with HTTP 200, so they are converted
+// into PostalErrors here.
+func unwrapEnvelope(result *types.Result, statusCode int, format ResponseFormat) error {
+	if result.Status != "success" {
+		code, _ := result.Data["code"].(string)
+		message, _ := result.Data["message"].(string)
+		if code != "" {
+			return types.NewPostalError(code, message, statusCode).WithDetails(errorDetails(result.Data))
+		}
+	}
+
+	if result.MessageID == "" || format == FormatEnvelope {
+		if id, ok := result.Data["message_id"].(string); ok {
+			result.MessageID = id
+		}
+	}
+
+	return nil
+}
+
+// errorDetails returns the fields of an enveloped error other than its
+// code and message, such as the failing field or a limit
+func errorDetails(data map[string]interface{}) map[string]interface{} {
+	details := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "code" && k != "message" {
+			details[k] = v
+		}
+	}
+	return details
+}
+
+// errorResponse converts an error response into a GatewayError for proxy
+// pages, a PostalError for API errors, or an UnexpectedResponseError
+func errorResponse(resp *http.Response, body []byte) error {
+	contentType := resp.Header.Get("Content-Type")
+	if isHTML(contentType) {
+		return types.NewGatewayError(resp.StatusCode, contentType, body)
+	}
+
+	var postalErr types.PostalError
+	if err := json.Unmarshal(body, &postalErr); err != nil {
+		if isGatewayStatus(resp.StatusCode) {
+			return types.NewGatewayError(resp.StatusCode, contentType, body)
 		}
-		clientCopy.Transport = middleware.Chain(t.middleware...)(rt)
-		client = &clientCopy
+		return types.NewUnexpectedResponseError("failed to parse error response", resp.StatusCode, body, err)
+	}
+	postalErr.StatusCode = resp.StatusCode
+	return &postalErr
+}
+
+// IsMissingEndpoint reports whether a status code means the server has no
+// such endpoint, as opposed to rejecting the request sent to it
+func IsMissingEndpoint(status int) bool {
+	switch status {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date, returning zero if it is absent, malformed or in the past
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// decodeData decodes the data field of an enveloped response into v.
+// Enveloped errors are converted into PostalErrors as in unwrapEnvelope.
+func decodeData(v interface{}, statusCode int, body []byte) (*types.Result, error) {
+	var envelope struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, types.NewUnexpectedResponseError("failed to parse response", statusCode, body, err)
+	}
+
+	if envelope.Status != "success" {
+		var failure map[string]interface{}
+		if json.Unmarshal(envelope.Data, &failure) == nil {
+			if code, _ := failure["code"].(string); code != "" {
+				message, _ := failure["message"].(string)
+				return nil, types.NewPostalError(code, message, statusCode).WithDetails(errorDetails(failure))
+			}
+		}
+		return nil, types.NewUnexpectedResponseError("unexpected response status", statusCode, body,
+			fmt.Errorf("%w: status %q", types.ErrUnexpectedResponse, envelope.Status))
+	}
+
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		return nil, types.NewUnexpectedResponseError("failed to parse response data", statusCode, body, err)
+	}
+	result := &types.Result{Status: envelope.Status}
+	result.ServerTime, result.Flags = envelopeMeta(body)
+	return result, nil
+}
+
+// envelopeMeta returns the envelope's time and flags fields. They are only
+// informational, so malformed values are ignored rather than failing the
+// response.
+func envelopeMeta(body []byte) (float64, map[string]interface{}) {
+	var meta struct {
+		Time  interface{} `json:"time"`
+		Flags interface{} `json:"flags"`
+	}
+	if json.Unmarshal(body, &meta) != nil {
+		return 0, nil
+	}
+	serverTime, _ := meta.Time.(float64)
+	flags, _ := meta.Flags.(map[string]interface{})
+	return serverTime, flags
+}
+
+// SetDebugLogger enables debug checks, such as response schema validation,
+// reporting to logger. A nil logger disables them.
+func (t *Transport) SetDebugLogger(logger *log.Logger) {
+	t.debugLogger.Store(logger)
+}
+
+// validateResponse logs any divergence between a JSON response body and the
+// embedded Postal response schema
+func validateResponse(logger *log.Logger, req *Request, resp *http.Response, body []byte) {
+	if isHTML(resp.Header.Get("Content-Type")) {
+		return
+	}
+
+	s := schema.Envelope
+	if resp.StatusCode >= 400 {
+		s = schema.Error
 	}
 
+	for _, problem := range s.Validate(body) {
+		logger.Printf("[DEBUG] %s %s: response schema mismatch [%d]: %s", req.Method, req.Path, resp.StatusCode, problem)
+	}
+}
+
+// isHTML reports whether an error response is an HTML page rather than a
+// Postal API response
+func isHTML(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/html"
+}
+
+// isGatewayStatus reports whether the status code is typically produced by a
+// reverse proxy when the upstream is unreachable
+func isGatewayStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Probe sends req without a body and returns the response status code,
+// without interpreting the body. It is used with a non-mutating method to
+// detect supported endpoints under the request's credentials and headers.
+func (t *Transport) Probe(ctx context.Context, req *Request) (int, error) {
+	httpReq, err := t.newHTTPRequest(ctx, t.compat.Load(), req)
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Body = http.NoBody
+	httpReq.GetBody = nil
+	httpReq.ContentLength = 0
+	httpReq.Header.Del("Content-Type")
+
+	client := t.client.Load()
+	if len(req.Middleware) > 0 {
+		client = withMiddleware(client, req.Middleware)
+	}
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return 0, &types.TransportError{Op: "request failed", Err: err}
 	}
 	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
 
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, nil
+}
+
+// Clone returns a transport with the same settings and middleware that sends
+// through client. Passing a client that shares the original's RoundTripper
+// shares the connection pool while keeping middleware independent.
+func (t *Transport) Clone(client *http.Client) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clone := &Transport{
+		urlBuilder: t.urlBuilder,
+		apiKey:     t.apiKey,
+		httpClient: copyClient(client),
+		middleware: append([]middleware.Middleware(nil), t.middleware...),
+	}
+	clone.compat.Store(t.compat.Load())
+	clone.debugLogger.Store(t.debugLogger.Load())
+	clone.maxResponseSize.Store(t.maxResponseSize.Load())
+	clone.strict.Store(t.strict.Load())
+	clone.rebuild()
+
+	return clone
+}
+
+// AddMiddleware adds middleware to the transport
+func (t *Transport) AddMiddleware(m middleware.Middleware) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.middleware = append(t.middleware, m)
+	t.rebuild()
+}
+
+// requestBody returns the streamed body if one is set, otherwise the
+// marshalled Body with field aliases applied
+func (t *Transport) requestBody(compat *Compatibility, req *Request) (io.Reader, error) {
+	if req.BodyStream != nil {
+		return req.BodyStream()
+	}
+
+	body, err := json.Marshal(req.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
+	if len(compat.FieldAliases) > 0 {
+		if body, err = renameFields(body, compat.FieldAliases); err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+	return bytes.NewReader(body), nil
+}
 
-	// Handle error responses
-	if resp.StatusCode >= 400 {
-		var postalErr types.PostalError
-		if err := json.Unmarshal(respBody, &postalErr); err != nil {
-			return nil, fmt.Errorf("failed to parse error response: %w", err)
+// DefaultMaxRedirects is how many redirects are followed when the limit is
+// zero, the same as net/http's default policy
+const DefaultMaxRedirects = 10
+
+// SetMaxRedirects limits how many redirects are followed. Zero applies
+// DefaultMaxRedirects; a negative value disables redirects.
+func (t *Transport) SetMaxRedirects(maxRedirects int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if maxRedirects == 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	if maxRedirects < 0 {
+		t.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return fmt.Errorf("%w: redirects are disabled, stopped at %s", types.ErrTooManyRedirects, req.URL.Redacted())
+		}
+	} else {
+		t.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("%w: stopped at %s after %d", types.ErrTooManyRedirects, req.URL.Redacted(), maxRedirects)
+			}
+			return nil
 		}
-		postalErr.StatusCode = resp.StatusCode
-		return nil, &postalErr
 	}
+	t.rebuild()
+}
 
-	// Parse success response
-	var result types.Result
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+// SetStrictDecode rejects successful responses containing fields the client
+// does not know, so drift between the client and server is caught early.
+// Error responses are not affected.
+func (t *Transport) SetStrictDecode(strict bool) {
+	t.strict.Store(strict)
+}
+
+// SetMaxResponseSize caps the number of bytes read from a response body.
+// Zero or a negative value removes the limit.
+func (t *Transport) SetMaxResponseSize(maxBytes int64) {
+	t.maxResponseSize.Store(maxBytes)
+}
+
+// readBody reads the response body, rejecting bodies above the size limit
+func (t *Transport) readBody(resp *http.Response) ([]byte, error) {
+	limit := t.maxResponseSize.Load()
+	if limit <= 0 {
+		return io.ReadAll(resp.Body)
 	}
 
-	return &result, nil
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d", types.ErrResponseTooLarge, resp.ContentLength, limit)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: body exceeds limit of %d bytes", types.ErrResponseTooLarge, limit)
+	}
+	return body, nil
 }
 
-// AddMiddleware adds middleware to the transport
-func (t *Transport) AddMiddleware(m middleware.Middleware) {
-	t.middleware = append(t.middleware, m)
+// SetTimeout updates the request timeout of the underlying HTTP client
+func (t *Transport) SetTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.httpClient.Timeout = timeout
+	t.rebuild()
+}
+
+// HTTPClient returns the client requests are sent with: a copy of the one
+// the transport was created with, with settings applied and middleware
+// composed in
+func (t *Transport) HTTPClient() *http.Client {
+	return t.client.Load()
+}
+
+// rebuild composes the middleware chain into a new copy of the HTTP client,
+// so later settings never change a client requests are in flight on.
+// Callers must hold t.mu.
+func (t *Transport) rebuild() {
+	if len(t.middleware) == 0 {
+		t.client.Store(copyClient(t.httpClient))
+		return
+	}
+
+	t.client.Store(withMiddleware(t.httpClient, t.middleware))
+}
+
+// copyClient returns a shallow copy of client, which shares its
+// RoundTripper and so its connection pool
+func copyClient(client *http.Client) *http.Client {
+	c := *client
+	return &c
+}
+
+// withMiddleware returns a copy of client with the middleware chain wrapped
+// around its transport
+func withMiddleware(client *http.Client, mws []middleware.Middleware) *http.Client {
+	clientCopy := *client
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	clientCopy.Transport = middleware.Chain(mws...)(rt)
+	return &clientCopy
 }
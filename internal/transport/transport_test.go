@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sachin-duhan/postal-go/common/types"
 )
@@ -279,6 +283,297 @@ func TestTransportDo(t *testing.T) {
 	}
 }
 
+func TestTransportUnexpectedResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte("not json at all"))
+	}))
+	defer ts.Close()
+
+	transport, err := NewTransport(ts.URL, "test-key", &http.Client{})
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+
+	_, err = transport.Do(context.Background(), &Request{Method: http.MethodPost, Path: "test", Body: map[string]string{}})
+
+	var unexpected *types.UnexpectedResponseError
+	if !errors.As(err, &unexpected) {
+		t.Fatalf("expected *types.UnexpectedResponseError, got %T: %v", err, err)
+	}
+	if unexpected.StatusCode != 200 {
+		t.Errorf("StatusCode = %d, want 200", unexpected.StatusCode)
+	}
+	if unexpected.Body != "not json at all" {
+		t.Errorf("Body = %q, want %q", unexpected.Body, "not json at all")
+	}
+}
+
+func TestTransportGatewayErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		contentType string
+		body        string
+		retryable   bool
+	}{
+		{
+			name:        "HTML 502 page",
+			statusCode:  502,
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><body><h1>502 Bad Gateway</h1></body></html>",
+			retryable:   true,
+		},
+		{
+			name:        "plain text 504",
+			statusCode:  504,
+			contentType: "text/plain",
+			body:        "upstream request timeout",
+			retryable:   true,
+		},
+		{
+			name:        "HTML 403 page",
+			statusCode:  403,
+			contentType: "text/html",
+			body:        "<html><body>Forbidden</body></html>",
+			retryable:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			transport, err := NewTransport(ts.URL, "test-key", &http.Client{})
+			if err != nil {
+				t.Fatalf("failed to create transport: %v", err)
+			}
+
+			_, err = transport.Do(context.Background(), &Request{Method: http.MethodPost, Path: "test", Body: map[string]string{}})
+
+			var gatewayErr *types.GatewayError
+			if !errors.As(err, &gatewayErr) {
+				t.Fatalf("expected *types.GatewayError, got %T: %v", err, err)
+			}
+			if gatewayErr.StatusCode != tt.statusCode {
+				t.Errorf("StatusCode = %d, want %d", gatewayErr.StatusCode, tt.statusCode)
+			}
+			if gatewayErr.Body != tt.body {
+				t.Errorf("Body = %q, want %q", gatewayErr.Body, tt.body)
+			}
+			if got := types.IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestTransportRedirectLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRedirects int
+		redirects    int
+		wantErr      bool
+	}{
+		{name: "redirects disabled", maxRedirects: -1, redirects: 1, wantErr: true},
+		{name: "within limit", maxRedirects: 2, redirects: 2, wantErr: false},
+		{name: "over limit", maxRedirects: 2, redirects: 3, wantErr: true},
+		{name: "default limit", maxRedirects: 0, redirects: 10, wantErr: false},
+		{name: "over default limit", maxRedirects: 0, redirects: 11, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				if hits <= tt.redirects {
+					http.Redirect(w, r, r.URL.Path, http.StatusTemporaryRedirect)
+					return
+				}
+				w.Write([]byte(`{"message_id": "12345", "status": "success"}`))
+			}))
+			defer ts.Close()
+
+			transport, err := NewTransport(ts.URL, "test-key", &http.Client{})
+			if err != nil {
+				t.Fatalf("failed to create transport: %v", err)
+			}
+			transport.SetMaxRedirects(tt.maxRedirects)
+
+			_, err = transport.Do(context.Background(), &Request{Method: http.MethodPost, Path: "test", Body: map[string]string{}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Transport.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, types.ErrTooManyRedirects) {
+				t.Errorf("expected ErrTooManyRedirects, got %v", err)
+			}
+			if types.IsRetryable(err) {
+				t.Error("redirect limit errors should not be retryable")
+			}
+		})
+	}
+}
+
+func TestTransportSettingsCopyClient(t *testing.T) {
+	client := &http.Client{}
+	transport, err := NewTransport("https://postal.example.com", "test-key", client)
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	before := transport.client.Load()
+
+	transport.SetTimeout(5 * time.Second)
+	transport.SetMaxRedirects(2)
+
+	if client.Timeout != 0 || client.CheckRedirect != nil {
+		t.Errorf("caller's client was modified: Timeout = %v, CheckRedirect set = %v", client.Timeout, client.CheckRedirect != nil)
+	}
+	if before.Timeout != 0 {
+		t.Error("settings changed the client in use by earlier requests")
+	}
+	if got := transport.client.Load(); got.Timeout != 5*time.Second || got.CheckRedirect == nil {
+		t.Errorf("transport client Timeout = %v, CheckRedirect set = %v", got.Timeout, got.CheckRedirect != nil)
+	}
+}
+
+func TestTransportMaxResponseSize(t *testing.T) {
+	body := `{"message_id": "12345", "status": "success"}`
+
+	tests := []struct {
+		name    string
+		limit   int64
+		chunked bool
+		wantErr bool
+	}{
+		{name: "unlimited", limit: 0, wantErr: false},
+		{name: "within limit", limit: int64(len(body)), wantErr: false},
+		{name: "content length over limit", limit: 10, wantErr: true},
+		{name: "streamed body over limit", limit: 10, chunked: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.chunked {
+					w.Write([]byte(body[:5]))
+					w.(http.Flusher).Flush()
+					w.Write([]byte(body[5:]))
+					return
+				}
+				w.Write([]byte(body))
+			}))
+			defer ts.Close()
+
+			transport, err := NewTransport(ts.URL, "test-key", &http.Client{})
+			if err != nil {
+				t.Fatalf("failed to create transport: %v", err)
+			}
+			transport.SetMaxResponseSize(tt.limit)
+
+			result, err := transport.Do(context.Background(), &Request{Method: http.MethodPost, Path: "test", Body: map[string]string{}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Transport.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if result.MessageID != "12345" {
+					t.Errorf("MessageID = %q, want %q", result.MessageID, "12345")
+				}
+				return
+			}
+			if !errors.Is(err, types.ErrResponseTooLarge) {
+				t.Errorf("expected ErrResponseTooLarge, got %v", err)
+			}
+			if types.IsRetryable(err) {
+				t.Error("oversized response errors should not be retryable")
+			}
+		})
+	}
+}
+
+func TestTransportDebugSchemaValidation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12345", "status": "success", "time": "slow", "extra": true}`))
+	}))
+	defer ts.Close()
+
+	transport, err := NewTransport(ts.URL, "test-key", &http.Client{})
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+
+	var buf bytes.Buffer
+	transport.SetDebugLogger(log.New(&buf, "", 0))
+
+	req := &Request{Method: http.MethodPost, Path: "send/message", Body: map[string]string{}}
+	if _, err := transport.Do(context.Background(), req); err != nil {
+		t.Fatalf("Transport.Do() error = %v", err)
+	}
+
+	logs := buf.String()
+	for _, want := range []string{"$.time: expected number, got string", `unknown field "extra"`} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("debug log %q does not contain %q", logs, want)
+		}
+	}
+
+	buf.Reset()
+	transport.SetDebugLogger(nil)
+	if _, err := transport.Do(context.Background(), req); err != nil {
+		t.Fatalf("Transport.Do() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no debug output when disabled, got %q", buf.String())
+	}
+}
+
+func TestTransportStrictDecode(t *testing.T) {
+	body := `{"message_id": "12345", "status": "success", "time": 0.1, "extra": true}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	transport, err := NewTransport(ts.URL, "test-key", &http.Client{})
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	req := &Request{Method: http.MethodPost, Path: "send/message", Body: map[string]string{}}
+
+	if _, err := transport.Do(context.Background(), req); err != nil {
+		t.Fatalf("Transport.Do() without strict decode error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	transport.SetDebugLogger(log.New(&buf, "", 0))
+	transport.SetStrictDecode(true)
+	_, err = transport.Do(context.Background(), req)
+	if !errors.Is(err, types.ErrUnknownResponseField) || !strings.Contains(err.Error(), "$.extra") {
+		t.Fatalf("Transport.Do() error = %v, want unknown field $.extra", err)
+	}
+	if types.IsRetryable(err) {
+		t.Error("strict decode error is retryable")
+	}
+	if !strings.Contains(buf.String(), "strict decode") {
+		t.Errorf("debug log %q does not mention strict decode", buf.String())
+	}
+
+	body = `{"message_id": "12345", "status": "success", "time": 0.1, "flags": {}}`
+	if _, err := transport.Do(context.Background(), req); err != nil {
+		t.Errorf("Transport.Do() with known fields error = %v", err)
+	}
+}
+
 func TestTransportRequestBody(t *testing.T) {
 	// Test that request body is properly marshaled
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -420,6 +715,58 @@ func TestTransportAddMiddleware(t *testing.T) {
 	}
 }
 
+func TestTransportMiddlewareComposedOnce(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		json.NewEncoder(w).Encode(types.Result{Status: "success"})
+	}))
+	defer ts.Close()
+
+	client := &http.Client{}
+	transport, err := NewTransport(ts.URL, "test-key", client)
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+
+	wraps := 0
+	calls := 0
+	transport.AddMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		wraps++
+		return &mockRoundTripper{rt: next}
+	})
+	transport.AddMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		wraps++
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return next.RoundTrip(r)
+		})
+	})
+	wraps = 0
+
+	req := &Request{Method: http.MethodPost, Path: "test", Body: map[string]string{"test": "data"}}
+	for i := 0; i < 5; i++ {
+		if _, err := transport.Do(context.Background(), req); err != nil {
+			t.Fatalf("Transport.Do() error = %v", err)
+		}
+	}
+
+	if wraps != 0 {
+		t.Errorf("middleware chain rebuilt %d times during requests, want 0", wraps)
+	}
+	if calls != 5 {
+		t.Errorf("middleware invoked %d times, want 5", calls)
+	}
+	if client.Transport != nil {
+		t.Error("AddMiddleware() modified the caller's http.Client")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
 func BenchmarkTransportDo(b *testing.B) {
 	// Create test server
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -460,6 +807,42 @@ func BenchmarkTransportDo(b *testing.B) {
 	}
 }
 
+func BenchmarkTransportDoWithMiddleware(b *testing.B) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "benchmark-msg", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	req := &Request{
+		Method: http.MethodPost,
+		Path:   "send/message",
+		Body:   map[string]string{"subject": "Benchmark"},
+	}
+
+	for _, n := range []int{0, 8} {
+		transport, err := NewTransport(ts.URL, "test-key", &http.Client{})
+		if err != nil {
+			b.Fatalf("failed to create transport: %v", err)
+		}
+		for i := 0; i < n; i++ {
+			transport.AddMiddleware(func(next http.RoundTripper) http.RoundTripper {
+				return &mockRoundTripper{rt: next}
+			})
+		}
+
+		b.Run(fmt.Sprintf("middleware=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				if _, err := transport.Do(ctx, req); err != nil {
+					b.Fatalf("Transport.Do() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkTransportRequestMarshaling(b *testing.B) {
 	req := &Request{
 		Method: http.MethodPost,
@@ -486,4 +869,121 @@ func BenchmarkTransportRequestMarshaling(b *testing.B) {
 			b.Fatalf("json.Marshal() error = %v", err)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{" 5 ", 5 * time.Second},
+		{"-1", 0},
+		{"Mon, 01 Jan 2024 12:01:30 GMT", 90 * time.Second},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTransportRateLimitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"code": "rate_limited", "message": "Slow down"}`))
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(server.URL, "test-key", &http.Client{})
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+	_, err = transport.Do(context.Background(), &Request{Method: http.MethodPost, Path: "send/message", Body: map[string]string{}})
+
+	var rateLimitErr *types.RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 7*time.Second {
+		t.Fatalf("Do() error = %v, want RateLimitError with RetryAfter 7s", err)
+	}
+	if !types.IsRetryable(err) || !errors.Is(err, types.ErrRateLimit) {
+		t.Errorf("Do() error = %v, want a retryable rate limit error", err)
+	}
+}
+
+func TestTransportEnvelopeMeta(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		data      interface{}
+		wantTime  float64
+		wantFlags map[string]interface{}
+	}{
+		{
+			name:      "result",
+			body:      `{"status": "success", "time": 0.25, "flags": {"throttled": true}, "data": {"message_id": "abc"}}`,
+			wantTime:  0.25,
+			wantFlags: map[string]interface{}{"throttled": true},
+		},
+		{
+			name:      "typed data",
+			body:      `{"status": "success", "time": 0.5, "flags": {}, "data": {"id": 1}}`,
+			data:      &map[string]interface{}{},
+			wantTime:  0.5,
+			wantFlags: map[string]interface{}{},
+		},
+		{
+			name: "malformed meta ignored",
+			body: `{"status": "success", "time": "slow", "flags": [], "data": {"message_id": "abc"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			transport, err := NewTransport(server.URL, "test-key", &http.Client{})
+			if err != nil {
+				t.Fatalf("NewTransport() error = %v", err)
+			}
+			result, err := transport.Do(context.Background(), &Request{Method: http.MethodPost, Path: "messages/message", Body: map[string]string{}, Data: tt.data})
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if result.ServerTime != tt.wantTime || fmt.Sprint(result.Flags) != fmt.Sprint(tt.wantFlags) {
+				t.Errorf("ServerTime, Flags = %v, %v, want %v, %v", result.ServerTime, result.Flags, tt.wantTime, tt.wantFlags)
+			}
+		})
+	}
+}
+
+func TestTransportEnvelopeErrorDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "parameter-error", "time": 0.01, "flags": {}, "data": {"code": "TooManyToAddresses", "message": "Too many addresses", "field": "to", "limit": 50}}`))
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(server.URL, "test-key", &http.Client{})
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+	for _, data := range []interface{}{nil, &map[string]interface{}{}} {
+		_, err = transport.Do(context.Background(), &Request{Method: http.MethodPost, Path: "send/message", Body: map[string]string{}, Data: data})
+		var postalErr *types.PostalError
+		if !errors.As(err, &postalErr) {
+			t.Fatalf("Do() error = %v, want PostalError", err)
+		}
+		if limit, ok := postalErr.Limit(); postalErr.Field() != "to" || !ok || limit != 50 {
+			t.Errorf("Field(), Limit() = %q, %d, want to, 50", postalErr.Field(), limit)
+		}
+		if _, ok := postalErr.Details["code"]; ok {
+			t.Errorf("Details = %v, should not repeat the code", postalErr.Details)
+		}
+	}
+}
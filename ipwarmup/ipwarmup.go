@@ -0,0 +1,82 @@
+// Package ipwarmup computes daily send limits for warming up a new
+// sending IP address
+package ipwarmup
+
+import (
+	"math"
+	"time"
+)
+
+// Schedule ramps the daily send limit from Start following Ramp, where
+// Ramp[0] is the limit on the first day. After the ramp ends, the last
+// limit applies indefinitely; before Start the limit is zero.
+type Schedule struct {
+	Start time.Time
+	Ramp  []int
+}
+
+// New creates a Schedule starting on the day of start in its location
+func New(start time.Time, ramp []int) *Schedule {
+	y, m, d := start.Date()
+	return &Schedule{Start: time.Date(y, m, d, 0, 0, 0, 0, start.Location()), Ramp: ramp}
+}
+
+// Day returns the zero-based day of the schedule at t, or -1 before Start
+func (s *Schedule) Day(t time.Time) int {
+	t = t.In(s.Start.Location())
+	if t.Before(s.Start) {
+		return -1
+	}
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	// Round to absorb daylight saving shifts
+	return int(math.Round(midnight.Sub(s.Start).Hours() / 24))
+}
+
+// LimitAt returns the daily send limit in effect at t
+func (s *Schedule) LimitAt(t time.Time) int {
+	day := s.Day(t)
+	if day < 0 || len(s.Ramp) == 0 {
+		return 0
+	}
+	if day >= len(s.Ramp) {
+		return s.Ramp[len(s.Ramp)-1]
+	}
+	return s.Ramp[day]
+}
+
+// TodayLimit returns the daily send limit in effect now
+func (s *Schedule) TodayLimit() int {
+	return s.LimitAt(time.Now())
+}
+
+// Complete returns true once the ramp has reached its final limit
+func (s *Schedule) Complete(t time.Time) bool {
+	return s.Day(t) >= len(s.Ramp)-1
+}
+
+// Exponential builds a ramp starting at initial and multiplying by factor
+// each day until target is reached
+func Exponential(initial int, factor float64, target int) []int {
+	if initial < 1 || factor <= 1 {
+		return []int{target}
+	}
+	var ramp []int
+	for limit := float64(initial); int(limit) < target; limit *= factor {
+		ramp = append(ramp, int(limit))
+	}
+	return append(ramp, target)
+}
+
+// Linear builds a ramp starting at initial and adding step each day until
+// target is reached
+func Linear(initial, step, target int) []int {
+	if step < 1 {
+		return []int{target}
+	}
+	var ramp []int
+	for limit := initial; limit < target; limit += step {
+		ramp = append(ramp, limit)
+	}
+	return append(ramp, target)
+}
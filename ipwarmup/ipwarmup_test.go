@@ -0,0 +1,73 @@
+package ipwarmup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleLimitAt(t *testing.T) {
+	start := time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC)
+	s := New(start, []int{50, 100, 500})
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int
+	}{
+		{name: "before start", at: time.Date(2024, 2, 29, 23, 59, 0, 0, time.UTC), want: 0},
+		{name: "first day morning", at: time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC), want: 50},
+		{name: "second day", at: time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC), want: 100},
+		{name: "last day", at: time.Date(2024, 3, 3, 23, 59, 0, 0, time.UTC), want: 500},
+		{name: "after ramp", at: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.LimitAt(tt.at); got != tt.want {
+				t.Errorf("LimitAt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if s.Complete(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Complete() = true during the ramp")
+	}
+	if !s.Complete(time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Complete() = false on the final day")
+	}
+}
+
+func TestScheduleDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	s := New(time.Date(2024, 3, 9, 12, 0, 0, 0, loc), []int{1, 2, 3})
+
+	// Clocks spring forward on March 10, making that day 23 hours long
+	if got := s.LimitAt(time.Date(2024, 3, 11, 0, 30, 0, 0, loc)); got != 3 {
+		t.Errorf("LimitAt() = %d, want 3", got)
+	}
+}
+
+func TestRamps(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []int
+		want []int
+	}{
+		{name: "exponential", got: Exponential(50, 2, 500), want: []int{50, 100, 200, 400, 500}},
+		{name: "exponential invalid factor", got: Exponential(50, 1, 500), want: []int{500}},
+		{name: "linear", got: Linear(100, 150, 500), want: []int{100, 250, 400, 500}},
+		{name: "linear invalid step", got: Linear(100, 0, 500), want: []int{500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("ramp = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
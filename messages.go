@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// messageDetailsRequest is the body of a messages/message request
+type messageDetailsRequest struct {
+	ID         int                      `json:"id"`
+	Expansions []types.MessageExpansion `json:"_expansions,omitempty"`
+}
+
+// GetMessage implements Client
+func (c *clientImpl) GetMessage(ctx context.Context, id int, expansions ...types.MessageExpansion) (*types.MessageDetails, error) {
+	var details types.MessageDetails
+	_, err := c.query(ctx, &transport.Request{
+		Method:   http.MethodPost,
+		Path:     string(types.CapabilityMessageDetails),
+		Body:     messageDetailsRequest{ID: id, Expansions: expansions},
+		Data:     &details,
+		Optional: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// messageIDRequest is the body of requests that take only a message ID
+type messageIDRequest struct {
+	ID int `json:"id"`
+}
+
+// GetDeliveries implements Client
+func (c *clientImpl) GetDeliveries(ctx context.Context, messageID int) ([]types.Delivery, error) {
+	var deliveries []types.Delivery
+	_, err := c.query(ctx, &transport.Request{
+		Method:   http.MethodPost,
+		Path:     string(types.CapabilityDeliveries),
+		Body:     messageIDRequest{ID: messageID},
+		Data:     &deliveries,
+		Optional: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
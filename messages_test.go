@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+const messageDetailsResponse = `{
+	"status": "success",
+	"time": 0.01,
+	"flags": {},
+	"data": {
+		"id": 123,
+		"token": "AbCdEf",
+		"status": {"status": "Sent", "last_delivery_attempt": 1700000000.5, "held": false, "hold_expiry": null},
+		"details": {"rcpt_to": "recipient@example.com", "mail_from": "sender@example.com", "subject": "Hello", "message_id": "abc@example.com", "timestamp": 1699999999.0, "direction": "outgoing", "size": "812", "bounce": false, "tag": "welcome"},
+		"plain_body": "Hi there",
+		"headers": {"x-campaign": ["spring"], "received": ["a", "b"]},
+		"attachments": [{"filename": "report.pdf", "content_type": "application/pdf", "size": 1024, "hash": "d41d8"}],
+		"raw_message": "U3ViamVjdDogSGVsbG8NCg0KSGkgdGhlcmU="
+	}
+}`
+
+func TestGetMessage(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(messageDetailsResponse))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg, err := c.GetMessage(context.Background(), 123, types.ExpandStatus, types.ExpandDetails, types.ExpandHeaders)
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+
+	if path != "/api/v1/messages/message" {
+		t.Errorf("path = %q, want /api/v1/messages/message", path)
+	}
+	if body["id"] != float64(123) {
+		t.Errorf("request id = %v, want 123", body["id"])
+	}
+	if exp, _ := body["_expansions"].([]interface{}); len(exp) != 3 || exp[0] != "status" {
+		t.Errorf("request _expansions = %v, want [status details headers]", body["_expansions"])
+	}
+
+	if msg.ID != 123 || msg.Token != "AbCdEf" {
+		t.Errorf("ID, Token = %d, %q", msg.ID, msg.Token)
+	}
+	if msg.Status == nil || msg.Status.Status != "Sent" {
+		t.Fatalf("Status = %+v, want Sent", msg.Status)
+	}
+	if got := msg.Status.LastDeliveryAttemptTime(); !got.Equal(time.Unix(1700000000, 5e8)) {
+		t.Errorf("LastDeliveryAttemptTime() = %v", got)
+	}
+	if msg.Details == nil || msg.Details.Tag != "welcome" || msg.Details.RcptTo != "recipient@example.com" {
+		t.Errorf("Details = %+v", msg.Details)
+	}
+	if got := msg.Header("X-Campaign"); got != "spring" {
+		t.Errorf("Header(X-Campaign) = %q, want spring", got)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "report.pdf" {
+		t.Errorf("Attachments = %+v", msg.Attachments)
+	}
+	if raw, err := msg.Raw(); err != nil || string(raw) != "Subject: Hello\r\n\r\nHi there" {
+		t.Errorf("Raw() = %q, %v", raw, err)
+	}
+}
+
+func TestGetMessageErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "error", "time": 0.01, "flags": {}, "data": {"code": "MessageNotFound", "message": "No message found matching provided ID", "id": 999}}`))
+	}))
+	defer ts.Close()
+
+	budget := &ErrorBudget{Window: time.Hour}
+	c, err := NewClient(ts.URL, "test-key", WithErrorBudget(budget))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = c.GetMessage(context.Background(), 999)
+	postalErr, ok := err.(*types.PostalError)
+	if !ok || postalErr.Code != "MessageNotFound" {
+		t.Fatalf("GetMessage() error = %v, want MessageNotFound", err)
+	}
+
+	// Lookups are not sends, so they neither count against the error budget
+	// nor use up a tenant's quota
+	if len(budget.sends) != 0 {
+		t.Errorf("error budget recorded %d sends for a lookup, want 0", len(budget.sends))
+	}
+	view := c.ForTenant("tenant-key", TenantDefaults{Quota: TenantQuota{Limit: 1, Period: time.Hour}})
+	for i := 0; i < 2; i++ {
+		if _, err := view.GetMessage(context.Background(), 999); errors.Is(err, types.ErrQuotaExceeded) {
+			t.Fatalf("GetMessage() through tenant view error = %v", err)
+		}
+	}
+}
+
+func TestEndpointNotSupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"not found", http.StatusNotFound, `{"code": "NotFound", "message": "Route not found"}`},
+		{"not implemented", http.StatusNotImplemented, `{"code": "NotImplemented", "message": "Not implemented"}`},
+		{"proxy page", http.StatusNotFound, `<html><body>404 Not Found</body></html>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tt.body[0] == '<' {
+					w.Header().Set("Content-Type", "text/html")
+				} else {
+					w.Header().Set("Content-Type", "application/json")
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			c, err := NewClient(ts.URL, "test-key", WithMaxRetries(2), WithRetryInterval(time.Millisecond))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			_, err = c.GetDeliveries(context.Background(), 123)
+			var notSupported *types.EndpointNotSupportedError
+			if !errors.As(err, &notSupported) || notSupported.Endpoint != string(types.CapabilityDeliveries) {
+				t.Fatalf("GetDeliveries() error = %v, want EndpointNotSupportedError for messages/deliveries", err)
+			}
+			if !errors.Is(err, types.ErrEndpointNotSupported) || !errors.Is(err, types.ErrNotSupported) {
+				t.Errorf("GetDeliveries() error = %v, want it to match ErrEndpointNotSupported and ErrNotSupported", err)
+			}
+			if requests != 1 {
+				t.Errorf("server got %d requests, want 1 (no retries)", requests)
+			}
+		})
+	}
+}
+
+func TestGetDeliveries(t *testing.T) {
+	var body map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/messages/deliveries" {
+			t.Errorf("path = %q, want /api/v1/messages/deliveries", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "success", "time": 0.01, "flags": {}, "data": [
+			{"id": 1, "status": "SoftFail", "details": "Temporary failure", "output": "451 4.7.1 Try later", "sent_with_ssl": true, "log_id": "A1", "time": 0.4, "timestamp": 1700000000.0},
+			{"id": 2, "status": "HardFail", "details": "Permanent failure", "output": "550 5.1.1 User unknown", "sent_with_ssl": true, "log_id": "A2", "time": 0.2, "timestamp": 1700000600.0}
+		]}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	deliveries, err := c.GetDeliveries(context.Background(), 123)
+	if err != nil {
+		t.Fatalf("GetDeliveries() error = %v", err)
+	}
+	if body["id"] != float64(123) {
+		t.Errorf("request id = %v, want 123", body["id"])
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2", len(deliveries))
+	}
+	if deliveries[0].IsHardFail() || !deliveries[1].IsHardFail() {
+		t.Errorf("IsHardFail() = %v, %v; want false, true", deliveries[0].IsHardFail(), deliveries[1].IsHardFail())
+	}
+	if deliveries[1].SMTPCode() != 550 || deliveries[1].LogID != "A2" {
+		t.Errorf("second delivery = %+v", deliveries[1])
+	}
+}
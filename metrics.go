@@ -0,0 +1,22 @@
+package client
+
+import "github.com/sachin-duhan/postal-go/internal/middleware/metrics"
+
+// MetricsCollector receives request counts, durations and response sizes
+type MetricsCollector = metrics.Collector
+
+// TimingCollector is a MetricsCollector that also receives each request's
+// DNS, connect, TLS and first byte timings
+type TimingCollector = metrics.TimingCollector
+
+// ConnReuseCollector is a MetricsCollector that also counts whether each
+// request reused a pooled connection
+type ConnReuseCollector = metrics.ConnReuseCollector
+
+// WithMetrics reports every HTTP request, including each retry attempt, to
+// collector
+func WithMetrics(collector MetricsCollector) Option {
+	return func(c *clientImpl) {
+		c.transport.AddMiddleware(metrics.New(collector))
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingCollector struct {
+	mu       sync.Mutex
+	counts   map[int]int
+	timings  []RequestTimings
+	observed int
+	reused   map[bool]int
+}
+
+func (r *recordingCollector) ObserveRequestDuration(method, path string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observed++
+}
+
+func (r *recordingCollector) IncRequestCount(method, path string, statusCode int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[statusCode]++
+}
+
+func (r *recordingCollector) ObserveResponseSize(method, path string, bytes int64) {}
+
+func (r *recordingCollector) ObserveRequestTimings(method, path string, timings RequestTimings) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timings = append(r.timings, timings)
+}
+
+func (r *recordingCollector) IncConnectionReuse(method, path string, reused bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reused[reused]++
+}
+
+func TestWithMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message_id": "12357", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	collector := &recordingCollector{counts: make(map[int]int), reused: make(map[bool]int)}
+	var buf bytes.Buffer
+	client, err := NewClient(ts.URL, "test-key", WithMetrics(collector), WithDebug(true), WithLogger(log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendMessage(context.Background(), compatTestMessage()); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+
+	if collector.counts[http.StatusOK] != 2 || collector.observed != 2 {
+		t.Errorf("counts = %v, durations = %d, want 2 successful requests", collector.counts, collector.observed)
+	}
+	if len(collector.timings) != 2 || collector.timings[0].ReusedConn || !collector.timings[1].ReusedConn {
+		t.Errorf("timings = %v, want a new then a reused connection", collector.timings)
+	}
+	if collector.reused[false] != 1 || collector.reused[true] != 1 {
+		t.Errorf("connection reuse = %v, want one new and one reused", collector.reused)
+	}
+	if !contains(buf.String(), "[DEBUG] POST send/message: dns=") || !contains(buf.String(), "reused=true") {
+		t.Errorf("debug log = %q, want request timings", buf.String())
+	}
+}
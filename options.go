@@ -1,6 +1,8 @@
 package client
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 	"time"
 )
@@ -13,22 +15,267 @@ type Config struct {
 	MaxConcurrency int
 	Debug          bool
 	Transport      *http.Transport
+
+	// RetryJitter randomizes each wait between retries by up to this
+	// fraction of RetryInterval either way, e.g. 0.2 for ±20%, so clients
+	// that failed together do not retry in lockstep. Zero disables it.
+	RetryJitter float64
+
+	// MaxConcurrency, when positive, limits how many sends are in flight at
+	// once. ConcurrencyMode chooses whether sends over the limit wait for a
+	// slot or fail with types.ErrTooManyInFlight.
+	ConcurrencyMode ConcurrencyMode
+
+	// DefaultOperationTimeout bounds a whole send, including retries, when
+	// the caller's context has no deadline. Zero disables it.
+	DefaultOperationTimeout time.Duration
+
+	// MaxRedirects limits how many redirects are followed. Zero follows up
+	// to 10, like net/http's default policy; a negative value disables
+	// redirects.
+	MaxRedirects int
+	// MaxResponseSize caps the bytes read from a response body. Zero or a
+	// negative value removes the limit.
+	MaxResponseSize int64
+
+	// MaxAttachmentSize and MaxMessageSize reject sends whose attachments or
+	// whole request body exceed them once encoded, before anything is sent,
+	// since Postal's own errors for oversized mail are vague. Zero disables
+	// a limit.
+	MaxAttachmentSize int64
+	MaxMessageSize    int64
+
+	// IdempotencyHeader, when set, sends a generated idempotency key in this
+	// header with every send, kept the same across retries (see
+	// WithIdempotencyKeys)
+	IdempotencyHeader string
+
+	// StrictDecode fails sends whose successful response contains fields the
+	// client does not know, to catch drift between the client and server.
+	// The send itself may have succeeded, so use it in tests and staging.
+	StrictDecode bool
+
+	// PrivacyMode replaces recipient addresses and subjects in log output,
+	// client-set metrics labels and returned error strings with hashed
+	// identifiers (see the privacy package). Message content sent to the
+	// server and records saved to a result store are unchanged.
+	PrivacyMode bool
+
+	// ForceRaw makes SendMessage render messages as MIME and send them
+	// through send/raw, for servers whose send/message lacks needed features
+	ForceRaw bool
+
+	// Logger receives debug output when Debug is enabled. Defaults to log.Default().
+	Logger *log.Logger
+
+	// Compatibility selects how responses are parsed for the target Postal release
+	Compatibility CompatibilityMode
+	// APIPathPrefix overrides the default "api/v1" request path prefix
+	APIPathPrefix string
+	// FieldAliases renames request body fields (client name -> server name)
+	// for servers that use different field names
+	FieldAliases map[string]string
+
+	// ContextHeaders copies values found in the request context (trace ID,
+	// tenant ID, request ID...) into outgoing request headers
+	ContextHeaders []ContextHeader
+
+	// VerifiedDomains lists the domains the server can send for. When set,
+	// debug output warns about senders outside them and outside the sender
+	// profiles' domains, since such mail may fail DMARC alignment.
+	VerifiedDomains []string
+}
+
+// ContextHeader maps a context key to the header its value is sent in
+type ContextHeader struct {
+	Key    interface{}
+	Header string
 }
 
+// CompatibilityMode selects how the client adapts to different Postal releases
+type CompatibilityMode int
+
+const (
+	// CompatibilityAuto accepts both flat and enveloped responses, so one
+	// client works against mixed server versions
+	CompatibilityAuto CompatibilityMode = iota
+	// CompatibilityFlat expects message_id at the top level of responses
+	CompatibilityFlat
+	// CompatibilityEnvelope expects Postal's {"status", "time", "flags", "data"}
+	// envelope with results inside data
+	CompatibilityEnvelope
+)
+
+// ConcurrencyMode selects what sends beyond Config.MaxConcurrency do
+type ConcurrencyMode int
+
+const (
+	// ConcurrencyWait blocks a send until another finishes or its context ends
+	ConcurrencyWait ConcurrencyMode = iota
+	// ConcurrencyFail returns types.ErrTooManyInFlight at once
+	ConcurrencyFail
+)
+
 // Option is a function that configures the client
 type Option func(*clientImpl)
 
 // Middleware represents a function that wraps the client's transport layer
 type Middleware func(http.RoundTripper) http.RoundTripper
 
+// WithTimeout sets the per-request HTTP timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *clientImpl) {
+		c.config.Timeout = timeout
+	}
+}
+
+// WithMaxRetries sets how many times retryable failures are retried. Sends
+// are only retried when the server cannot have accepted the message (the
+// connection failed, or it answered 429 or 503) unless they carry an
+// idempotency key; see WithIdempotencyKeys.
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *clientImpl) {
+		c.config.MaxRetries = maxRetries
+	}
+}
+
+// WithRetryInterval sets the wait between retries
+func WithRetryInterval(interval time.Duration) Option {
+	return func(c *clientImpl) {
+		c.config.RetryInterval = interval
+	}
+}
+
+// WithRetryJitter randomizes the wait between retries by up to fraction of
+// the retry interval
+func WithRetryJitter(fraction float64) Option {
+	return func(c *clientImpl) {
+		c.config.RetryJitter = fraction
+	}
+}
+
+// WithDebug enables debug logging and response checks
+func WithDebug(debug bool) Option {
+	return func(c *clientImpl) {
+		c.config.Debug = debug
+	}
+}
+
+// WithLogger sets the logger used for debug output
+func WithLogger(logger *log.Logger) Option {
+	return func(c *clientImpl) {
+		c.config.Logger = logger
+	}
+}
+
+// WithStrictDecode enables strict response decoding
+func WithStrictDecode(strict bool) Option {
+	return func(c *clientImpl) {
+		c.config.StrictDecode = strict
+	}
+}
+
+// WithForceRaw makes SendMessage build MIME and send it through send/raw
+func WithForceRaw(forceRaw bool) Option {
+	return func(c *clientImpl) {
+		c.config.ForceRaw = forceRaw
+	}
+}
+
+// WithPrivacyMode keeps recipient addresses and subjects out of logs,
+// labels and error strings
+func WithPrivacyMode(enabled bool) Option {
+	return func(c *clientImpl) {
+		c.config.PrivacyMode = enabled
+	}
+}
+
+// WithVerifiedDomains sets the domains checked for DMARC alignment
+func WithVerifiedDomains(domains ...string) Option {
+	return func(c *clientImpl) {
+		c.config.VerifiedDomains = domains
+	}
+}
+
+// WithFieldAliases renames request body fields (client name -> server name)
+// for servers that use different field names
+func WithFieldAliases(aliases map[string]string) Option {
+	return func(c *clientImpl) {
+		c.config.FieldAliases = aliases
+	}
+}
+
+// WithMiddleware adds middleware to the client's transport
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *clientImpl) {
+		c.WithMiddleware(mws...)
+	}
+}
+
+// problems describes settings that are out of range or conflict, which
+// NewClient reports instead of misbehaving at send time
+func (cfg *Config) problems() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if cfg.Timeout < 0 {
+		add("Timeout %v is negative", cfg.Timeout)
+	}
+	if cfg.MaxRetries < 0 {
+		add("MaxRetries %d is negative", cfg.MaxRetries)
+	}
+	if cfg.RetryInterval < 0 {
+		add("RetryInterval %v is negative", cfg.RetryInterval)
+	}
+	if cfg.RetryJitter < 0 || cfg.RetryJitter > 1 {
+		add("RetryJitter %v is outside [0, 1]", cfg.RetryJitter)
+	}
+	if cfg.MaxConcurrency < 0 {
+		add("MaxConcurrency %d is negative", cfg.MaxConcurrency)
+	}
+	if cfg.MaxAttachmentSize < 0 {
+		add("MaxAttachmentSize %d is negative", cfg.MaxAttachmentSize)
+	}
+	if cfg.MaxMessageSize < 0 {
+		add("MaxMessageSize %d is negative", cfg.MaxMessageSize)
+	}
+	if cfg.ConcurrencyMode != ConcurrencyWait && cfg.ConcurrencyMode != ConcurrencyFail {
+		add("unknown ConcurrencyMode %d", cfg.ConcurrencyMode)
+	}
+	if cfg.Timeout == 0 && cfg.MaxRetries > 0 {
+		// Without a per-request timeout a hung request blocks until the
+		// operation deadline, so the retries never run
+		add("Timeout is zero with MaxRetries %d; set a timeout or disable retries", cfg.MaxRetries)
+	}
+	if cfg.DefaultOperationTimeout > 0 && cfg.Timeout > cfg.DefaultOperationTimeout {
+		add("Timeout %v exceeds DefaultOperationTimeout %v", cfg.Timeout, cfg.DefaultOperationTimeout)
+	}
+	if _, ok := responseFormats[cfg.Compatibility]; !ok {
+		add("unknown Compatibility mode %d", cfg.Compatibility)
+	}
+	for _, ch := range cfg.ContextHeaders {
+		if ch.Key == nil || ch.Header == "" {
+			add("ContextHeader %v needs both a key and a header", ch)
+		}
+	}
+	return problems
+}
+
+// DefaultMaxResponseSize is the response body limit used by DefaultConfig
+const DefaultMaxResponseSize = 10 << 20
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		Timeout:        30 * time.Second,
-		MaxRetries:     3,
-		RetryInterval:  time.Second,
-		MaxConcurrency: 10,
-		Debug:          false,
-		Transport:      http.DefaultTransport.(*http.Transport).Clone(),
+		Timeout:                 30 * time.Second,
+		DefaultOperationTimeout: 2 * time.Minute,
+		MaxResponseSize:         DefaultMaxResponseSize,
+		MaxRetries:              3,
+		RetryInterval:           time.Second,
+		MaxConcurrency:          10,
+		Debug:                   false,
+		Transport:               http.DefaultTransport.(*http.Transport).Clone(),
 	}
 }
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestOptions(t *testing.T) {
+	logger := log.New(log.Writer(), "postal: ", 0)
+	c, err := NewClient("https://postal.example.com", "test-key",
+		WithTimeout(10*time.Second),
+		WithMaxRetries(1),
+		WithRetryInterval(50*time.Millisecond),
+		WithDebug(true),
+		WithLogger(logger),
+		WithStrictDecode(true),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	impl := c.(*clientImpl)
+	if impl.config.Timeout != 10*time.Second {
+		t.Errorf("Timeout = %v, want 10s", impl.config.Timeout)
+	}
+	if got := impl.transport.HTTPClient().Timeout; got != 10*time.Second {
+		t.Errorf("http.Client Timeout = %v, want 10s", got)
+	}
+	if impl.config.MaxRetries != 1 {
+		t.Errorf("MaxRetries = %d, want 1", impl.config.MaxRetries)
+	}
+	if impl.config.RetryInterval != 50*time.Millisecond {
+		t.Errorf("RetryInterval = %v, want 50ms", impl.config.RetryInterval)
+	}
+	if !impl.config.Debug {
+		t.Error("Debug = false, want true")
+	}
+	if !impl.config.StrictDecode {
+		t.Error("StrictDecode = false, want true")
+	}
+	if impl.logger() != logger {
+		t.Error("logger() did not return the configured logger")
+	}
+}
+
+func TestOptionsValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "zero timeout with retries",
+			opts: []Option{WithTimeout(0)},
+			want: "Timeout is zero with MaxRetries 3",
+		},
+		{
+			name: "negative retries",
+			opts: []Option{WithMaxRetries(-1)},
+			want: "MaxRetries -1 is negative",
+		},
+		{
+			name: "jitter out of range",
+			opts: []Option{WithRetryJitter(1.5)},
+			want: "RetryJitter 1.5 is outside [0, 1]",
+		},
+		{
+			name: "timeout longer than operation timeout",
+			opts: []Option{WithTimeout(5 * time.Minute)},
+			want: "Timeout 5m0s exceeds DefaultOperationTimeout 2m0s",
+		},
+		{
+			name: "key provider applied twice",
+			opts: []Option{
+				WithKeyProvider(&staticKeyProvider{}, 0),
+				WithKeyProvider(&staticKeyProvider{}, 0),
+			},
+			want: "WithKeyProvider is applied more than once",
+		},
+		{
+			name: "signing the same header twice",
+			opts: []Option{
+				WithRequestSigning([]byte("secret-one"), ""),
+				WithRequestSigning([]byte("secret-two"), "x-postal-signature"),
+			},
+			want: "request signing is applied more than once to header X-Postal-Signature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient("https://postal.example.com", "test-key", tt.opts...)
+			if !errors.Is(err, types.ErrInvalidConfig) {
+				t.Fatalf("NewClient() error = %v, want ErrInvalidConfig", err)
+			}
+			if !contains(err.Error(), tt.want) {
+				t.Errorf("NewClient() error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	// Retries can be disabled to allow requests without a timeout
+	if _, err := NewClient("https://postal.example.com", "test-key", WithTimeout(0), WithMaxRetries(0)); err != nil {
+		t.Errorf("NewClient() with no timeout and no retries error = %v", err)
+	}
+	// Different headers may each carry a signature
+	if _, err := NewClient("https://postal.example.com", "test-key",
+		WithRequestSigning([]byte("secret-one"), "X-Gateway-A"),
+		WithRequestSigning([]byte("secret-two"), "X-Gateway-B"),
+	); err != nil {
+		t.Errorf("NewClient() signing two headers error = %v", err)
+	}
+}
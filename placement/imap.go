@@ -0,0 +1,129 @@
+package placement
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+)
+
+// IMAPMailbox finds probes over IMAP by searching for the token header
+type IMAPMailbox struct {
+	// Addr is the server address, e.g. "imap.example.com:993"
+	Addr     string
+	Username string
+	Password string
+	// PlainText disables TLS, for testing against local servers
+	PlainText bool
+	// SpamFolders are searched after INBOX and default to "Junk" and "Spam"
+	SpamFolders []string
+}
+
+// Find implements Mailbox
+func (m *IMAPMailbox) Find(ctx context.Context, token string) (Placement, error) {
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return Missing, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	s := &imapSession{r: textproto.NewReader(bufio.NewReader(conn)), w: conn}
+	if _, err := s.r.ReadLine(); err != nil {
+		return Missing, fmt.Errorf("imap: failed to read greeting: %w", err)
+	}
+	if _, err := s.command("LOGIN " + quote(m.Username) + " " + quote(m.Password)); err != nil {
+		return Missing, err
+	}
+	defer s.command("LOGOUT")
+
+	spam := m.SpamFolders
+	if len(spam) == 0 {
+		spam = []string{"Junk", "Spam"}
+	}
+	folders := append([]string{"INBOX"}, spam...)
+	for i, folder := range folders {
+		if _, err := s.command("EXAMINE " + quote(folder)); err != nil {
+			// Missing spam folders are common; skip them
+			if i > 0 {
+				continue
+			}
+			return Missing, err
+		}
+		lines, err := s.command("SEARCH HEADER " + TokenHeader + " " + quote(token))
+		if err != nil {
+			return Missing, err
+		}
+		if found(lines) {
+			if i == 0 {
+				return Inbox, nil
+			}
+			return Spam, nil
+		}
+	}
+	return Missing, nil
+}
+
+// dial connects to the server, using TLS unless PlainText is set
+func (m *IMAPMailbox) dial(ctx context.Context) (net.Conn, error) {
+	if m.PlainText {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", m.Addr)
+	}
+	d := tls.Dialer{}
+	return d.DialContext(ctx, "tcp", m.Addr)
+}
+
+// imapSession issues tagged IMAP commands
+type imapSession struct {
+	r   *textproto.Reader
+	w   net.Conn
+	tag int
+}
+
+// command sends a command and returns its untagged response lines, or an
+// error if the command does not complete with OK
+func (s *imapSession) command(cmd string) ([]string, error) {
+	s.tag++
+	tag := fmt.Sprintf("a%d", s.tag)
+	if _, err := fmt.Fprintf(s.w, "%s %s\r\n", tag, cmd); err != nil {
+		return nil, fmt.Errorf("imap: %w", err)
+	}
+
+	var untagged []string
+	for {
+		line, err := s.r.ReadLine()
+		if err != nil {
+			return nil, fmt.Errorf("imap: %w", err)
+		}
+		if !strings.HasPrefix(line, tag+" ") {
+			untagged = append(untagged, line)
+			continue
+		}
+		if status := strings.TrimPrefix(line, tag+" "); !strings.HasPrefix(status, "OK") {
+			verb, _, _ := strings.Cut(cmd, " ")
+			return nil, fmt.Errorf("imap: %s failed: %s", verb, status)
+		}
+		return untagged, nil
+	}
+}
+
+// found reports whether a SEARCH response lists any messages
+func found(lines []string) bool {
+	for _, line := range lines {
+		if ids, ok := strings.CutPrefix(line, "* SEARCH"); ok && strings.TrimSpace(ids) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// quote returns s as an IMAP quoted string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
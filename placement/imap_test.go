@@ -0,0 +1,107 @@
+package placement
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeIMAPServer answers LOGIN, EXAMINE and SEARCH. messages maps a folder
+// to the tokens it holds; folders not in the map do not exist.
+func fakeIMAPServer(t *testing.T, password string, messages map[string][]string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveIMAP(conn, password, messages)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func serveIMAP(conn net.Conn, password string, messages map[string][]string) {
+	defer conn.Close()
+	fmt.Fprint(conn, "* OK IMAP ready\r\n")
+
+	var folder string
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		tag, cmd, _ := strings.Cut(scanner.Text(), " ")
+		verb, args, _ := strings.Cut(cmd, " ")
+		switch verb {
+		case "LOGIN":
+			if !strings.HasSuffix(args, `"`+password+`"`) {
+				fmt.Fprintf(conn, "%s NO authentication failed\r\n", tag)
+				continue
+			}
+		case "EXAMINE":
+			folder = strings.Trim(args, `"`)
+			if _, ok := messages[folder]; !ok {
+				fmt.Fprintf(conn, "%s NO no such mailbox\r\n", tag)
+				continue
+			}
+		case "SEARCH":
+			ids := ""
+			for i, token := range messages[folder] {
+				if strings.HasSuffix(args, `"`+token+`"`) {
+					ids += fmt.Sprintf(" %d", i+1)
+				}
+			}
+			fmt.Fprintf(conn, "* SEARCH%s\r\n", ids)
+		case "LOGOUT":
+			fmt.Fprintf(conn, "* BYE\r\n%s OK LOGOUT completed\r\n", tag)
+			return
+		}
+		fmt.Fprintf(conn, "%s OK %s completed\r\n", tag, verb)
+	}
+}
+
+func TestIMAPMailboxFind(t *testing.T) {
+	addr := fakeIMAPServer(t, `p\"w`, map[string][]string{
+		"INBOX": {"other", "inbox-token"},
+		"Spam":  {"spam-token"},
+	})
+
+	tests := []struct {
+		token string
+		want  Placement
+	}{
+		{token: "inbox-token", want: Inbox},
+		{token: "spam-token", want: Spam},
+		{token: "unknown", want: Missing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			mb := &IMAPMailbox{Addr: addr, Username: "seed", Password: `p"w`, PlainText: true}
+			got, err := mb.Find(context.Background(), tt.token)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Find() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIMAPMailboxLoginFailure(t *testing.T) {
+	addr := fakeIMAPServer(t, "secret", map[string][]string{"INBOX": nil})
+
+	mb := &IMAPMailbox{Addr: addr, Username: "seed", Password: "wrong", PlainText: true}
+	if _, err := mb.Find(context.Background(), "token"); err == nil || !strings.Contains(err.Error(), "LOGIN failed") {
+		t.Errorf("Find() error = %v, want LOGIN failure", err)
+	}
+}
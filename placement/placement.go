@@ -0,0 +1,167 @@
+// Package placement measures inbox placement by sending probe messages to
+// seed mailboxes and checking which folder they land in
+package placement
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	client "github.com/sachin-duhan/postal-go"
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// TokenHeader carries the probe token used to find the message in a seed mailbox
+const TokenHeader = "X-Placement-Token"
+
+// Placement is where a probe was found
+type Placement string
+
+const (
+	Inbox   Placement = "inbox"
+	Spam    Placement = "spam"
+	Missing Placement = "missing"
+)
+
+// Mailbox finds a probe by its token in a seed mailbox
+type Mailbox interface {
+	// Find returns the folder holding the message with the token, or
+	// Missing if it has not arrived
+	Find(ctx context.Context, token string) (Placement, error)
+}
+
+// Seed is a mailbox probes are sent to
+type Seed struct {
+	Address string
+	Mailbox Mailbox
+}
+
+// Result is the placement of the probe sent to one seed
+type Result struct {
+	Seed      string
+	Placement Placement
+	Err       error
+}
+
+// Harness sends probes and collects their placement
+type Harness struct {
+	Client client.Client
+	From   string
+	// Subject and Body are text/template strings executed with .Token and .Seed
+	Subject string
+	Body    string
+	Seeds   []Seed
+
+	// PollInterval defaults to 10 seconds
+	PollInterval time.Duration
+	// Timeout bounds the wait for probes to arrive and defaults to 5 minutes.
+	// Probes not found by then are reported as Missing.
+	Timeout time.Duration
+}
+
+// Run sends a probe to every seed and polls the mailboxes until each probe
+// is found or the timeout expires
+func (h *Harness) Run(ctx context.Context) ([]Result, error) {
+	subject, err := template.New("subject").Parse(h.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	body, err := template.New("body").Parse(h.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	results := make([]Result, len(h.Seeds))
+	tokens := make([]string, len(h.Seeds))
+	pending := 0
+	for i, seed := range h.Seeds {
+		results[i] = Result{Seed: seed.Address, Placement: Missing}
+		if tokens[i], err = newToken(); err != nil {
+			return nil, err
+		}
+		msg, err := probe(h.From, seed.Address, tokens[i], subject, body)
+		if err == nil {
+			_, err = h.Client.SendMessage(ctx, msg)
+		}
+		if err != nil {
+			results[i].Err = fmt.Errorf("failed to send probe: %w", err)
+			continue
+		}
+		pending++
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	interval := h.PollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			return results, nil
+		case <-ticker.C:
+		}
+
+		for i, seed := range h.Seeds {
+			if results[i].Err != nil || results[i].Placement != Missing {
+				continue
+			}
+			placement, err := seed.Mailbox.Find(ctx, tokens[i])
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					return results, nil
+				}
+				results[i].Err = err
+				pending--
+				continue
+			}
+			if placement != Missing {
+				results[i].Placement = placement
+				pending--
+			}
+		}
+	}
+	return results, nil
+}
+
+// probe renders the probe message for one seed
+func probe(from, to, token string, subject, body *template.Template) (*types.Message, error) {
+	data := struct{ Token, Seed string }{token, to}
+
+	var s, b bytes.Buffer
+	if err := subject.Execute(&s, data); err != nil {
+		return nil, err
+	}
+	if err := body.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return &types.Message{
+		To:      []string{to},
+		From:    from,
+		Subject: s.String(),
+		Body:    b.String(),
+		Headers: map[string]string{TokenHeader: token},
+	}, nil
+}
+
+// newToken returns a random probe token
+func newToken() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,108 @@
+package placement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	client "github.com/sachin-duhan/postal-go"
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// fakeMailbox places probes according to the address it was sent to
+type fakeMailbox struct {
+	mu        sync.Mutex
+	delivered map[string]Placement // token -> placement
+	placement Placement
+	err       error
+}
+
+func (m *fakeMailbox) Find(ctx context.Context, token string) (Placement, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return Missing, m.err
+	}
+	if p, ok := m.delivered[token]; ok {
+		return p, nil
+	}
+	return Missing, nil
+}
+
+func TestHarnessRun(t *testing.T) {
+	mailboxes := map[string]*fakeMailbox{
+		"inbox@seed.example":   {placement: Inbox},
+		"spam@seed.example":    {placement: Spam},
+		"missing@seed.example": {placement: Missing},
+		"broken@seed.example":  {err: errors.New("login failed")},
+	}
+
+	var subjects []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg types.Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		subjects = append(subjects, msg.Subject)
+
+		mb := mailboxes[msg.To[0]]
+		mb.mu.Lock()
+		if mb.placement != Missing {
+			mb.delivered = map[string]Placement{msg.Headers[TokenHeader]: mb.placement}
+		}
+		mb.mu.Unlock()
+		w.Write([]byte(`{"message_id": "1", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := client.NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	h := &Harness{
+		Client:       c,
+		From:         "probe@example.com",
+		Subject:      "Probe {{.Seed}}",
+		Body:         "Token {{.Token}}",
+		PollInterval: time.Millisecond,
+		Timeout:      50 * time.Millisecond,
+	}
+	for _, addr := range []string{"inbox@seed.example", "spam@seed.example", "missing@seed.example", "broken@seed.example"} {
+		h.Seeds = append(h.Seeds, Seed{Address: addr, Mailbox: mailboxes[addr]})
+	}
+
+	results, err := h.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]Placement{
+		"inbox@seed.example":   Inbox,
+		"spam@seed.example":    Spam,
+		"missing@seed.example": Missing,
+		"broken@seed.example":  Missing,
+	}
+	for _, r := range results {
+		if r.Placement != want[r.Seed] {
+			t.Errorf("%s: Placement = %s, want %s", r.Seed, r.Placement, want[r.Seed])
+		}
+		if (r.Err != nil) != (r.Seed == "broken@seed.example") {
+			t.Errorf("%s: Err = %v", r.Seed, r.Err)
+		}
+	}
+	if subjects[0] != "Probe inbox@seed.example" {
+		t.Errorf("subject = %q, want the rendered template", subjects[0])
+	}
+}
+
+func TestHarnessInvalidTemplate(t *testing.T) {
+	h := &Harness{Subject: "{{.Token"}
+	if _, err := h.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid subject template") {
+		t.Errorf("Run() error = %v, want invalid subject template", err)
+	}
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/common/validation"
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+// PreparedMessage is a message marshalled once for repeated sends where only
+// the To recipients change, such as a campaign with an identical body
+type PreparedMessage struct {
+	c   *clientImpl
+	msg *types.Message
+
+	// static is the JSON body without the recipients field or closing brace
+	static []byte
+	// toField is the quoted name of the recipients field, after aliases
+	toField []byte
+}
+
+// Prepare validates msg and marshals everything except its To recipients.
+// WithProfile, WithCorrelationID and Config.FieldAliases are applied here;
+// options passed to PreparedMessage.Send only affect the request. Later
+// changes to msg do not affect the prepared message.
+func (c *clientImpl) Prepare(msg *types.Message, opts ...SendOption) (*PreparedMessage, error) {
+	o := collectSendOptions(opts)
+	msg, err := c.profileDefaults(msg, o.profile)
+	if err != nil {
+		return nil, err
+	}
+	msg = c.messageDefaults(msg)
+	static := *msg
+	static.Headers = o.correlationHeaders(msg.Headers)
+
+	// Recipients are checked per send; validate the rest with a stand-in
+	check := static
+	check.To = []string{static.From}
+	if err := validation.ValidateMessage(&check); err != nil {
+		return nil, c.redactError(err, messageIdentifiers(&check)...)
+	}
+	if err := validation.ValidateMessageSize(&static, c.sizeLimits()); err != nil {
+		return nil, err
+	}
+	c.warn("send/message", validation.AlignmentWarning(static.From, c.verifiedDomains()))
+
+	p := &PreparedMessage{c: c, msg: &static}
+	if c.config.ForceRaw {
+		// The recipients are part of the rendered MIME, so raw sends are
+		// rendered in full each time
+		return p, nil
+	}
+	if p.static, p.toField, err = c.marshalStatic(&static); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// marshalStatic marshals msg without its To field, applying field aliases
+func (c *clientImpl) marshalStatic(msg *types.Message) (static, toField []byte, err error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	delete(fields, "to")
+
+	rename := func(name string) string {
+		if alias, ok := c.config.FieldAliases[name]; ok {
+			return alias
+		}
+		return name
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, name := range names {
+		key, _ := json.Marshal(rename(name))
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(fields[name])
+		buf.WriteByte(',')
+	}
+	toField, _ = json.Marshal(rename("to"))
+	return buf.Bytes(), toField, nil
+}
+
+// Send sends the prepared message to the given recipients
+func (p *PreparedMessage) Send(ctx context.Context, to []string, opts ...SendOption) (*types.Result, error) {
+	if err := validation.ValidateEnvelope(&types.Envelope{To: to, From: p.msg.From}); err != nil {
+		return nil, p.c.redactError(err, append([]string{p.msg.From}, to...)...)
+	}
+	msg := *p.msg
+	msg.To = to
+
+	o := collectSendOptions(opts)
+	if p.static == nil {
+		req, err := p.c.messageRequest(&msg, o)
+		if err != nil {
+			return nil, err
+		}
+		return p.c.sendAndRecord(ctx, req, func() *resultstore.Record {
+			r := messageRecord(&msg)
+			r.Path = req.Path
+			return r
+		})
+	}
+
+	recipients, err := json.Marshal(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal recipients: %w", err)
+	}
+
+	// The static body is shared by reference; only the tail is per send
+	req := p.c.newRequest(http.MethodPost, "send/message", nil, o)
+	req.ContentLength = int64(len(p.static) + len(p.toField) + len(recipients) + 2)
+	req.BodyStream = func() (io.Reader, error) {
+		buf := tailPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		p.writeTail(buf, recipients)
+		return &pooledBody{
+			Reader: io.MultiReader(bytes.NewReader(p.static), bytes.NewReader(buf.Bytes())),
+			buf:    buf,
+		}, nil
+	}
+	return p.c.sendAndRecord(ctx, req, func() *resultstore.Record {
+		return messageRecord(&msg)
+	})
+}
+
+// tailPool holds buffers for the per-send end of prepared bodies
+var tailPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// pooledBody is a prepared request body that returns its tail buffer to the
+// pool once the HTTP client closes it. Embedding the reader also hides
+// MultiReader's WriteTo, which allocates a 32KB buffer per call.
+type pooledBody struct {
+	io.Reader
+	buf  *bytes.Buffer
+	once sync.Once
+}
+
+// Close returns the tail buffer to the pool
+func (b *pooledBody) Close() error {
+	b.once.Do(func() { tailPool.Put(b.buf) })
+	return nil
+}
+
+// writeTail writes the recipients field and closing brace that follow the
+// static JSON
+func (p *PreparedMessage) writeTail(buf *bytes.Buffer, recipients []byte) {
+	buf.Write(p.toField)
+	buf.WriteByte(':')
+	buf.Write(recipients)
+	buf.WriteByte('}')
+}
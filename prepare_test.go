@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestPrepare(t *testing.T) {
+	var bodies []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "prepared", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key", WithFieldAliases(map[string]string{"to": "recipients"}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg := compatTestMessage()
+	msg.CC = []string{"cc@example.com"}
+	msg.Headers = map[string]string{"X-Campaign": "spring"}
+	prepared, err := c.Prepare(msg, WithCorrelationID("batch-7"))
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	msg.Subject = "changed after Prepare"
+
+	ctx := context.Background()
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		if _, err := prepared.Send(ctx, []string{to}); err != nil {
+			t.Fatalf("Send(%s) error = %v", to, err)
+		}
+	}
+
+	for i, to := range []string{"a@example.com", "b@example.com"} {
+		body := bodies[i]
+		if !reflect.DeepEqual(body["recipients"], []interface{}{to}) {
+			t.Errorf("send %d recipients = %v, want [%s]", i, body["recipients"], to)
+		}
+		if _, ok := body["to"]; ok {
+			t.Errorf("send %d has unaliased to field", i)
+		}
+		if body["subject"] != compatTestMessage().Subject {
+			t.Errorf("send %d subject = %v", i, body["subject"])
+		}
+		if !reflect.DeepEqual(body["cc"], []interface{}{"cc@example.com"}) {
+			t.Errorf("send %d cc = %v", i, body["cc"])
+		}
+		headers, _ := body["headers"].(map[string]interface{})
+		if headers["X-Campaign"] != "spring" || headers[types.CorrelationHeader] != "batch-7" {
+			t.Errorf("send %d headers = %v", i, headers)
+		}
+	}
+}
+
+func TestPrepareMatchesSendMessage(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		canonical, _ := json.Marshal(body)
+		bodies = append(bodies, string(canonical))
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "prepared", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	msg := compatTestMessage()
+	prepared, err := c.Prepare(msg)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := c.SendMessage(ctx, msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, err := prepared.Send(ctx, msg.To); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("prepared body differs from SendMessage:\n%s\n%s", bodies[1], bodies[0])
+	}
+}
+
+func TestPrepareValidation(t *testing.T) {
+	c, err := NewClient("https://postal.example.com", "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg := compatTestMessage()
+	msg.Subject = ""
+	if _, err := c.Prepare(msg); !errors.As(err, new(*types.ValidationError)) {
+		t.Errorf("Prepare() without subject error = %v, want ValidationError", err)
+	}
+
+	prepared, err := c.Prepare(compatTestMessage())
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	_, err = prepared.Send(context.Background(), []string{"not-an-address"})
+	if err == nil || !strings.Contains(err.Error(), "not-an-address") {
+		t.Errorf("Send() error = %v, want invalid recipient", err)
+	}
+}
+
+func TestPrepareForceRaw(t *testing.T) {
+	var raw types.RawMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&raw)
+		mail, _ := base64.StdEncoding.DecodeString(raw.Mail)
+		raw.Mail = string(mail)
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "prepared", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "test-key", WithForceRaw(true))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	prepared, err := c.Prepare(compatTestMessage())
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if _, err := prepared.Send(context.Background(), []string{"raw@example.com"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !reflect.DeepEqual(raw.To, []string{"raw@example.com"}) || !strings.Contains(raw.Mail, "To: raw@example.com") {
+		t.Errorf("raw send = %v %q", raw.To, raw.Mail)
+	}
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// SenderProfile is a named sending identity. Its fields fill in values a
+// message leaves empty; headers set on the message take precedence.
+type SenderProfile struct {
+	Name    string
+	From    string
+	Sender  string
+	ReplyTo string
+	Tag     string
+	Headers map[string]string
+}
+
+// WithSenderProfile registers sender profiles that sends select with
+// WithProfile. A profile replaces any earlier profile with the same name.
+func WithSenderProfile(profiles ...SenderProfile) Option {
+	return func(c *clientImpl) {
+		registered := make(map[string]SenderProfile, len(c.profiles)+len(profiles))
+		for name, p := range c.profiles {
+			registered[name] = p
+		}
+		for _, p := range profiles {
+			registered[p.Name] = p
+		}
+		c.profiles = registered
+	}
+}
+
+// profile looks up a registered profile. The empty name selects no profile.
+func (c *clientImpl) profile(name string) (SenderProfile, error) {
+	if name == "" {
+		return SenderProfile{}, nil
+	}
+	p, ok := c.profiles[name]
+	if !ok {
+		return SenderProfile{}, fmt.Errorf("%w: %q", types.ErrUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// profileDefaults fills in the named profile without modifying the caller's message
+func (c *clientImpl) profileDefaults(msg *types.Message, name string) (*types.Message, error) {
+	if name == "" || msg == nil {
+		return msg, nil
+	}
+	p, err := c.profile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	withProfile := *msg
+	if withProfile.From == "" {
+		withProfile.From = p.From
+	}
+	if withProfile.Sender == "" {
+		withProfile.Sender = p.Sender
+	}
+	if withProfile.ReplyTo == "" {
+		withProfile.ReplyTo = p.ReplyTo
+	}
+	if withProfile.Tag == "" {
+		withProfile.Tag = p.Tag
+	}
+	withProfile.Headers = mergeHeaders(p.Headers, msg.Headers)
+	return &withProfile, nil
+}
+
+// rawProfileDefaults fills in the named profile's sender and headers
+func (c *clientImpl) rawProfileDefaults(raw *types.RawMessage, name string) (*types.RawMessage, error) {
+	if name == "" || raw == nil {
+		return raw, nil
+	}
+	p, err := c.profile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	withProfile := *raw
+	if withProfile.From == "" {
+		withProfile.From = p.From
+	}
+	withProfile.Headers = mergeHeaders(p.Headers, raw.Headers)
+	return &withProfile, nil
+}
+
+// mergeHeaders returns defaults overlaid with headers, or headers unchanged
+// when there are no defaults
+func mergeHeaders(defaults, headers map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return headers
+	}
+	merged := make(map[string]string, len(defaults)+len(headers))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	return merged
+}
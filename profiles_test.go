@@ -0,0 +1,163 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestSenderProfiles(t *testing.T) {
+	var got types.Message
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = types.Message{}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"message_id": "12370", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key", WithSenderProfile(
+		SenderProfile{
+			Name:    "billing",
+			From:    "billing@example.com",
+			ReplyTo: "accounts@example.com",
+			Tag:     "billing",
+			Headers: map[string]string{"X-Team": "billing", "X-Priority": "3"},
+		},
+		SenderProfile{Name: "support", From: "support@example.com"},
+	))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg := &types.Message{
+		To:       []string{"recipient@example.com"},
+		Subject:  "Invoice",
+		HTMLBody: "Body",
+		Headers:  map[string]string{"X-Priority": "1"},
+	}
+	if _, err := client.SendMessage(context.Background(), msg, WithProfile("billing")); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if got.From != "billing@example.com" || got.ReplyTo != "accounts@example.com" || got.Tag != "billing" {
+		t.Errorf("profile fields not applied: %+v", got)
+	}
+	if got.Headers["X-Team"] != "billing" || got.Headers["X-Priority"] != "1" {
+		t.Errorf("Headers = %v, want profile headers with message override", got.Headers)
+	}
+	if msg.From != "" || len(msg.Headers) != 1 {
+		t.Errorf("caller's message was modified: %+v", msg)
+	}
+
+	msg.From = "override@example.com"
+	if _, err := client.SendMessage(context.Background(), msg, WithProfile("support")); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if got.From != "override@example.com" {
+		t.Errorf("From = %q, want the message's own sender", got.From)
+	}
+}
+
+func TestSenderProfileUnknown(t *testing.T) {
+	client, err := NewClient("http://localhost", "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.SendMessage(context.Background(), retryTestMessage(), WithProfile("missing"))
+	if !errors.Is(err, types.ErrUnknownProfile) {
+		t.Errorf("SendMessage() error = %v, want ErrUnknownProfile", err)
+	}
+
+	_, err = client.SendRawMessage(context.Background(), rawTestMessage(), WithProfile("missing"))
+	if !errors.Is(err, types.ErrUnknownProfile) {
+		t.Errorf("SendRawMessage() error = %v, want ErrUnknownProfile", err)
+	}
+}
+
+func TestSenderProfileInheritedByViews(t *testing.T) {
+	var from string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body types.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		from = body.From
+		w.Write([]byte(`{"message_id": "12371", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key", WithSenderProfile(SenderProfile{Name: "news", From: "news@example.com"}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	raw := rawTestMessage()
+	raw.From = ""
+	for name, c := range map[string]Client{"clone": client.Clone(), "tenant": client.ForTenant("tenant-key", TenantDefaults{})} {
+		from = ""
+		if _, err := c.SendRawMessage(context.Background(), raw, WithProfile("news")); err != nil {
+			t.Fatalf("%s: SendRawMessage() error = %v", name, err)
+		}
+		if from != "news@example.com" {
+			t.Errorf("%s: From = %q, want the profile sender", name, from)
+		}
+	}
+}
+
+func TestAlignmentWarnings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message_id": "12372", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	client, err := NewClient(ts.URL, "test-key",
+		WithDebug(true),
+		WithLogger(log.New(&buf, "", 0)),
+		WithSenderProfile(SenderProfile{Name: "partner", From: "mail@partner.org"}),
+		WithVerifiedDomains("example.com"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		from     string
+		wantWarn bool
+	}{
+		{name: "verified domain", from: "sender@example.com"},
+		{name: "profile domain", from: "other@partner.org"},
+		{name: "unverified domain", from: "sender@other.net", wantWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			msg := retryTestMessage()
+			msg.From = tt.from
+			if _, err := client.SendMessage(context.Background(), msg); err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			if got := contains(buf.String(), "[WARN] send/message: sender domain"); got != tt.wantWarn {
+				t.Errorf("warning logged = %v, want %v; log %q", got, tt.wantWarn, buf.String())
+			}
+		})
+	}
+
+	buf.Reset()
+	raw := rawTestMessage()
+	raw.Mail = "From: news@other.net\r\nTo: recipient@example.com\r\nSubject: Test\r\n\r\nBody"
+	if _, err := client.SendRawMessage(context.Background(), raw); err != nil {
+		t.Fatalf("SendRawMessage() error = %v", err)
+	}
+	if !contains(buf.String(), "[WARN] send/raw: sender domain other.net") {
+		t.Errorf("expected raw From header to be checked, got %q", buf.String())
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/common/validation"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+// SendRawMessageFrom implements Client
+func (c *clientImpl) SendRawMessageFrom(ctx context.Context, r io.Reader, env types.Envelope, opts ...SendOption) (*types.Result, error) {
+	o := collectSendOptions(opts)
+	profile, err := c.profile(o.profile)
+	if err != nil {
+		return nil, err
+	}
+	if env.From == "" {
+		env.From = profile.From
+	}
+	env = c.envelopeDefaults(env)
+	if err := validation.ValidateEnvelope(&env); err != nil {
+		return nil, c.redactError(err, append([]string{env.From}, env.To...)...)
+	}
+
+	req := c.newRequest(http.MethodPost, "send/raw", nil, o)
+	req.BodyStream = rawBodyStream(r, env, c.config.MaxMessageSize)
+	result, err := c.sendAndRecord(ctx, req, func() *resultstore.Record {
+		return rawRecord(env.To, env.From)
+	})
+	// Report a stream over a limit as SendRawMessage reports the mail,
+	// rather than as the transport failure it aborted the request with
+	var tooLarge *types.MessageTooLargeError
+	if errors.As(err, &tooLarge) {
+		return nil, tooLarge
+	}
+	var invalid *types.ValidationError
+	if errors.As(err, &invalid) {
+		return nil, invalid
+	}
+	return result, err
+}
+
+// rawBodyStream returns a body factory that base64 encodes r into a send/raw
+// request as it is read. The request is aborted with a
+// *types.ValidationError once the mail exceeds validation.MaxRawMailSize, or
+// with a *types.MessageTooLargeError once its encoding exceeds
+// maxEncoded, when that is above zero. Retries rewind r when it is an
+// io.Seeker; otherwise a second attempt fails with transport.ErrBodyConsumed.
+func rawBodyStream(r io.Reader, env types.Envelope, maxEncoded int64) func() (io.Reader, error) {
+	seeker, _ := r.(io.Seeker)
+	start := int64(-1)
+	if seeker != nil {
+		if pos, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			start = pos
+		}
+	}
+
+	var done chan struct{}
+	return func() (io.Reader, error) {
+		if done != nil {
+			// Wait for the previous attempt to stop reading before rewinding
+			<-done
+			if start < 0 {
+				return nil, transport.ErrBodyConsumed
+			}
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+
+		pr, pw := io.Pipe()
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			pw.CloseWithError(writeRawBody(pw, &rawLimitReader{r: r, maxEncoded: maxEncoded}, env))
+		}(done)
+		return pr, nil
+	}
+}
+
+// encodeRaw returns a copy of raw with its MIME content base64 encoded, the
+// form send/raw expects and rawBodyStream streams
+func encodeRaw(raw *types.RawMessage) *types.RawMessage {
+	encoded := *raw
+	encoded.Mail = base64.StdEncoding.EncodeToString([]byte(raw.Mail))
+	return &encoded
+}
+
+// writeRawBody writes the send/raw JSON body with the mail encoded from r
+func writeRawBody(w io.Writer, r io.Reader, env types.Envelope) error {
+	to, err := json.Marshal(env.To)
+	if err != nil {
+		return err
+	}
+	from, err := json.Marshal(env.From)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, `{"mail":"`); err != nil {
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := io.Copy(enc, r); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `","to":`+string(to)+`,"from":`+string(from)+`}`)
+	return err
+}
+
+// rawLimitReader applies the SendRawMessage size checks to mail as it is
+// read from r: validation.ValidateRawMailSize to the mail itself and
+// maxEncoded, when above zero, to its base64 encoding
+type rawLimitReader struct {
+	r          io.Reader
+	read       int64
+	maxEncoded int64
+}
+
+func (l *rawLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if sizeErr := validation.ValidateRawMailSize(l.read); sizeErr != nil {
+		return 0, sizeErr
+	}
+	if size := int64(base64.StdEncoding.EncodedLen(int(l.read))); l.maxEncoded > 0 && size > l.maxEncoded {
+		return 0, &types.MessageTooLargeError{Part: "raw message", Size: size, Limit: l.maxEncoded}
+	}
+	return n, err
+}
+
+// envelopeDefaults fills the envelope sender from the tenant defaults
+func (c *clientImpl) envelopeDefaults(env types.Envelope) types.Envelope {
+	if c.tenant != nil && env.From == "" {
+		env.From = c.tenant.From
+	}
+	return env
+}
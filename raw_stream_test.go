@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/common/validation"
+)
+
+const streamTestMail = "From: sender@example.com\r\nTo: recipient@example.com\r\nSubject: Stream\r\n\r\nBody"
+
+// rawStreamServer records the decoded mail of each send/raw request and
+// fails the first failures attempts with a 503
+func rawStreamServer(t *testing.T, failures int, mails *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Mail string   `json:"mail"`
+			To   []string `json:"to"`
+			From string   `json:"from"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		mail, err := base64.StdEncoding.DecodeString(body.Mail)
+		if err != nil {
+			t.Errorf("mail is not base64: %v", err)
+		}
+		*mails = append(*mails, string(mail))
+
+		if len(*mails) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status": "error", "message": "unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"message_id": "12360", "status": "success"}`))
+	}))
+}
+
+func TestSendRawMessageFrom(t *testing.T) {
+	var mails []string
+	ts := rawStreamServer(t, 0, &mails)
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, 0)
+	env := types.Envelope{To: []string{"recipient@example.com"}, From: "sender@example.com"}
+
+	result, err := client.SendRawMessageFrom(context.Background(), strings.NewReader(streamTestMail), env)
+	if err != nil {
+		t.Fatalf("SendRawMessageFrom() error = %v", err)
+	}
+	if result.MessageID != "12360" {
+		t.Errorf("MessageID = %q, want %q", result.MessageID, "12360")
+	}
+	if len(mails) != 1 || mails[0] != streamTestMail {
+		t.Errorf("server received %q, want %q", mails, streamTestMail)
+	}
+}
+
+func TestRawSendBodiesMatch(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Write([]byte(`{"message_id": "12360", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, 0)
+	env := types.Envelope{To: []string{"recipient@example.com"}, From: "sender@example.com"}
+	raw := &types.RawMessage{Mail: streamTestMail, To: env.To, From: env.From}
+
+	if _, err := client.SendRawMessage(context.Background(), raw); err != nil {
+		t.Fatalf("SendRawMessage() error = %v", err)
+	}
+	if _, err := client.SendRawMessageFrom(context.Background(), strings.NewReader(streamTestMail), env); err != nil {
+		t.Fatalf("SendRawMessageFrom() error = %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("requests = %d, want 2", len(bodies))
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("SendRawMessage sent %s, SendRawMessageFrom sent %s", bodies[0], bodies[1])
+	}
+	if raw.Mail != streamTestMail {
+		t.Errorf("SendRawMessage modified the caller's message: %q", raw.Mail)
+	}
+}
+
+func TestSendRawMessageFromRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		reader       func() io.Reader
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "seekable reader is rewound",
+			reader:       func() io.Reader { return strings.NewReader(streamTestMail) },
+			wantAttempts: 2,
+		},
+		{
+			name:         "non-seekable reader is sent once",
+			reader:       func() io.Reader { return io.MultiReader(strings.NewReader(streamTestMail)) },
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mails []string
+			ts := rawStreamServer(t, 1, &mails)
+			defer ts.Close()
+
+			client := newRetryTestClient(t, ts.URL, 2)
+			env := types.Envelope{To: []string{"recipient@example.com"}, From: "sender@example.com"}
+
+			_, err := client.SendRawMessageFrom(context.Background(), tt.reader(), env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendRawMessageFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, types.ErrServerError) {
+				t.Errorf("expected the server error from the first attempt, got %v", err)
+			}
+			if len(mails) != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", len(mails), tt.wantAttempts)
+			}
+			for i, mail := range mails {
+				if mail != streamTestMail {
+					t.Errorf("attempt %d sent %q, want %q", i+1, mail, streamTestMail)
+				}
+			}
+		})
+	}
+}
+
+func TestSendRawMessageFromValidation(t *testing.T) {
+	client := newRetryTestClient(t, "http://localhost", 0)
+
+	_, err := client.SendRawMessageFrom(context.Background(), strings.NewReader(streamTestMail), types.Envelope{})
+	var validationErr *types.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *types.ValidationError, got %T: %v", err, err)
+	}
+	if len(validationErr.Problems) != 3 {
+		t.Errorf("Problems = %q, want 3 problems", validationErr.Problems)
+	}
+}
+
+func TestSendRawMessageFromSizeLimit(t *testing.T) {
+	var served int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			return
+		}
+		atomic.AddInt32(&served, 1)
+		w.Write([]byte(`{"message_id": "12360", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.MaxMessageSize = 64
+	cfg.MaxRetries = 2
+	cfg.RetryInterval = time.Millisecond
+	// Idempotency keys make every failed send retryable
+	cfg.IdempotencyHeader = DefaultIdempotencyHeader
+	client.WithConfig(cfg)
+	var attempts int32
+	client.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&attempts, 1)
+			return next.RoundTrip(r)
+		})
+	})
+	env := types.Envelope{To: []string{"recipient@example.com"}, From: "sender@example.com"}
+
+	// 48 bytes encode to exactly 64
+	if _, err := client.SendRawMessageFrom(context.Background(), strings.NewReader(strings.Repeat("a", 48)), env); err != nil {
+		t.Fatalf("SendRawMessageFrom() at the limit error = %v", err)
+	}
+
+	_, err = client.SendRawMessageFrom(context.Background(), strings.NewReader(strings.Repeat("a", 49)), env)
+	var tooLarge *types.MessageTooLargeError
+	if !errors.As(err, &tooLarge) || !errors.Is(err, types.ErrMessageTooLarge) {
+		t.Fatalf("SendRawMessageFrom() over the limit error = %T %v, want *types.MessageTooLargeError", err, err)
+	}
+	if tooLarge.Limit != 64 || tooLarge.Size <= 64 {
+		t.Errorf("MessageTooLargeError = %+v", tooLarge)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("attempts = %d, want 2: the oversized stream must not be retried", got)
+	}
+	if got := atomic.LoadInt32(&served); got != 1 {
+		t.Errorf("server received %d complete requests, want only the one at the limit", got)
+	}
+}
+
+func TestSendRawMessageFromRawMailSize(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"message_id": "12360", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, 0)
+	env := types.Envelope{To: []string{"recipient@example.com"}, From: "sender@example.com"}
+
+	// The limit applies to the mail itself, as for SendRawMessage, not to
+	// its larger encoding
+	mail := strings.Repeat("a", validation.MaxRawMailSize)
+	if _, err := client.SendRawMessageFrom(context.Background(), strings.NewReader(mail), env); err != nil {
+		t.Fatalf("SendRawMessageFrom() at MaxRawMailSize error = %v", err)
+	}
+	_, err := client.SendRawMessageFrom(context.Background(), strings.NewReader(mail+"a"), env)
+	var validationErr *types.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("SendRawMessageFrom() over MaxRawMailSize error = %T %v, want *types.ValidationError", err, err)
+	}
+}
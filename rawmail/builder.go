@@ -0,0 +1,313 @@
+// Package rawmail assembles MIME messages for SendRawMessage
+package rawmail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/common/validation"
+)
+
+// RawBuilder assembles a MIME message from text and HTML bodies,
+// attachments and inline images, producing a RawMessage
+type RawBuilder struct {
+	from        string
+	to          []string
+	cc          []string
+	bcc         []string
+	replyTo     string
+	subject     string
+	date        time.Time
+	headers     [][2]string
+	text        string
+	html        string
+	attachments []attachment
+
+	bodyEncoding       Encoding
+	attachmentEncoding Encoding
+}
+
+// attachment is a file attached to the message or, when contentID is set,
+// an inline image referenced from the HTML body as cid:contentID
+type attachment struct {
+	name        string
+	contentType string
+	contentID   string
+	data        []byte
+}
+
+// NewRawBuilder creates an empty RawBuilder
+func NewRawBuilder() *RawBuilder {
+	return &RawBuilder{}
+}
+
+// WithFrom sets the From header and envelope sender
+func (b *RawBuilder) WithFrom(from string) *RawBuilder {
+	b.from = from
+	return b
+}
+
+// WithTo adds To recipients
+func (b *RawBuilder) WithTo(to ...string) *RawBuilder {
+	b.to = append(b.to, to...)
+	return b
+}
+
+// WithCC adds Cc recipients
+func (b *RawBuilder) WithCC(cc ...string) *RawBuilder {
+	b.cc = append(b.cc, cc...)
+	return b
+}
+
+// WithBCC adds Bcc recipients. They are only added to the envelope.
+func (b *RawBuilder) WithBCC(bcc ...string) *RawBuilder {
+	b.bcc = append(b.bcc, bcc...)
+	return b
+}
+
+// WithReplyTo sets the Reply-To header
+func (b *RawBuilder) WithReplyTo(replyTo string) *RawBuilder {
+	b.replyTo = replyTo
+	return b
+}
+
+// WithSubject sets the subject, encoding it if it is not ASCII
+func (b *RawBuilder) WithSubject(subject string) *RawBuilder {
+	b.subject = subject
+	return b
+}
+
+// WithDate sets the Date header. Defaults to the time of Build.
+func (b *RawBuilder) WithDate(date time.Time) *RawBuilder {
+	b.date = date
+	return b
+}
+
+// WithHeader adds a custom header
+func (b *RawBuilder) WithHeader(name, value string) *RawBuilder {
+	b.headers = append(b.headers, [2]string{name, value})
+	return b
+}
+
+// WithText sets the plain text body
+func (b *RawBuilder) WithText(text string) *RawBuilder {
+	b.text = text
+	return b
+}
+
+// WithHTML sets the HTML body
+func (b *RawBuilder) WithHTML(html string) *RawBuilder {
+	b.html = html
+	return b
+}
+
+// WithBodyEncoding overrides the transfer encoding of the text and HTML
+// bodies, which is otherwise chosen from their content
+func (b *RawBuilder) WithBodyEncoding(enc Encoding) *RawBuilder {
+	b.bodyEncoding = enc
+	return b
+}
+
+// WithAttachmentEncoding overrides the transfer encoding of attachments and
+// inline images, which is otherwise chosen from their content type
+func (b *RawBuilder) WithAttachmentEncoding(enc Encoding) *RawBuilder {
+	b.attachmentEncoding = enc
+	return b
+}
+
+// WithAttachment attaches a file
+func (b *RawBuilder) WithAttachment(name, contentType string, data []byte) *RawBuilder {
+	b.attachments = append(b.attachments, attachment{name: name, contentType: contentType, data: data})
+	return b
+}
+
+// WithInline adds an image the HTML body references as cid:contentID
+func (b *RawBuilder) WithInline(contentID, name, contentType string, data []byte) *RawBuilder {
+	b.attachments = append(b.attachments, attachment{name: name, contentType: contentType, contentID: contentID, data: data})
+	return b
+}
+
+// Warnings reports attachment filenames likely to be mangled or rejected
+// by receiving clients and servers. They do not prevent building.
+func (b *RawBuilder) Warnings() []string {
+	var warnings []string
+	for _, att := range b.attachments {
+		if att.contentID == "" || att.name != "" {
+			warnings = append(warnings, validation.AttachmentNameWarnings(att.name)...)
+		}
+	}
+	return warnings
+}
+
+// Build renders the message and returns it with its envelope. Mail holds
+// the plain MIME, which SendRawMessage encodes for the request.
+func (b *RawBuilder) Build() (*types.RawMessage, error) {
+	content, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	from, _ := mail.ParseAddress(b.from)
+	raw := &types.RawMessage{Mail: string(content), From: from.Address}
+	for _, list := range [][]string{b.to, b.cc, b.bcc} {
+		for _, recipient := range list {
+			addr, _ := mail.ParseAddress(recipient)
+			raw.To = append(raw.To, addr.Address)
+		}
+	}
+	return raw, nil
+}
+
+// Bytes renders the message
+func (b *RawBuilder) Bytes() ([]byte, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	date := b.date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var h header
+	h.add("From", formatAddresses(b.from))
+	if len(b.to) > 0 {
+		h.add("To", formatAddresses(b.to...))
+	}
+	if len(b.cc) > 0 {
+		h.add("Cc", formatAddresses(b.cc...))
+	}
+	if b.replyTo != "" {
+		h.add("Reply-To", formatAddresses(b.replyTo))
+	}
+	h.add("Subject", mime.QEncoding.Encode("utf-8", b.subject))
+	h.add("Date", date.Format(time.RFC1123Z))
+	h.add("MIME-Version", "1.0")
+	for _, kv := range b.headers {
+		h.add(kv[0], kv[1])
+	}
+
+	root, err := b.body()
+	if err != nil {
+		return nil, err
+	}
+	body, err := root.render()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	h.write(&buf)
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// validate reports missing or malformed fields
+func (b *RawBuilder) validate() error {
+	var problems []string
+	if b.from == "" {
+		problems = append(problems, "sender (From) is required")
+	} else if _, err := mail.ParseAddress(b.from); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid sender email: %s", b.from))
+	}
+	if len(b.to)+len(b.cc)+len(b.bcc) == 0 {
+		problems = append(problems, "at least one recipient is required")
+	}
+	for _, list := range [][]string{b.to, b.cc, b.bcc} {
+		for _, addr := range list {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid recipient email: %s", addr))
+			}
+		}
+	}
+	if b.replyTo != "" {
+		if _, err := mail.ParseAddress(b.replyTo); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid reply-to email: %s", b.replyTo))
+		}
+	}
+	if b.text == "" && b.html == "" && len(b.attachments) == 0 {
+		problems = append(problems, "a text body, HTML body or attachment is required")
+	}
+	for _, att := range b.attachments {
+		if att.name == "" && att.contentID == "" {
+			problems = append(problems, "attachment name is required")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &types.ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// body arranges the bodies and attachments into the MIME part tree:
+// mixed(related(alternative(text, html), inline...), attachments...)
+func (b *RawBuilder) body() (*part, error) {
+	var bodies []*part
+	for _, text := range [][2]string{{"text/plain", b.text}, {"text/html", b.html}} {
+		if text[1] == "" {
+			continue
+		}
+		p, err := textPart(text[0], text[1], b.bodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, p)
+	}
+
+	var content *part
+	switch len(bodies) {
+	case 1:
+		content = bodies[0]
+	case 2:
+		content = multipartOf("alternative", bodies...)
+	}
+
+	var inline, attached []*part
+	for _, att := range b.attachments {
+		p, err := att.part(b.attachmentEncoding)
+		if err != nil {
+			return nil, err
+		}
+		if att.contentID != "" {
+			inline = append(inline, p)
+		} else {
+			attached = append(attached, p)
+		}
+	}
+
+	if len(inline) > 0 {
+		if content != nil {
+			inline = append([]*part{content}, inline...)
+		}
+		content = multipartOf("related", inline...)
+	}
+	if len(attached) > 0 {
+		if content != nil {
+			attached = append([]*part{content}, attached...)
+		}
+		content = multipartOf("mixed", attached...)
+	}
+	return content, nil
+}
+
+// formatAddresses formats addresses for a header, encoding non-ASCII
+// display names. Addresses that do not parse are used as given.
+func formatAddresses(addresses ...string) string {
+	formatted := make([]string, len(addresses))
+	for i, a := range addresses {
+		if addr, err := mail.ParseAddress(a); err == nil && addr.Name == "" {
+			formatted[i] = addr.Address
+		} else if err == nil {
+			formatted[i] = addr.String()
+		} else {
+			formatted[i] = a
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
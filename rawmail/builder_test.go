@@ -0,0 +1,231 @@
+package rawmail
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// parsedPart is a decoded leaf of a MIME tree
+type parsedPart struct {
+	path        string
+	contentType string
+	header      map[string][]string
+	body        string
+}
+
+// parseMIME flattens a message into its leaf parts, each labelled with the
+// multipart subtypes it is nested in, e.g. "mixed/alternative"
+func parseMIME(t *testing.T, content string) (*mail.Message, []parsedPart) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	var parts []parsedPart
+	walkPart(t, "", msg.Header, msg.Body, &parts)
+	return msg, parts
+}
+
+func walkPart(t *testing.T, path string, header map[string][]string, body io.Reader, parts *[]parsedPart) {
+	t.Helper()
+	contentType := ""
+	if values := header["Content-Type"]; len(values) > 0 {
+		contentType = values[0]
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("ParseMediaType(%q) error = %v", contentType, err)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		path = strings.TrimPrefix(path+"/"+strings.TrimPrefix(mediaType, "multipart/"), "/")
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("NextRawPart() error = %v", err)
+			}
+			walkPart(t, path, p.Header, p, parts)
+		}
+	}
+
+	data, err := io.ReadAll(decoder(header, body))
+	if err != nil {
+		t.Fatalf("decode %s error = %v", mediaType, err)
+	}
+	*parts = append(*parts, parsedPart{path: path, contentType: mediaType, header: header, body: string(data)})
+}
+
+func TestRawBuilder(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	raw, err := NewRawBuilder().
+		WithFrom("Zoë <sender@example.com>").
+		WithTo("to@example.com").
+		WithCC("cc@example.com").
+		WithBCC("bcc@example.com").
+		WithReplyTo("reply@example.com").
+		WithSubject("Grüße").
+		WithDate(date).
+		WithHeader("X-Campaign", "spring").
+		WithText("Hello\nworld").
+		WithHTML(`<p>Hello <img src="cid:logo"></p>`).
+		WithInline("logo", "logo.png", "image/png", []byte{0x89, 'P', 'N', 'G'}).
+		WithAttachment("report.csv", "text/csv", []byte("a,b\n1,2\n")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if raw.From != "sender@example.com" {
+		t.Errorf("From = %q", raw.From)
+	}
+	if got := strings.Join(raw.To, ","); got != "to@example.com,cc@example.com,bcc@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if strings.Contains(raw.Mail, "bcc@example.com") {
+		t.Error("Bcc recipient written to the message headers")
+	}
+
+	msg, parts := parseMIME(t, raw.Mail)
+	dec := new(mime.WordDecoder)
+	if subject, _ := dec.DecodeHeader(msg.Header.Get("Subject")); subject != "Grüße" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if from, err := msg.Header.AddressList("From"); err != nil || from[0].Name != "Zoë" {
+		t.Errorf("From header = %v, %v", from, err)
+	}
+	if got, _ := msg.Header.Date(); !got.Equal(date) {
+		t.Errorf("Date = %v, want %v", got, date)
+	}
+	if msg.Header.Get("X-Campaign") != "spring" || msg.Header.Get("MIME-Version") != "1.0" {
+		t.Errorf("headers = %v", msg.Header)
+	}
+
+	want := []struct{ path, contentType, body string }{
+		{"mixed/related/alternative", "text/plain", "Hello\r\nworld"},
+		{"mixed/related/alternative", "text/html", `<p>Hello <img src="cid:logo"></p>`},
+		{"mixed/related", "image/png", "\x89PNG"},
+		{"mixed", "text/csv", "a,b\r\n1,2\r\n"},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(parts), len(want))
+	}
+	for i, w := range want {
+		if parts[i].path != w.path || parts[i].contentType != w.contentType || parts[i].body != w.body {
+			t.Errorf("part %d = %s %s %q, want %s %s %q", i, parts[i].path, parts[i].contentType, parts[i].body, w.path, w.contentType, w.body)
+		}
+	}
+	if cid := parts[2].header["Content-Id"]; len(cid) == 0 || cid[0] != "<logo>" {
+		t.Errorf("inline Content-ID = %v", cid)
+	}
+	if _, params, _ := mime.ParseMediaType(parts[3].header["Content-Disposition"][0]); params["filename"] != "report.csv" {
+		t.Errorf("attachment filename = %q", params["filename"])
+	}
+}
+
+func TestRawBuilderLayouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *RawBuilder
+		want    []string
+	}{
+		{
+			name:    "text only",
+			builder: NewRawBuilder().WithText("hi"),
+			want:    []string{":text/plain"},
+		},
+		{
+			name:    "html only",
+			builder: NewRawBuilder().WithHTML("<p>hi</p>"),
+			want:    []string{":text/html"},
+		},
+		{
+			name:    "attachment only",
+			builder: NewRawBuilder().WithAttachment("a.bin", "", []byte{1, 2}),
+			want:    []string{"mixed:application/octet-stream"},
+		},
+		{
+			name:    "text and attachment",
+			builder: NewRawBuilder().WithText("hi").WithAttachment("a.txt", "text/plain", []byte("a")),
+			want:    []string{"mixed:text/plain", "mixed:text/plain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := tt.builder.WithFrom("sender@example.com").WithTo("to@example.com").Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			_, parts := parseMIME(t, raw.Mail)
+			var got []string
+			for _, p := range parts {
+				got = append(got, p.path+":"+p.contentType)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("parts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawBuilderValidation(t *testing.T) {
+	_, err := NewRawBuilder().WithTo("not an address").WithReplyTo("bad").Build()
+
+	var verr *types.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Build() error = %v, want ValidationError", err)
+	}
+	want := []string{
+		"sender (From) is required",
+		"invalid recipient email: not an address",
+		"invalid reply-to email: bad",
+		"a text body, HTML body or attachment is required",
+	}
+	if strings.Join(verr.Problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems = %q, want %q", verr.Problems, want)
+	}
+}
+
+// decoder undoes the part's Content-Transfer-Encoding
+func decoder(header map[string][]string, body io.Reader) io.Reader {
+	encoding := ""
+	if values := header["Content-Transfer-Encoding"]; len(values) > 0 {
+		encoding = strings.ToLower(values[0])
+	}
+	switch encoding {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// newlineStripper drops line breaks from wrapped base64
+type newlineStripper struct{ r io.Reader }
+
+func (n *newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	kept := 0
+	for _, c := range p[:count] {
+		if c != '\r' && c != '\n' {
+			p[kept] = c
+			kept++
+		}
+	}
+	return kept, err
+}
@@ -0,0 +1,88 @@
+package rawmail
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Encoding is a Content-Transfer-Encoding
+type Encoding string
+
+const (
+	// EncodingAuto picks an encoding from the content with ChooseEncoding
+	EncodingAuto Encoding = ""
+	// Encoding7Bit sends short-lined ASCII text as is
+	Encoding7Bit Encoding = "7bit"
+	// EncodingQuotedPrintable escapes the bytes of mostly ASCII text
+	EncodingQuotedPrintable Encoding = "quoted-printable"
+	// EncodingBase64 encodes binary content
+	EncodingBase64 Encoding = "base64"
+)
+
+// maxLineLength is the longest line RFC 5322 allows, excluding CRLF
+const maxLineLength = 998
+
+// ChooseEncoding picks the transfer encoding for content of the given type:
+// 7bit for short-lined ASCII text, quoted-printable for text that is mostly
+// ASCII and base64 for everything else
+func ChooseEncoding(contentType string, data []byte) Encoding {
+	if !strings.HasPrefix(strings.ToLower(contentType), "text/") {
+		return EncodingBase64
+	}
+	if is7Bit(data) {
+		return Encoding7Bit
+	}
+
+	escaped := 0
+	for _, c := range data {
+		if c == 0 {
+			return EncodingBase64
+		}
+		if c > 126 || (c < 32 && c != '\r' && c != '\n' && c != '\t') {
+			escaped++
+		}
+	}
+	// Quoted-printable adds two bytes per escaped byte and base64 a third
+	// of the content; pick whichever is smaller
+	if escaped*6 > len(data) {
+		return EncodingBase64
+	}
+	return EncodingQuotedPrintable
+}
+
+// is7Bit returns true if data is ASCII without NULs or bare CR and LF, in
+// lines no longer than RFC 5322 allows
+func is7Bit(data []byte) bool {
+	for i, c := range data {
+		if c == 0 || c > 127 {
+			return false
+		}
+		if c == '\r' && (i+1 == len(data) || data[i+1] != '\n') {
+			return false
+		}
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSuffix(line, []byte("\r"))) > maxLineLength {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveEncoding returns the encoding to use for a part, checking that an
+// explicit 7bit override can carry the content
+func resolveEncoding(override Encoding, contentType string, data []byte) (Encoding, error) {
+	switch override {
+	case EncodingAuto:
+		return ChooseEncoding(contentType, data), nil
+	case Encoding7Bit:
+		if !is7Bit(data) {
+			return "", fmt.Errorf("rawmail: %s content cannot be sent as 7bit", contentType)
+		}
+		return override, nil
+	case EncodingQuotedPrintable, EncodingBase64:
+		return override, nil
+	}
+	return "", fmt.Errorf("rawmail: unknown encoding %q", override)
+}
@@ -0,0 +1,80 @@
+package rawmail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChooseEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		want        Encoding
+	}{
+		{"ascii text", "text/plain", "Hello\r\nworld\n", Encoding7Bit},
+		{"accented text", "text/plain", "Viele Grüße aus Köln und bis bald", EncodingQuotedPrintable},
+		{"long ascii line", "text/html", strings.Repeat("a", 1000), EncodingQuotedPrintable},
+		{"bare carriage return", "text/plain", "a\rb", EncodingQuotedPrintable},
+		{"mostly non-ascii text", "text/plain", "日本語のテキスト", EncodingBase64},
+		{"nul byte", "text/plain", "a\x00b", EncodingBase64},
+		{"binary", "image/png", "plain looking", EncodingBase64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChooseEncoding(tt.contentType, []byte(tt.data)); got != tt.want {
+				t.Errorf("ChooseEncoding() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawBuilderEncodings(t *testing.T) {
+	build := func(b *RawBuilder) (string, []parsedPart, error) {
+		raw, err := b.WithFrom("sender@example.com").WithTo("to@example.com").Build()
+		if err != nil {
+			return "", nil, err
+		}
+		_, parts := parseMIME(t, raw.Mail)
+		return raw.Mail, parts, nil
+	}
+	encodingOf := func(p parsedPart) string {
+		return p.header["Content-Transfer-Encoding"][0]
+	}
+
+	content, parts, err := build(NewRawBuilder().WithText("Viele Grüße und bis bald").WithHTML("<p>hi</p>"))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if encodingOf(parts[0]) != "quoted-printable" || encodingOf(parts[1]) != "7bit" {
+		t.Errorf("encodings = %s, %s", encodingOf(parts[0]), encodingOf(parts[1]))
+	}
+	if parts[0].body != "Viele Grüße und bis bald" {
+		t.Errorf("decoded text = %q", parts[0].body)
+	}
+	for _, c := range []byte(content) {
+		if c > 127 {
+			t.Fatal("generated message contains 8bit data")
+		}
+	}
+
+	_, parts, err = build(NewRawBuilder().WithText("hello").WithBodyEncoding(EncodingBase64).
+		WithAttachment("a.txt", "text/plain", []byte("x")).WithAttachmentEncoding(EncodingQuotedPrintable))
+	if err != nil {
+		t.Fatalf("Build() with overrides error = %v", err)
+	}
+	if encodingOf(parts[0]) != "base64" || parts[0].body != "hello" {
+		t.Errorf("body part = %s %q, want base64 override", encodingOf(parts[0]), parts[0].body)
+	}
+	if encodingOf(parts[1]) != "quoted-printable" {
+		t.Errorf("attachment encoding = %s, want quoted-printable override", encodingOf(parts[1]))
+	}
+
+	if _, _, err := build(NewRawBuilder().WithText("Grüße").WithBodyEncoding(Encoding7Bit)); err == nil {
+		t.Error("Build() error = nil for non-ASCII text forced to 7bit")
+	}
+	if _, _, err := build(NewRawBuilder().WithText("hi").WithBodyEncoding("8bit")); err == nil {
+		t.Error("Build() error = nil for an unknown encoding")
+	}
+}
@@ -0,0 +1,84 @@
+package rawmail
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// maxParamSegment is the longest encoded RFC 2231 continuation segment, so
+// long filenames can be folded across lines
+const maxParamSegment = 60
+
+// filenameParam formats a filename parameter. ASCII names are quoted;
+// others are percent-encoded per RFC 2231 (as profiled by RFC 5987) and
+// split into numbered continuations when long.
+func filenameParam(param, filename string) string {
+	if isPlainASCII(filename) {
+		return param + "=" + quoteParam(filename)
+	}
+
+	encoded := percentEncode(filename)
+	if len(encoded) <= maxParamSegment {
+		return param + "*=utf-8''" + encoded
+	}
+
+	var segments []string
+	for i := 0; encoded != ""; i++ {
+		n := maxParamSegment
+		if n > len(encoded) {
+			n = len(encoded)
+		}
+		// Do not split a %XX escape across segments
+		if j := strings.LastIndexByte(encoded[:n], '%'); j >= 0 && j > n-3 && n < len(encoded) {
+			n = j
+		}
+		prefix := ""
+		if i == 0 {
+			prefix = "utf-8''"
+		}
+		segments = append(segments, fmt.Sprintf("%s*%d*=%s%s", param, i, prefix, encoded[:n]))
+		encoded = encoded[n:]
+	}
+	return strings.Join(segments, "; ")
+}
+
+// nameParam formats the legacy Content-Type name parameter, which older
+// clients read instead of the disposition filename. Non-ASCII names use
+// RFC 2047 encoded words, as those clients expect.
+func nameParam(filename string) string {
+	if isPlainASCII(filename) {
+		return "name=" + quoteParam(filename)
+	}
+	return "name=" + quoteParam(mime.QEncoding.Encode("utf-8", filename))
+}
+
+// isPlainASCII returns true if s contains only printable ASCII
+func isPlainASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteParam returns s as a MIME quoted-string
+func quoteParam(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// percentEncode encodes every byte outside the RFC 5987 attr-char set
+func percentEncode(s string) string {
+	const attrChars = "!#$&+-.^_`|~"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(attrChars, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
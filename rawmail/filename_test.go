@@ -0,0 +1,87 @@
+package rawmail
+
+import (
+	"mime"
+	"strings"
+	"testing"
+)
+
+func TestFilenameParam(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"ascii", "report.pdf", `filename="report.pdf"`},
+		{"quotes escaped", `say "hi".txt`, `filename="say \"hi\".txt"`},
+		{"non-ascii", "résumé.pdf", `filename*=utf-8''r%C3%A9sum%C3%A9.pdf`},
+		{"space encoded", "naïve plan.txt", `filename*=utf-8''na%C3%AFve%20plan.txt`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filenameParam("filename", tt.filename); got != tt.want {
+				t.Errorf("filenameParam() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilenameRoundTrip(t *testing.T) {
+	for _, filename := range []string{
+		"report.pdf",
+		"résumé.pdf",
+		`quote "and" back\slash.txt`,
+		"日本語のファイル名と長い説明文が含まれているドキュメント.docx",
+		strings.Repeat("é", 40) + ".txt",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			raw, err := NewRawBuilder().
+				WithFrom("sender@example.com").
+				WithTo("to@example.com").
+				WithText("see attached").
+				WithAttachment(filename, "application/octet-stream", []byte("data")).
+				Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			_, parts := parseMIME(t, raw.Mail)
+			att := parts[1]
+
+			_, params, err := mime.ParseMediaType(att.header["Content-Disposition"][0])
+			if err != nil {
+				t.Fatalf("ParseMediaType() error = %v", err)
+			}
+			if params["filename"] != filename {
+				t.Errorf("disposition filename = %q, want %q", params["filename"], filename)
+			}
+
+			_, params, err = mime.ParseMediaType(att.header["Content-Type"][0])
+			if err != nil {
+				t.Fatalf("ParseMediaType() error = %v", err)
+			}
+			name, err := new(mime.WordDecoder).DecodeHeader(params["name"])
+			if err != nil || name != filename {
+				t.Errorf("content type name = %q (%v), want %q", name, err, filename)
+			}
+
+			for _, line := range strings.Split(raw.Mail, "\r\n") {
+				if len(line) > maxLineLength {
+					t.Errorf("line of %d characters exceeds %d", len(line), maxLineLength)
+				}
+			}
+		})
+	}
+}
+
+func TestRawBuilderWarnings(t *testing.T) {
+	b := NewRawBuilder().
+		WithAttachment("résumé.pdf", "application/pdf", nil).
+		WithAttachment("invoice.exe", "application/octet-stream", nil).
+		WithInline("logo", "", "image/png", nil)
+
+	warnings := b.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "invoice.exe") {
+		t.Errorf("Warnings() = %q, want one for invoice.exe", warnings)
+	}
+}
@@ -0,0 +1,37 @@
+package rawmail
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func FuzzRawBuilder(f *testing.F) {
+	f.Add("Sender <sender@example.com>", "recipient@example.com", "Subject", "text", "<p>html</p>", "report.pdf", []byte("data"))
+	f.Add("", "", "", "", "", "", []byte(nil))
+	f.Add("a@b", "c@d", "\r\nBcc: evil@example.com", "line\rbreak", "", "näme\"\\.txt", []byte{0xff, 0x00})
+	f.Add("x@example.com", "y@example.com", strings.Repeat("long ", 100), strings.Repeat("x", 2000), "", strings.Repeat("é", 100), []byte("=\r\n"))
+
+	f.Fuzz(func(t *testing.T, from, to, subject, text, html, filename string, data []byte) {
+		b := NewRawBuilder().
+			WithFrom(from).
+			WithTo(to).
+			WithSubject(subject).
+			WithText(text).
+			WithHTML(html).
+			WithAttachment(filename, "application/octet-stream", data)
+		b.Warnings()
+		raw, err := b.Build()
+		if err != nil {
+			return
+		}
+		// Whatever the input, the output must parse and must not gain headers
+		msg, err := mail.ReadMessage(strings.NewReader(raw.Mail))
+		if err != nil {
+			t.Fatalf("built message does not parse: %v", err)
+		}
+		if len(msg.Header["Bcc"]) > 0 {
+			t.Errorf("built message has a Bcc header injected through its fields")
+		}
+	})
+}
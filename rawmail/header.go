@@ -0,0 +1,53 @@
+package rawmail
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// foldLength is the line length RFC 5322 recommends; longer values are
+// folded at whitespace
+const foldLength = 78
+
+// headerAcronyms restores the casing RFC documents use for header names
+// that textproto canonicalizes differently
+var headerAcronyms = map[string]string{
+	"Message-Id":     "Message-ID",
+	"Mime-Version":   "MIME-Version",
+	"Content-Id":     "Content-ID",
+	"Dkim-Signature": "DKIM-Signature",
+	"List-Id":        "List-ID",
+}
+
+// canonicalName returns the conventional casing of a header name, e.g.
+// "message-id" becomes "Message-ID"
+func canonicalName(name string) string {
+	name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+	if acronym, ok := headerAcronyms[name]; ok {
+		return acronym
+	}
+	return name
+}
+
+// formatField renders a header field, folding it at whitespace so lines
+// stay within foldLength where possible. Line breaks in value are replaced
+// so a value cannot inject further fields.
+func formatField(name, value string) string {
+	value = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+
+	var b strings.Builder
+	b.WriteString(name + ":")
+	lineLen := len(name) + 1
+	for i, word := range strings.Split(value, " ") {
+		// The first word always follows the colon; later words move to a
+		// continuation line when they would overflow this one
+		if i > 0 && lineLen+1+len(word) > foldLength {
+			b.WriteString("\r\n")
+			lineLen = 0
+		}
+		b.WriteString(" " + word)
+		lineLen += 1 + len(word)
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
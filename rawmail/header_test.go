@@ -0,0 +1,140 @@
+package rawmail
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the reference fixtures in testdata")
+
+func TestCanonicalName(t *testing.T) {
+	tests := map[string]string{
+		"message-id":     "Message-ID",
+		"mime-version":   "MIME-Version",
+		"CONTENT-TYPE":   "Content-Type",
+		"x-custom-thing": "X-Custom-Thing",
+		"dkim-signature": "DKIM-Signature",
+		" list-id ":      "List-ID",
+	}
+	for name, want := range tests {
+		if got := canonicalName(name); got != want {
+			t.Errorf("canonicalName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFormatField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		value string
+		want  string
+	}{
+		{"short", "Subject", "Hello", "Subject: Hello\r\n"},
+		{
+			name:  "folded at whitespace",
+			field: "To",
+			value: strings.Repeat("someone@example.com, ", 4) + "last@example.com",
+			want: "To: someone@example.com, someone@example.com, someone@example.com,\r\n" +
+				" someone@example.com, last@example.com\r\n",
+		},
+		{
+			name:  "unbreakable word kept whole",
+			field: "X-Token",
+			value: strings.Repeat("a", 100),
+			want:  "X-Token: " + strings.Repeat("a", 100) + "\r\n",
+		},
+		{
+			name:  "line breaks cannot inject fields",
+			field: "X-Note",
+			value: "one\r\nBcc: victim@example.com",
+			want:  "X-Note: one Bcc: victim@example.com\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatField(tt.field, tt.value)
+			if got != tt.want {
+				t.Errorf("formatField() = %q, want %q", got, tt.want)
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+				if len(line) > maxLineLength {
+					t.Errorf("line of %d characters exceeds %d", len(line), maxLineLength)
+				}
+			}
+		})
+	}
+}
+
+func TestRawBuilderFixtures(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	var recipients []string
+	for i := 0; i < 6; i++ {
+		recipients = append(recipients, "Recipient Number "+string(rune('A'+i))+" <recipient"+string(rune('a'+i))+"@example.com>")
+	}
+
+	tests := []struct {
+		fixture string
+		builder *RawBuilder
+	}{
+		{
+			fixture: "folded.eml",
+			builder: NewRawBuilder().
+				WithFrom("Sender <sender@example.com>").
+				WithTo(recipients...).
+				WithSubject("Quarterly report: revenue, churn, retention and everything else you asked for").
+				WithDate(date).
+				WithHeader("message-id", "<20240501120000.abc@example.com>").
+				WithHeader("x-long-header", strings.TrimSpace(strings.Repeat("token ", 30))).
+				WithText("Hello,\nthe report is attached.\n"),
+		},
+		{
+			fixture: "encoded.eml",
+			builder: NewRawBuilder().
+				WithFrom("Zoë Müller <zoe@example.com>").
+				WithTo("Björn <bjorn@example.com>").
+				WithSubject("Ünïcödé subject that is long enough to need more than one encoded word").
+				WithDate(date).
+				WithText("Grüße aus Köln, wir sehen uns nächste Woche.\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := tt.builder.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes() error = %v", err)
+			}
+			path := filepath.Join("testdata", tt.fixture)
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s:\n%s", path, got)
+			}
+
+			head, _, _ := strings.Cut(string(got), "\r\n\r\n")
+			for _, line := range strings.Split(head, "\r\n") {
+				value := line
+				if !strings.HasPrefix(line, " ") {
+					_, value, _ = strings.Cut(line, ":")
+				}
+				if len(line) > foldLength && len(strings.Fields(value)) > 1 {
+					t.Errorf("foldable line of %d characters: %q", len(line), line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package rawmail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"mime/quotedprintable"
+	"strings"
+)
+
+// header is an ordered list of header fields
+type header [][2]string
+
+// add appends a header field
+func (h *header) add(name, value string) {
+	*h = append(*h, [2]string{name, value})
+}
+
+// write writes the fields with canonical names, folding long values.
+// Callers end the header with a blank line.
+func (h header) write(buf *bytes.Buffer) {
+	for _, kv := range h {
+		buf.WriteString(formatField(canonicalName(kv[0]), kv[1]))
+	}
+}
+
+// part is a node in the MIME tree: either a leaf with a body or a
+// multipart container with children
+type part struct {
+	header   header
+	body     []byte
+	subtype  string
+	children []*part
+}
+
+// textPart returns a UTF-8 text part
+func textPart(contentType, text string, override Encoding) (*part, error) {
+	enc, err := resolveEncoding(override, contentType, []byte(text))
+	if err != nil {
+		return nil, err
+	}
+	p := &part{body: []byte(text)}
+	p.header.add("Content-Type", contentType+"; charset=utf-8")
+	p.header.add("Content-Transfer-Encoding", string(enc))
+	return p, nil
+}
+
+// multipartOf returns a multipart/subtype container
+func multipartOf(subtype string, children ...*part) *part {
+	return &part{subtype: subtype, children: children}
+}
+
+// part returns the MIME part for an attachment or inline image
+func (a attachment) part(override Encoding) (*part, error) {
+	contentType := a.contentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	enc, err := resolveEncoding(override, contentType, a.data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &part{body: a.data}
+	if a.name != "" {
+		p.header.add("Content-Type", contentType+"; "+nameParam(a.name))
+	} else {
+		p.header.add("Content-Type", contentType)
+	}
+	p.header.add("Content-Transfer-Encoding", string(enc))
+	if a.contentID != "" {
+		p.header.add("Content-ID", "<"+a.contentID+">")
+		p.header.add("Content-Disposition", disposition("inline", a.name))
+	} else {
+		p.header.add("Content-Disposition", disposition("attachment", a.name))
+	}
+	return p, nil
+}
+
+// disposition formats a Content-Disposition value with an optional filename
+func disposition(kind, filename string) string {
+	if filename == "" {
+		return kind
+	}
+	return kind + "; " + filenameParam("filename", filename)
+}
+
+// render returns the part's header fields, the blank line ending them and
+// the encoded body
+func (p *part) render() ([]byte, error) {
+	var buf bytes.Buffer
+	if p.subtype != "" {
+		boundary, err := newBoundary()
+		if err != nil {
+			return nil, err
+		}
+		h := header{{"Content-Type", "multipart/" + p.subtype + "; boundary=\"" + boundary + "\""}}
+		h.write(&buf)
+		buf.WriteString("\r\n")
+		for _, child := range p.children {
+			buf.WriteString("--" + boundary + "\r\n")
+			content, err := child.render()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(content)
+			buf.WriteString("\r\n")
+		}
+		buf.WriteString("--" + boundary + "--\r\n")
+		return buf.Bytes(), nil
+	}
+
+	p.header.write(&buf)
+	buf.WriteString("\r\n")
+	if err := encodeBody(&buf, p.header.get("Content-Transfer-Encoding"), p.body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// get returns the first value of the named field
+func (h header) get(name string) string {
+	for _, kv := range h {
+		if strings.EqualFold(kv[0], name) {
+			return kv[1]
+		}
+	}
+	return ""
+}
+
+// encodeBody writes body using the given transfer encoding
+func encodeBody(buf *bytes.Buffer, encoding string, body []byte) error {
+	switch encoding {
+	case "base64":
+		encoded := base64.StdEncoding.EncodeToString(body)
+		for len(encoded) > 76 {
+			buf.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		buf.WriteString(encoded)
+		return nil
+	case "quoted-printable":
+		qp := quotedprintable.NewWriter(buf)
+		if _, err := qp.Write(body); err != nil {
+			return err
+		}
+		return qp.Close()
+	}
+	// 7bit content keeps its text but lines must end in CRLF
+	buf.Write(bytes.ReplaceAll(bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n")))
+	return nil
+}
+
+// newBoundary returns a random multipart boundary
+func newBoundary() (string, error) {
+	var b [15]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "postal-" + hex.EncodeToString(b[:]), nil
+}
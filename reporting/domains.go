@@ -0,0 +1,131 @@
+package reporting
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sachin-duhan/postal-go/common/validation"
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+// SuccessRate returns the fraction of sends that were accepted
+func (c Counts) SuccessRate() float64 {
+	return ratio(c.Sent, c.Sent+c.Failed)
+}
+
+// DeliveryRate returns delivered recipients as a fraction of accepted sends
+func (c Counts) DeliveryRate() float64 {
+	return ratio(c.Delivered, c.Sent)
+}
+
+// DeferralRate returns deferrals as a fraction of accepted sends. A
+// recipient deferred several times counts each time.
+func (c Counts) DeferralRate() float64 {
+	return ratio(c.Deferred, c.Sent)
+}
+
+// BounceRate returns bounces as a fraction of accepted sends
+func (c Counts) BounceRate() float64 {
+	return ratio(c.Bounced, c.Sent)
+}
+
+// ratio returns n/d, or 0 when d is 0
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// DomainStats keeps running counts per recipient domain, since inbox
+// providers throttle and filter per domain. It is safe for concurrent use.
+// Register it with the client's WithDomainStats and feed it webhook events
+// with RecordEvent.
+type DomainStats struct {
+	mu      sync.Mutex
+	domains map[string]*Counts
+}
+
+// NewDomainStats creates an empty DomainStats
+func NewDomainStats() *DomainStats {
+	return &DomainStats{domains: make(map[string]*Counts)}
+}
+
+// RecordResult counts a send for each of its recipients' domains
+func (s *DomainStats) RecordResult(r *resultstore.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rcpt := range r.Recipients {
+		if domain := validation.AddressDomain(rcpt); domain != "" {
+			c := row(s.domains, domain)
+			if r.Failed() {
+				c.Failed++
+			} else {
+				c.Sent++
+			}
+		}
+	}
+}
+
+// RecordEvent counts a delivery event for its recipient's domain. Opens are
+// counted every time, unlike in Build.
+func (s *DomainStats) RecordEvent(ev Event) {
+	domain := validation.AddressDomain(ev.Recipient)
+	if domain == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := row(s.domains, domain)
+	switch ev.Type {
+	case EventDelivered:
+		c.Delivered++
+	case EventDeferred:
+		c.Deferred++
+	case EventBounced:
+		c.Bounced++
+	case EventOpened:
+		c.Opened++
+	}
+}
+
+// Domain returns the counts for domain
+func (s *DomainStats) Domain(domain string) Counts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.domains[domain]; ok {
+		return *c
+	}
+	return Counts{}
+}
+
+// Domains returns the domains seen so far, sorted
+func (s *DomainStats) Domains() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	domains := make([]string, 0, len(s.domains))
+	for d := range s.domains {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// Snapshot returns a copy of the counts for every domain
+func (s *DomainStats) Snapshot() map[string]Counts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := make(map[string]Counts, len(s.domains))
+	for d, c := range s.domains {
+		snap[d] = *c
+	}
+	return snap
+}
+
+// Reset clears all counts
+func (s *DomainStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.domains = make(map[string]*Counts)
+}
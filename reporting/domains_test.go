@@ -0,0 +1,65 @@
+package reporting
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+func TestCountsRates(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		counts                               Counts
+		success, delivery, deferral, bounces float64
+	}{
+		{"empty", Counts{}, 0, 0, 0, 0},
+		{"mixed", Counts{Sent: 8, Failed: 2, Delivered: 6, Deferred: 4, Bounced: 2}, 0.8, 0.75, 0.5, 0.25},
+		{"all failed", Counts{Failed: 3}, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.counts
+			if c.SuccessRate() != tt.success || c.DeliveryRate() != tt.delivery || c.DeferralRate() != tt.deferral || c.BounceRate() != tt.bounces {
+				t.Errorf("rates = %v %v %v %v, want %v %v %v %v",
+					c.SuccessRate(), c.DeliveryRate(), c.DeferralRate(), c.BounceRate(),
+					tt.success, tt.delivery, tt.deferral, tt.bounces)
+			}
+		})
+	}
+}
+
+func TestDomainStats(t *testing.T) {
+	stats := NewDomainStats()
+	records, events := testData()
+
+	var wg sync.WaitGroup
+	for i := range records {
+		wg.Add(1)
+		go func(r *resultstore.Record) {
+			defer wg.Done()
+			stats.RecordResult(r)
+		}(&records[i])
+	}
+	wg.Wait()
+	for _, ev := range events {
+		stats.RecordEvent(ev)
+	}
+	stats.RecordEvent(Event{Type: EventBounced, Recipient: "not an address"})
+
+	if got, want := stats.Domains(), []string{"gmail.com", "yahoo.com"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Domains() = %v, want %v", got, want)
+	}
+	gmail := Counts{Sent: 3, Failed: 1, Delivered: 2, Deferred: 1, Opened: 3}
+	if got := stats.Domain("gmail.com"); got != gmail {
+		t.Errorf("Domain(gmail.com) = %+v, want %+v", got, gmail)
+	}
+	if got := stats.Snapshot()["yahoo.com"]; got.BounceRate() != 1 {
+		t.Errorf("yahoo.com BounceRate() = %v, want 1", got.BounceRate())
+	}
+
+	stats.Reset()
+	if len(stats.Domains()) != 0 {
+		t.Error("Reset() kept domains")
+	}
+}
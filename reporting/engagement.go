@@ -0,0 +1,117 @@
+package reporting
+
+import (
+	"time"
+
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+// Engagement counts opens and clicks. Unique counts include each recipient
+// once; Recipients is the number of recipients sent to, when known.
+type Engagement struct {
+	Recipients   int            `json:"recipients"`
+	Opens        int            `json:"opens"`
+	UniqueOpens  int            `json:"unique_opens"`
+	Clicks       int            `json:"clicks"`
+	UniqueClicks int            `json:"unique_clicks"`
+	Links        map[string]int `json:"links,omitempty"`
+}
+
+// OpenRate returns the fraction of recipients who opened, or 0 when the
+// number of recipients is unknown
+func (e *Engagement) OpenRate() float64 {
+	return ratio(e.UniqueOpens, e.Recipients)
+}
+
+// ClickRate returns the fraction of recipients who clicked a link, or 0
+// when the number of recipients is unknown
+func (e *Engagement) ClickRate() float64 {
+	return ratio(e.UniqueClicks, e.Recipients)
+}
+
+// EngagementReport summarizes opens and clicks over a period
+type EngagementReport struct {
+	Since     time.Time              `json:"since"`
+	Until     time.Time              `json:"until"`
+	Total     Engagement             `json:"total"`
+	ByMessage map[string]*Engagement `json:"by_message"`
+	ByTag     map[string]*Engagement `json:"by_tag"`
+}
+
+// BuildEngagement aggregates the open and click events in [since, until)
+// per message and per tag. Records supply each message's tag and, for
+// successful sends in the period, the recipient counts rates are based on.
+func BuildEngagement(records []resultstore.Record, events []Event, since, until time.Time) *EngagementReport {
+	r := &EngagementReport{
+		Since:     since,
+		Until:     until,
+		ByMessage: make(map[string]*Engagement),
+		ByTag:     make(map[string]*Engagement),
+	}
+
+	tags := make(map[string]string, len(records))
+	for _, rec := range records {
+		if rec.MessageID != "" {
+			tags[rec.MessageID] = rec.Tag
+		}
+		if rec.Failed() || !inPeriod(rec.CreatedAt, since, until) {
+			continue
+		}
+		n := len(rec.Recipients)
+		r.add(rec.MessageID, rec.Tag, func(e *Engagement) { e.Recipients += n })
+	}
+
+	// Recipients are counted per message, so a recipient is unique once per
+	// message they opened or clicked, matching how Recipients is summed
+	seen := make(map[[3]string]bool)
+	for _, ev := range events {
+		if (ev.Type != EventOpened && ev.Type != EventClicked) || !inPeriod(ev.Time, since, until) {
+			continue
+		}
+		key := [3]string{string(ev.Type), ev.MessageID, ev.Recipient}
+		unique := !seen[key]
+		seen[key] = true
+
+		r.add(ev.MessageID, tags[ev.MessageID], func(e *Engagement) {
+			if ev.Type == EventOpened {
+				e.Opens++
+				if unique {
+					e.UniqueOpens++
+				}
+				return
+			}
+			e.Clicks++
+			if unique {
+				e.UniqueClicks++
+			}
+			if ev.URL != "" {
+				if e.Links == nil {
+					e.Links = make(map[string]int)
+				}
+				e.Links[ev.URL]++
+			}
+		})
+	}
+	return r
+}
+
+// add applies count to the total and to the rows for messageID and tag
+func (r *EngagementReport) add(messageID, tag string, count func(*Engagement)) {
+	count(&r.Total)
+	if messageID != "" {
+		count(engagementRow(r.ByMessage, messageID))
+	}
+	if tag != "" {
+		count(engagementRow(r.ByTag, tag))
+	}
+}
+
+// engagementRow returns the engagement for key, creating it if needed
+func engagementRow(rows map[string]*Engagement, key string) *Engagement {
+	e, ok := rows[key]
+	if !ok {
+		e = &Engagement{}
+		rows[key] = e
+	}
+	return e
+}
@@ -0,0 +1,53 @@
+package reporting
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildEngagement(t *testing.T) {
+	records, events := testData()
+	events = append(events,
+		Event{Type: EventClicked, MessageID: "1", Recipient: "a@gmail.com", URL: "https://example.com/start", Time: base.Add(4 * time.Hour)},
+		Event{Type: EventClicked, MessageID: "1", Recipient: "a@gmail.com", URL: "https://example.com/start", Time: base.Add(6 * time.Hour)},
+		Event{Type: EventClicked, MessageID: "2", Recipient: "c@gmail.com", URL: "https://example.com/pay", Time: base.Add(4 * time.Hour)},
+		// Outside the period
+		Event{Type: EventClicked, MessageID: "2", Recipient: "c@gmail.com", URL: "https://example.com/pay", Time: base.Add(48 * time.Hour)},
+	)
+	r := BuildEngagement(records, events, base, base.Add(24*time.Hour))
+
+	tests := []struct {
+		name string
+		got  *Engagement
+		want Engagement
+	}{
+		{"total", &r.Total, Engagement{Recipients: 3, Opens: 3, UniqueOpens: 2, Clicks: 3, UniqueClicks: 2}},
+		{"message 1", r.ByMessage["1"], Engagement{Recipients: 2, Opens: 2, UniqueOpens: 1, Clicks: 2, UniqueClicks: 1}},
+		{"message 0", r.ByMessage["0"], Engagement{Opens: 1, UniqueOpens: 1}},
+		{"tag welcome", r.ByTag["welcome"], Engagement{Recipients: 2, Opens: 3, UniqueOpens: 2, Clicks: 2, UniqueClicks: 1}},
+		{"tag invoice", r.ByTag["invoice"], Engagement{Recipients: 1, Clicks: 1, UniqueClicks: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got == nil {
+				t.Fatal("row missing")
+			}
+			got := *tt.got
+			got.Links = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := r.ByMessage["1"].Links["https://example.com/start"]; got != 2 {
+		t.Errorf("clicks on /start = %d, want 2", got)
+	}
+	if got := r.ByTag["invoice"].ClickRate(); got != 1 {
+		t.Errorf("invoice ClickRate() = %v, want 1", got)
+	}
+	if got := r.ByTag["welcome"].OpenRate(); got != 1 {
+		t.Errorf("welcome OpenRate() = %v, want 1", got)
+	}
+}
@@ -0,0 +1,206 @@
+// Package reporting aggregates send results and delivery events over a
+// period into a report that can be rendered as JSON or CSV
+package reporting
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/validation"
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+// EventType is the kind of a delivery event
+type EventType string
+
+// Delivery event types
+const (
+	EventDelivered EventType = "delivered"
+	EventDeferred  EventType = "deferred"
+	EventBounced   EventType = "bounced"
+	EventOpened    EventType = "opened"
+	EventClicked   EventType = "clicked"
+)
+
+// Event is something that happened to a sent message after the send, as
+// reported by webhooks or inbound processing. Postal's MessageLoaded and
+// MessageLinkClicked webhooks map to EventOpened and EventClicked.
+type Event struct {
+	Type      EventType
+	MessageID string
+	Recipient string
+	Time      time.Time
+	// URL is the link that was followed, for EventClicked
+	URL string
+}
+
+// Counts holds the totals for one row of a report. Sent and Failed count
+// recipients of successful and failed sends; Opened counts each recipient
+// of a message at most once.
+type Counts struct {
+	Sent      int `json:"sent"`
+	Failed    int `json:"failed"`
+	Delivered int `json:"delivered"`
+	Deferred  int `json:"deferred"`
+	Bounced   int `json:"bounced"`
+	Opened    int `json:"opened"`
+}
+
+// Report summarizes sending over a period
+type Report struct {
+	Since    time.Time          `json:"since"`
+	Until    time.Time          `json:"until"`
+	Total    Counts             `json:"total"`
+	ByTag    map[string]*Counts `json:"by_tag"`
+	ByDomain map[string]*Counts `json:"by_domain"`
+}
+
+// Build aggregates the records and events created in [since, until). Events
+// are attributed to the tag of the record with the same message ID.
+func Build(records []resultstore.Record, events []Event, since, until time.Time) *Report {
+	r := &Report{
+		Since:    since,
+		Until:    until,
+		ByTag:    make(map[string]*Counts),
+		ByDomain: make(map[string]*Counts),
+	}
+
+	tags := make(map[string]string, len(records))
+	for _, rec := range records {
+		if rec.MessageID != "" {
+			tags[rec.MessageID] = rec.Tag
+		}
+		if !inPeriod(rec.CreatedAt, since, until) {
+			continue
+		}
+		for _, rcpt := range rec.Recipients {
+			r.add(rec.Tag, rcpt, func(c *Counts) {
+				if rec.Failed() {
+					c.Failed++
+				} else {
+					c.Sent++
+				}
+			})
+		}
+	}
+
+	opened := make(map[[2]string]bool)
+	for _, ev := range events {
+		if !inPeriod(ev.Time, since, until) {
+			continue
+		}
+		var count func(*Counts)
+		switch ev.Type {
+		case EventDelivered:
+			count = func(c *Counts) { c.Delivered++ }
+		case EventDeferred:
+			count = func(c *Counts) { c.Deferred++ }
+		case EventBounced:
+			count = func(c *Counts) { c.Bounced++ }
+		case EventOpened:
+			key := [2]string{ev.MessageID, ev.Recipient}
+			if opened[key] {
+				continue
+			}
+			opened[key] = true
+			count = func(c *Counts) { c.Opened++ }
+		default:
+			continue
+		}
+		r.add(tags[ev.MessageID], ev.Recipient, count)
+	}
+	return r
+}
+
+// FromStore builds a report from the records store holds for the period
+func FromStore(ctx context.Context, store resultstore.Store, events []Event, since, until time.Time) (*Report, error) {
+	records, err := store.Query(ctx, resultstore.Filter{Since: since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+	return Build(records, events, since, until), nil
+}
+
+// add applies count to the total and to the rows for tag and the
+// recipient's domain
+func (r *Report) add(tag, recipient string, count func(*Counts)) {
+	count(&r.Total)
+	if tag != "" {
+		count(row(r.ByTag, tag))
+	}
+	if domain := validation.AddressDomain(recipient); domain != "" {
+		count(row(r.ByDomain, domain))
+	}
+}
+
+// row returns the counts for key, creating them if needed
+func row(rows map[string]*Counts, key string) *Counts {
+	c, ok := rows[key]
+	if !ok {
+		c = &Counts{}
+		rows[key] = c
+	}
+	return c
+}
+
+// inPeriod reports whether t is in [since, until). Zero bounds are open.
+func inPeriod(t, since, until time.Time) bool {
+	return (since.IsZero() || !t.Before(since)) && (until.IsZero() || t.Before(until))
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// csvHeader is the header row written by WriteCSV
+var csvHeader = []string{"group", "key", "sent", "failed", "delivered", "deferred", "bounced", "opened"}
+
+// WriteCSV writes one row for the total, then one per tag and per domain,
+// each sorted by key
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	if err := cw.Write(csvRow("total", "", &r.Total)); err != nil {
+		return err
+	}
+	for _, group := range []struct {
+		name string
+		rows map[string]*Counts
+	}{{"tag", r.ByTag}, {"domain", r.ByDomain}} {
+		keys := make([]string, 0, len(group.rows))
+		for k := range group.rows {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := cw.Write(csvRow(group.name, k, group.rows[k])); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// csvRow formats counts as a CSV record
+func csvRow(group, key string, c *Counts) []string {
+	row := []string{group, key}
+	for _, n := range []int{c.Sent, c.Failed, c.Delivered, c.Deferred, c.Bounced, c.Opened} {
+		row = append(row, strconv.Itoa(n))
+	}
+	return row
+}
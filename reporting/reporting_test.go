@@ -0,0 +1,109 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+var base = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func testData() ([]resultstore.Record, []Event) {
+	records := []resultstore.Record{
+		{MessageID: "1", Tag: "welcome", Recipients: []string{"a@gmail.com", "b@yahoo.com"}, Status: "success", CreatedAt: base.Add(time.Hour)},
+		{MessageID: "2", Tag: "invoice", Recipients: []string{"c@gmail.com"}, Status: "success", CreatedAt: base.Add(2 * time.Hour)},
+		{Tag: "invoice", Recipients: []string{"d@gmail.com"}, Error: "rate limited", CreatedAt: base.Add(3 * time.Hour)},
+		// Outside the period
+		{MessageID: "0", Tag: "welcome", Recipients: []string{"z@gmail.com"}, Status: "success", CreatedAt: base.Add(-time.Hour)},
+	}
+	events := []Event{
+		{Type: EventDelivered, MessageID: "1", Recipient: "a@gmail.com", Time: base.Add(time.Hour)},
+		{Type: EventBounced, MessageID: "1", Recipient: "b@yahoo.com", Time: base.Add(time.Hour)},
+		{Type: EventDeferred, MessageID: "2", Recipient: "c@gmail.com", Time: base.Add(2 * time.Hour)},
+		{Type: EventDelivered, MessageID: "2", Recipient: "c@gmail.com", Time: base.Add(3 * time.Hour)},
+		{Type: EventOpened, MessageID: "1", Recipient: "a@gmail.com", Time: base.Add(4 * time.Hour)},
+		{Type: EventOpened, MessageID: "1", Recipient: "a@gmail.com", Time: base.Add(5 * time.Hour)},
+		// An event for a message sent before the period still counts
+		{Type: EventOpened, MessageID: "0", Recipient: "z@gmail.com", Time: base.Add(5 * time.Hour)},
+	}
+	return records, events
+}
+
+func TestBuild(t *testing.T) {
+	records, events := testData()
+	r := Build(records, events, base, base.Add(24*time.Hour))
+
+	tests := []struct {
+		name string
+		got  *Counts
+		want Counts
+	}{
+		{"total", &r.Total, Counts{Sent: 3, Failed: 1, Delivered: 2, Deferred: 1, Bounced: 1, Opened: 2}},
+		{"tag welcome", r.ByTag["welcome"], Counts{Sent: 2, Delivered: 1, Bounced: 1, Opened: 2}},
+		{"tag invoice", r.ByTag["invoice"], Counts{Sent: 1, Failed: 1, Delivered: 1, Deferred: 1}},
+		{"domain gmail.com", r.ByDomain["gmail.com"], Counts{Sent: 2, Failed: 1, Delivered: 2, Deferred: 1, Opened: 2}},
+		{"domain yahoo.com", r.ByDomain["yahoo.com"], Counts{Sent: 1, Bounced: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got == nil || *tt.got != tt.want {
+				t.Errorf("counts = %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromStore(t *testing.T) {
+	store := resultstore.NewMemoryStore(0)
+	records, _ := testData()
+	for _, rec := range records {
+		rec := rec
+		store.Save(context.Background(), &rec)
+	}
+
+	r, err := FromStore(context.Background(), store, nil, base, base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FromStore() error = %v", err)
+	}
+	if r.Total.Sent != 3 || r.Total.Failed != 1 {
+		t.Errorf("Total = %+v, want 3 sent and 1 failed", r.Total)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	records, events := testData()
+	var buf bytes.Buffer
+	if err := Build(records, events, base, base.Add(24*time.Hour)).WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := "group,key,sent,failed,delivered,deferred,bounced,opened\n" +
+		"total,,3,1,2,1,1,2\n" +
+		"tag,invoice,1,1,1,1,0,0\n" +
+		"tag,welcome,2,0,1,0,1,2\n" +
+		"domain,gmail.com,2,1,2,1,0,2\n" +
+		"domain,yahoo.com,1,0,0,0,1,0\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	records, events := testData()
+	var buf bytes.Buffer
+	if err := Build(records, events, base, base.Add(24*time.Hour)).WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() output is not valid JSON: %v", err)
+	}
+	if decoded.Total.Sent != 3 || decoded.ByDomain["yahoo.com"].Bounced != 1 || !decoded.Since.Equal(base) {
+		t.Errorf("decoded report = %+v", decoded)
+	}
+}
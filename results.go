@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+	"github.com/sachin-duhan/postal-go/reporting"
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+// WithResultStore saves a record of every send to store
+func WithResultStore(store resultstore.Store) Option {
+	return func(c *clientImpl) {
+		c.resultStore = store
+	}
+}
+
+// WithDomainStats counts the outcome of every send in stats, per recipient
+// domain
+func WithDomainStats(stats *reporting.DomainStats) Option {
+	return func(c *clientImpl) {
+		c.domainStats = stats
+	}
+}
+
+// messageRecord describes a message for the result store
+func messageRecord(msg *types.Message) *resultstore.Record {
+	recipients := make([]string, 0, len(msg.To)+len(msg.CC)+len(msg.BCC))
+	recipients = append(recipients, msg.To...)
+	recipients = append(recipients, msg.CC...)
+	recipients = append(recipients, msg.BCC...)
+	return &resultstore.Record{
+		Path:       "send/message",
+		Recipients: recipients,
+		From:       msg.From,
+		Subject:    msg.Subject,
+		Tag:        msg.Tag,
+	}
+}
+
+// rawRecord describes a raw message envelope for the result store
+func rawRecord(to []string, from string) *resultstore.Record {
+	return &resultstore.Record{
+		Path:       "send/raw",
+		Recipients: append([]string(nil), to...),
+		From:       from,
+	}
+}
+
+// sendAndRecord runs req and saves its outcome to the result store and
+// domain stats, if set
+// is configured. Store failures are logged but do not fail the send.
+func (c *clientImpl) sendAndRecord(ctx context.Context, req *transport.Request, rec func() *resultstore.Record) (*types.Result, error) {
+	if c.resultStore == nil && c.domainStats == nil {
+		result, err := c.do(ctx, req)
+		return result, c.redactError(err)
+	}
+
+	start := c.now()
+	result, err := c.do(ctx, req)
+
+	r := rec()
+	r.CreatedAt = start
+	r.Duration = c.now().Sub(start)
+	if result != nil {
+		r.MessageID = result.MessageID
+		r.Status = result.Status
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	if c.domainStats != nil {
+		c.domainStats.RecordResult(r)
+	}
+	if c.resultStore != nil {
+		if saveErr := c.resultStore.Save(context.WithoutCancel(ctx), r); saveErr != nil {
+			c.warn(r.Path, "result store: "+saveErr.Error())
+		}
+	}
+	return result, c.redactError(err)
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/reporting"
+	"github.com/sachin-duhan/postal-go/resultstore"
+)
+
+func TestClientResultStore(t *testing.T) {
+	fail := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": "error", "message": "rejected"}`))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "stored-1", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	store := resultstore.NewMemoryStore(0)
+	c, err := NewClient(ts.URL, "test-key", WithResultStore(store), WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	msg := compatTestMessage()
+	if _, err := c.SendMessage(ctx, msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	fail = true
+	if _, err := c.SendRawMessage(ctx, rawTestMessage()); err == nil {
+		t.Fatal("SendRawMessage() error = nil, want rejection")
+	}
+
+	records, err := store.Query(ctx, resultstore.Filter{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	sent := records[0]
+	if sent.Path != "send/message" || sent.MessageID != "stored-1" || sent.Status != "success" || sent.Failed() {
+		t.Errorf("sent record = %+v", sent)
+	}
+	if sent.Subject != msg.Subject || len(sent.Recipients) != len(msg.To)+len(msg.CC)+len(msg.BCC) {
+		t.Errorf("sent record metadata = %+v", sent)
+	}
+	if sent.CreatedAt.IsZero() {
+		t.Error("sent record has no CreatedAt")
+	}
+
+	if failed := records[1]; failed.Path != "send/raw" || !failed.Failed() {
+		t.Errorf("failed record = %+v", failed)
+	}
+
+	// Validation failures never reach the server and are not recorded
+	c.SendMessage(ctx, &types.Message{})
+	if records, _ := store.Query(ctx, resultstore.Filter{}); len(records) != 2 {
+		t.Errorf("got %d records after invalid send, want 2", len(records))
+	}
+}
+
+func TestClientDomainStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "stats-1", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	stats := reporting.NewDomainStats()
+	c, err := NewClient(ts.URL, "test-key", WithDomainStats(stats))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg := compatTestMessage()
+	msg.To = []string{"a@gmail.com", "b@Gmail.com"}
+	msg.CC, msg.BCC = nil, nil
+	if _, err := c.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	stats.RecordEvent(reporting.Event{Type: reporting.EventBounced, MessageID: "stats-1", Recipient: "b@gmail.com"})
+
+	got := stats.Domain("gmail.com")
+	if got.Sent != 2 || got.Bounced != 1 || got.BounceRate() != 0.5 {
+		t.Errorf("gmail.com counts = %+v, want 2 sent and 1 bounce", got)
+	}
+}
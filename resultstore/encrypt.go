@@ -0,0 +1,94 @@
+package resultstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encryptor encrypts record fields before they are persisted
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// ErrCiphertextTooShort is returned when decrypting data shorter than a nonce
+var ErrCiphertextTooShort = errors.New("ciphertext too short")
+
+// AESGCM encrypts with AES in Galois/Counter Mode, prefixing each
+// ciphertext with a random nonce
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM creates an AESGCM encryptor. The key must be 16, 24 or 32 bytes
+// to select AES-128, AES-192 or AES-256.
+func NewAESGCM(key []byte) (*AESGCM, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &AESGCM{aead: aead}, nil
+}
+
+// Encrypt implements Encryptor
+func (e *AESGCM) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(plaintext)+e.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt implements Encryptor
+func (e *AESGCM) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < e.aead.NonceSize() {
+		return nil, ErrCiphertextTooShort
+	}
+	nonce, sealed := ciphertext[:e.aead.NonceSize()], ciphertext[e.aead.NonceSize():]
+	return e.aead.Open(nil, nonce, sealed, nil)
+}
+
+// encryptedPrefix marks encrypted column values, so rows written before
+// encryption was enabled are still read as plaintext
+const encryptedPrefix = "enc:v1:"
+
+// encryptField returns s encrypted and base64 encoded for a text column
+func encryptField(enc Encryptor, s string) (string, error) {
+	if enc == nil || s == "" {
+		return s, nil
+	}
+	ciphertext, err := enc.Encrypt([]byte(s))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt result: %w", err)
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decryptField reverses encryptField
+func decryptField(enc Encryptor, s string) (string, error) {
+	if !strings.HasPrefix(s, encryptedPrefix) {
+		return s, nil
+	}
+	if enc == nil {
+		return "", errors.New("failed to decrypt result: no encryptor configured")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt result: %w", err)
+	}
+	plaintext, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt result: %w", err)
+	}
+	return string(plaintext), nil
+}
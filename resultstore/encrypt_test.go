@@ -0,0 +1,94 @@
+package resultstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/sqlutil/sqltest"
+)
+
+func TestAESGCM(t *testing.T) {
+	enc, err := NewAESGCM(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("NewAESGCM() error = %v", err)
+	}
+
+	plaintext := []byte("Your invoice is ready")
+	a, _ := enc.Encrypt(plaintext)
+	b, _ := enc.Encrypt(plaintext)
+	if bytes.Equal(a, b) {
+		t.Error("Encrypt() returned the same ciphertext twice; nonce not random")
+	}
+	if bytes.Contains(a, plaintext) {
+		t.Error("Encrypt() output contains the plaintext")
+	}
+
+	got, err := enc.Decrypt(a)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Decrypt() = %q, %v; want %q", got, err, plaintext)
+	}
+
+	a[len(a)-1] ^= 1
+	if _, err := enc.Decrypt(a); err == nil {
+		t.Error("Decrypt() of tampered ciphertext succeeded")
+	}
+	if _, err := enc.Decrypt([]byte("short")); err != ErrCiphertextTooShort {
+		t.Errorf("Decrypt() of short input error = %v, want ErrCiphertextTooShort", err)
+	}
+	if _, err := NewAESGCM([]byte("bad key")); err == nil {
+		t.Error("NewAESGCM() with a 7 byte key succeeded")
+	}
+}
+
+func TestSQLStoreEncryption(t *testing.T) {
+	db, d := sqltest.Open(t)
+	enc, _ := NewAESGCM(bytes.Repeat([]byte{7}, 16))
+	store := NewSQLStore(db, SQLConfig{Encryptor: enc})
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	err := store.Save(context.Background(), &Record{
+		MessageID:  "42",
+		Recipients: []string{"a@example.com"},
+		Subject:    "Payroll for January",
+		Error:      "a@example.com rejected",
+		CreatedAt:  created,
+	})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	args := d.Execs[0].Args
+	subject, sendErr := args[4].(string), args[7].(string)
+	if !strings.HasPrefix(subject, encryptedPrefix) || strings.Contains(subject, "Payroll") {
+		t.Errorf("stored subject = %q, want ciphertext", subject)
+	}
+	if !strings.HasPrefix(sendErr, encryptedPrefix) {
+		t.Errorf("stored error = %q, want ciphertext", sendErr)
+	}
+
+	// Rows written before encryption was enabled are read as plaintext
+	d.Columns = resultColumns
+	d.Rows = [][]driver.Value{
+		{"42", "send/message", ",a@example.com,", "", subject, "", "", sendErr, created, int64(0)},
+		{"41", "send/message", ",a@example.com,", "", "Old subject", "", "", "", created, int64(0)},
+	}
+	records, err := store.Query(context.Background(), Filter{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if records[0].Subject != "Payroll for January" || records[0].Error != "a@example.com rejected" {
+		t.Errorf("Query() record = %+v, want decrypted subject and error", records[0])
+	}
+	if records[1].Subject != "Old subject" {
+		t.Errorf("Query() plaintext subject = %q", records[1].Subject)
+	}
+
+	d.Rows = [][]driver.Value{{"42", "send/message", ",a@example.com,", "", subject, "", "", "", created, int64(0)}}
+	if _, err := NewSQLStore(db, SQLConfig{}).Query(context.Background(), Filter{}); err == nil {
+		t.Error("Query() of encrypted rows without an Encryptor succeeded")
+	}
+}
@@ -0,0 +1,161 @@
+// Package resultstore persists the outcome of each send so that delivery
+// attempts can be queried later
+package resultstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is the outcome of one send
+type Record struct {
+	MessageID  string
+	Path       string // API path, e.g. "send/message"
+	Recipients []string
+	From       string
+	Subject    string
+	Tag        string
+	Status     string // the API status, e.g. "success", or "" if no response
+	Error      string // the send error, or ""
+	CreatedAt  time.Time
+	Duration   time.Duration
+}
+
+// Failed returns true if the send returned an error
+func (r *Record) Failed() bool {
+	return r.Error != ""
+}
+
+// Filter selects records in Query. Zero fields match everything.
+type Filter struct {
+	MessageID  string
+	Recipient  string
+	Since      time.Time
+	Until      time.Time
+	FailedOnly bool
+	// Limit caps the number of records returned
+	Limit int
+}
+
+// Store persists send records
+type Store interface {
+	Save(ctx context.Context, r *Record) error
+	// Query returns matching records, oldest first
+	Query(ctx context.Context, f Filter) ([]Record, error)
+}
+
+// Purger is implemented by stores that can delete old records, so retained
+// email data can follow a deletion policy
+type Purger interface {
+	// Purge deletes records created before olderThan and returns how many
+	// were deleted
+	Purge(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// MemoryStore keeps records in memory
+type MemoryStore struct {
+	// MaxRecords caps the number of retained records, dropping the oldest.
+	// Zero means unlimited.
+	MaxRecords int
+	// Retention drops records older than this on each Save. Zero keeps
+	// records until MaxRecords is reached.
+	Retention time.Duration
+
+	mu      sync.RWMutex
+	records []Record
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore(maxRecords int) *MemoryStore {
+	return &MemoryStore{MaxRecords: maxRecords}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(ctx context.Context, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := *r
+	rec.Recipients = append([]string(nil), r.Recipients...)
+	s.records = append(s.records, rec)
+	if s.MaxRecords > 0 && len(s.records) > s.MaxRecords {
+		s.records = append([]Record(nil), s.records[len(s.records)-s.MaxRecords:]...)
+	}
+	if s.Retention > 0 {
+		s.purge(time.Now().Add(-s.Retention))
+	}
+	return nil
+}
+
+// Purge implements Purger
+func (s *MemoryStore) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.purge(olderThan), nil
+}
+
+// purge removes records created before cutoff. It must be called with mu held.
+func (s *MemoryStore) purge(cutoff time.Time) int64 {
+	kept := s.records[:0]
+	for _, r := range s.records {
+		if !r.CreatedAt.Before(cutoff) {
+			kept = append(kept, r)
+		}
+	}
+	purged := int64(len(s.records) - len(kept))
+	for i := len(kept); i < len(s.records); i++ {
+		s.records[i] = Record{}
+	}
+	s.records = kept
+	return purged
+}
+
+// Query implements Store
+func (s *MemoryStore) Query(ctx context.Context, f Filter) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []Record
+	for _, r := range s.records {
+		if !f.matches(&r) {
+			continue
+		}
+		out = append(out, r)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// matches reports whether r satisfies the filter
+func (f *Filter) matches(r *Record) bool {
+	if f.MessageID != "" && r.MessageID != f.MessageID {
+		return false
+	}
+	if f.Recipient != "" && !containsFold(r.Recipients, f.Recipient) {
+		return false
+	}
+	if !f.Since.IsZero() && r.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !r.CreatedAt.Before(f.Until) {
+		return false
+	}
+	if f.FailedOnly && !r.Failed() {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package resultstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func testRecords() []Record {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return []Record{
+		{MessageID: "1", Recipients: []string{"a@example.com"}, Status: "success", CreatedAt: base},
+		{MessageID: "2", Recipients: []string{"b@example.com", "A@example.com"}, Error: "rate limited", CreatedAt: base.Add(time.Minute)},
+		{MessageID: "3", Recipients: []string{"c@example.com"}, Status: "success", CreatedAt: base.Add(2 * time.Minute)},
+	}
+}
+
+func TestMemoryStoreQuery(t *testing.T) {
+	store := NewMemoryStore(0)
+	for _, r := range testRecords() {
+		r := r
+		if err := store.Save(context.Background(), &r); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "all", filter: Filter{}, want: []string{"1", "2", "3"}},
+		{name: "message id", filter: Filter{MessageID: "2"}, want: []string{"2"}},
+		{name: "recipient ignores case", filter: Filter{Recipient: "a@EXAMPLE.com"}, want: []string{"1", "2"}},
+		{name: "time range", filter: Filter{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}, want: []string{"2"}},
+		{name: "failed only", filter: Filter{FailedOnly: true}, want: []string{"2"}},
+		{name: "limit", filter: Filter{Limit: 2}, want: []string{"1", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := store.Query(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			var got []string
+			for _, r := range records {
+				got = append(got, r.MessageID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Query() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreMaxRecords(t *testing.T) {
+	store := NewMemoryStore(2)
+	for _, r := range testRecords() {
+		r := r
+		store.Save(context.Background(), &r)
+	}
+
+	records, _ := store.Query(context.Background(), Filter{})
+	if len(records) != 2 || records[0].MessageID != "2" {
+		t.Errorf("Query() = %+v, want the two newest records", records)
+	}
+}
+
+func TestMemoryStorePurge(t *testing.T) {
+	store := NewMemoryStore(0)
+	records := testRecords()
+	for _, r := range records {
+		r := r
+		store.Save(context.Background(), &r)
+	}
+
+	purged, err := store.Purge(context.Background(), records[2].CreatedAt)
+	if err != nil || purged != 2 {
+		t.Fatalf("Purge() = %d, %v; want 2", purged, err)
+	}
+	remaining, _ := store.Query(context.Background(), Filter{})
+	if len(remaining) != 1 || remaining[0].MessageID != "3" {
+		t.Errorf("Query() after Purge = %+v, want only record 3", remaining)
+	}
+}
+
+func TestMemoryStoreRetention(t *testing.T) {
+	store := &MemoryStore{Retention: time.Hour}
+	store.Save(context.Background(), &Record{MessageID: "old", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	store.Save(context.Background(), &Record{MessageID: "new", CreatedAt: time.Now()})
+
+	records, _ := store.Query(context.Background(), Filter{})
+	if len(records) != 1 || records[0].MessageID != "new" {
+		t.Errorf("Query() = %+v, want only the record inside the retention period", records)
+	}
+}
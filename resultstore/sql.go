@@ -0,0 +1,199 @@
+package resultstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/sqlutil"
+)
+
+// Placeholder styles for SQL parameters
+const (
+	// PlaceholderQuestion uses "?" (MySQL, SQLite)
+	PlaceholderQuestion = sqlutil.PlaceholderQuestion
+	// PlaceholderDollar uses "$1", "$2"... (PostgreSQL)
+	PlaceholderDollar = sqlutil.PlaceholderDollar
+)
+
+// SQLConfig configures an SQLStore
+type SQLConfig struct {
+	// Table defaults to "postal_results"
+	Table string
+	// Placeholder selects the parameter style, PlaceholderQuestion by default
+	Placeholder int
+	// Encryptor, when set, encrypts the subject and error of each record at
+	// rest. Recipients stay in plaintext so Query can filter on them.
+	Encryptor Encryptor
+	// Retention deletes records older than this, checked at most once per
+	// RetentionInterval when saving. Zero keeps records until Purge is called.
+	Retention time.Duration
+}
+
+// RetentionInterval is how often an SQLStore with a Retention deletes
+// expired records
+const RetentionInterval = sqlutil.RetentionInterval
+
+// SQLStore keeps records in a database/sql table
+type SQLStore struct {
+	db     *sql.DB
+	config SQLConfig
+
+	// purges spaces out the deletion of expired records
+	purges sqlutil.PurgeSchedule
+}
+
+// NewSQLStore creates a store using db. Call CreateTable to create the
+// table if it does not exist yet.
+func NewSQLStore(db *sql.DB, cfg SQLConfig) *SQLStore {
+	if cfg.Table == "" {
+		cfg.Table = "postal_results"
+	}
+	return &SQLStore{db: db, config: cfg}
+}
+
+// CreateTable creates the results table if it does not exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	message_id VARCHAR(255) NOT NULL,
+	path VARCHAR(64) NOT NULL,
+	recipients TEXT NOT NULL,
+	sender VARCHAR(255) NOT NULL,
+	subject TEXT NOT NULL,
+	tag VARCHAR(255) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	error TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	duration_ms BIGINT NOT NULL
+)`, s.config.Table))
+	return err
+}
+
+// Save implements Store
+func (s *SQLStore) Save(ctx context.Context, r *Record) error {
+	subject, err := encryptField(s.config.Encryptor, r.Subject)
+	if err != nil {
+		return err
+	}
+	sendErr, err := encryptField(s.config.Encryptor, r.Error)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (message_id, path, recipients, sender, subject, tag, status, error, created_at, duration_ms) VALUES (%s)",
+		s.config.Table, sqlutil.Placeholders(s.config.Placeholder, 1, 10))
+	_, err = s.db.ExecContext(ctx, query,
+		r.MessageID, r.Path, joinRecipients(r.Recipients), r.From, subject, r.Tag,
+		r.Status, sendErr, r.CreatedAt.UTC(), r.Duration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to save result: %w", err)
+	}
+
+	if now := time.Now(); s.config.Retention > 0 && s.purges.Due(now) {
+		if _, err := s.Purge(ctx, now.Add(-s.config.Retention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Purge implements Purger
+func (s *SQLStore) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < %s", s.config.Table, s.placeholder(1))
+	res, err := s.db.ExecContext(ctx, query, olderThan.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge results: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Query implements Store
+func (s *SQLStore) Query(ctx context.Context, f Filter) ([]Record, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, s.placeholder(len(args))))
+	}
+	if f.MessageID != "" {
+		add("message_id = %s", f.MessageID)
+	}
+	if f.Recipient != "" {
+		add("LOWER(recipients) LIKE %s", "%,"+strings.ToLower(f.Recipient)+",%")
+	}
+	if !f.Since.IsZero() {
+		add("created_at >= %s", f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		add("created_at < %s", f.Until.UTC())
+	}
+	if f.FailedOnly {
+		where = append(where, "error <> ''")
+	}
+
+	query := fmt.Sprintf("SELECT message_id, path, recipients, sender, subject, tag, status, error, created_at, duration_ms FROM %s", s.config.Table)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at"
+	if f.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query results: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Record
+	for rows.Next() {
+		var (
+			r          Record
+			recipients string
+			durationMS int64
+		)
+		if err := rows.Scan(&r.MessageID, &r.Path, &recipients, &r.From, &r.Subject, &r.Tag,
+			&r.Status, &r.Error, &r.CreatedAt, &durationMS); err != nil {
+			return nil, fmt.Errorf("failed to scan result: %w", err)
+		}
+		if r.Subject, err = decryptField(s.config.Encryptor, r.Subject); err != nil {
+			return nil, err
+		}
+		if r.Error, err = decryptField(s.config.Encryptor, r.Error); err != nil {
+			return nil, err
+		}
+		r.Recipients = splitRecipients(recipients)
+		r.Duration = time.Duration(durationMS) * time.Millisecond
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// placeholder returns the n-th (1-based) parameter placeholder
+func (s *SQLStore) placeholder(n int) string {
+	return sqlutil.Placeholder(s.config.Placeholder, n)
+}
+
+// joinRecipients stores recipients as ",a,b," so that a LIKE "%,a,%"
+// filter matches whole addresses
+func joinRecipients(recipients []string) string {
+	if len(recipients) == 0 {
+		return ""
+	}
+	return "," + strings.Join(recipients, ",") + ","
+}
+
+// splitRecipients reverses joinRecipients
+func splitRecipients(s string) []string {
+	s = strings.Trim(s, ",")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
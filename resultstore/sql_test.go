@@ -0,0 +1,133 @@
+package resultstore
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/sqlutil/sqltest"
+)
+
+// resultColumns are the columns Query selects
+var resultColumns = []string{"message_id", "path", "recipients", "sender", "subject", "tag", "status", "error", "created_at", "duration_ms"}
+
+func TestSQLStoreSave(t *testing.T) {
+	db, d := sqltest.Open(t)
+	store := NewSQLStore(db, SQLConfig{Placeholder: PlaceholderDollar})
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	err := store.Save(context.Background(), &Record{
+		MessageID:  "42",
+		Path:       "send/message",
+		Recipients: []string{"a@example.com", "b@example.com"},
+		From:       "sender@example.com",
+		Subject:    "Hello",
+		Status:     "success",
+		CreatedAt:  created,
+		Duration:   1500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	exec := d.Execs[0]
+	if !strings.HasPrefix(exec.Query, "INSERT INTO postal_results (") || !strings.Contains(exec.Query, "VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)") {
+		t.Errorf("query = %q", exec.Query)
+	}
+	want := []driver.Value{"42", "send/message", ",a@example.com,b@example.com,", "sender@example.com", "Hello", "", "success", "", created.UTC(), int64(1500)}
+	if !reflect.DeepEqual(exec.Args, want) {
+		t.Errorf("args = %v, want %v", exec.Args, want)
+	}
+}
+
+func TestSQLStoreQuery(t *testing.T) {
+	db, d := sqltest.Open(t)
+	store := NewSQLStore(db, SQLConfig{Table: "sends"})
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.Columns = resultColumns
+	d.Rows = [][]driver.Value{
+		{"42", "send/raw", ",a@example.com,", "sender@example.com", "", "", "", "timeout", created, int64(250)},
+	}
+
+	since := created.Add(-time.Hour)
+	records, err := store.Query(context.Background(), Filter{Recipient: "A@example.com", Since: since, FailedOnly: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	wantQuery := "SELECT message_id, path, recipients, sender, subject, tag, status, error, created_at, duration_ms FROM sends" +
+		" WHERE LOWER(recipients) LIKE ? AND created_at >= ? AND error <> '' ORDER BY created_at LIMIT 10"
+	if d.Queries[0].Query != wantQuery {
+		t.Errorf("query = %q, want %q", d.Queries[0].Query, wantQuery)
+	}
+	if wantArgs := []driver.Value{"%,a@example.com,%", since}; !reflect.DeepEqual(d.Queries[0].Args, wantArgs) {
+		t.Errorf("args = %v, want %v", d.Queries[0].Args, wantArgs)
+	}
+
+	want := Record{
+		MessageID:  "42",
+		Path:       "send/raw",
+		Recipients: []string{"a@example.com"},
+		From:       "sender@example.com",
+		Error:      "timeout",
+		CreatedAt:  created,
+		Duration:   250 * time.Millisecond,
+	}
+	if len(records) != 1 || !reflect.DeepEqual(records[0], want) {
+		t.Errorf("Query() = %+v, want %+v", records, want)
+	}
+}
+
+func TestSQLStoreCreateTable(t *testing.T) {
+	db, d := sqltest.Open(t)
+	if err := NewSQLStore(db, SQLConfig{}).CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if !strings.HasPrefix(d.Execs[0].Query, "CREATE TABLE IF NOT EXISTS postal_results (") {
+		t.Errorf("query = %q", d.Execs[0].Query)
+	}
+}
+
+func TestSQLStorePurge(t *testing.T) {
+	db, d := sqltest.Open(t)
+	store := NewSQLStore(db, SQLConfig{Placeholder: PlaceholderDollar})
+
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	if _, err := store.Purge(context.Background(), cutoff); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	exec := d.Execs[0]
+	if exec.Query != "DELETE FROM postal_results WHERE created_at < $1" {
+		t.Errorf("query = %q", exec.Query)
+	}
+	if want := []driver.Value{cutoff.UTC()}; !reflect.DeepEqual(exec.Args, want) {
+		t.Errorf("args = %v, want %v", exec.Args, want)
+	}
+}
+
+func TestSQLStoreRetention(t *testing.T) {
+	db, d := sqltest.Open(t)
+	store := NewSQLStore(db, SQLConfig{Retention: 24 * time.Hour})
+
+	for i := 0; i < 3; i++ {
+		if err := store.Save(context.Background(), &Record{MessageID: fmt.Sprint(i), CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	// Expired records are deleted on the first save, then once per RetentionInterval
+	var deletes int
+	for _, exec := range d.Execs {
+		if strings.HasPrefix(exec.Query, "DELETE FROM postal_results") {
+			deletes++
+		}
+	}
+	if deletes != 1 {
+		t.Errorf("got %d purges over 3 saves, want 1", deletes)
+	}
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/clock"
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// do executes a send request, retrying retryable failures up to
+// Config.MaxRetries times with Config.RetryInterval between attempts
+func (c *clientImpl) do(ctx context.Context, req *transport.Request) (*types.Result, error) {
+	return c.execute(ctx, req, true)
+}
+
+// query executes a request that does not send mail, such as a message
+// lookup. It is retried like a send but does not count against the error
+// budget or tenant quota.
+func (c *clientImpl) query(ctx context.Context, req *transport.Request) (*types.Result, error) {
+	return c.execute(ctx, req, false)
+}
+
+// execute runs req with retries; send marks requests that send mail
+func (c *clientImpl) execute(ctx context.Context, req *transport.Request, send bool) (result *types.Result, err error) {
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	c.applyContextHeaders(ctx, req)
+	if c.tenant != nil {
+		if ctx, err = c.applyTenant(ctx, req, send); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.requireCapability(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if send {
+		if err := c.ensureIdempotencyKey(req); err != nil {
+			return nil, err
+		}
+	}
+	if send && c.inflight != nil {
+		slots := c.inflight
+		if err := c.acquireSlot(ctx, slots); err != nil {
+			return nil, err
+		}
+		defer func() { <-slots }()
+	}
+
+	// Client-side rejections above are not counted against the error budget
+	if c.budget != nil && send {
+		defer func() { c.budget.RecordSend(err) }()
+	}
+
+	if c.config.Debug {
+		if deadline, ok := ctx.Deadline(); ok {
+			c.logger().Printf("[DEBUG] %s %s: deadline %s (in %v)",
+				req.Method, req.Path, deadline.Format(time.RFC3339Nano), deadline.Sub(c.now()).Round(time.Millisecond))
+		} else {
+			c.logger().Printf("[DEBUG] %s %s: no deadline", req.Method, req.Path)
+		}
+	}
+
+	stats := &types.RetryStats{}
+	start := c.now()
+	var lastErr error
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			timer := c.getClock().NewTimer(c.retryWait(lastErr))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, retryFailure(stats, c.now().Sub(start), lastErr)
+			case <-timer.C():
+			}
+		}
+
+		stats.Attempts++
+		result, err = c.transport.Do(ctx, req)
+		if errors.Is(err, transport.ErrBodyConsumed) && lastErr != nil {
+			return nil, retryFailure(stats, c.now().Sub(start), lastErr)
+		}
+		if err == nil || !c.shouldRetry(req, err, send) {
+			stats.Elapsed = c.now().Sub(start)
+			if err != nil {
+				return nil, retryFailure(stats, stats.Elapsed, err)
+			}
+			result.Retry = stats
+			return result, nil
+		}
+		lastErr = err
+		stats.LastError = err
+	}
+
+	return nil, retryFailure(stats, c.now().Sub(start), lastErr)
+}
+
+// shouldRetry reports whether a failed attempt may be retried. Sends are not
+// idempotent: a timeout after Postal accepted the message would deliver it
+// twice on retry. They are only retried when the server cannot have acted on
+// the request (it was never written, or was rate limited or refused with
+// 503), or when the request carries an idempotency key.
+func (c *clientImpl) shouldRetry(req *transport.Request, err error, send bool) bool {
+	if !types.IsRetryable(err) {
+		return false
+	}
+	if !send || c.hasIdempotencyKey(req) {
+		return true
+	}
+	var transportErr *types.TransportError
+	if errors.As(err, &transportErr) {
+		return transportErr.NotSent
+	}
+	return types.IsRateLimit(err) || types.StatusCode(err) == http.StatusServiceUnavailable
+}
+
+// retryFailure returns err, wrapped with the retry statistics if the
+// request was attempted more than once
+func retryFailure(stats *types.RetryStats, elapsed time.Duration, err error) error {
+	if stats.Attempts < 2 {
+		return err
+	}
+	stats.Elapsed = elapsed
+	return &types.RetryError{RetryStats: *stats, Err: err}
+}
+
+// acquireSlot takes one of the MaxConcurrency send slots, waiting for one
+// or failing at once according to Config.ConcurrencyMode
+func (c *clientImpl) acquireSlot(ctx context.Context, slots chan struct{}) error {
+	if c.config.ConcurrencyMode == ConcurrencyFail {
+		select {
+		case slots <- struct{}{}:
+			return nil
+		default:
+			return fmt.Errorf("%w: limit is %d", types.ErrTooManyInFlight, cap(slots))
+		}
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withDefaultTimeout applies Config.DefaultOperationTimeout when ctx has no deadline
+func (c *clientImpl) withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.config.DefaultOperationTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.config.DefaultOperationTimeout)
+}
+
+// retryWait returns the wait before the next attempt: the Retry-After of a
+// rate limited response, or RetryInterval with jitter applied
+func (c *clientImpl) retryWait(lastErr error) time.Duration {
+	// The server knows best how long it needs; jitter is not applied to it
+	var rateLimitErr *types.RateLimitError
+	if errors.As(lastErr, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		return rateLimitErr.RetryAfter
+	}
+
+	wait := c.config.RetryInterval
+	if c.config.RetryJitter <= 0 {
+		return wait
+	}
+	var r float64
+	if c.random != nil {
+		r = c.random.Float64()
+	} else {
+		r = rand.Float64()
+	}
+	wait += time.Duration(float64(wait) * c.config.RetryJitter * (2*r - 1))
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// WithClock replaces the system clock used for retry waits, quotas and send
+// records, so tests can control time
+func WithClock(clk clock.Clock) Option {
+	return func(c *clientImpl) {
+		c.clock = clk
+	}
+}
+
+// WithRandSource draws retry jitter from src, so tests get repeatable waits
+func WithRandSource(src rand.Source) Option {
+	return func(c *clientImpl) {
+		c.random = &lockedRand{r: rand.New(src)}
+	}
+}
+
+// lockedRand makes a rand.Rand safe for concurrent sends
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// Float64 returns a number in [0.0, 1.0)
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// getClock returns the configured clock or the system clock
+func (c *clientImpl) getClock() clock.Clock {
+	if c.clock != nil {
+		return c.clock
+	}
+	return clock.Real
+}
+
+// now returns the current time from the client's clock
+func (c *clientImpl) now() time.Time {
+	return c.getClock().Now()
+}
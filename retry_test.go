@@ -0,0 +1,419 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/clock"
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func newRetryTestClient(t *testing.T, url string, maxRetries int) Client {
+	t.Helper()
+	client, err := NewClient(url, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.MaxRetries = maxRetries
+	cfg.RetryInterval = time.Millisecond
+	return client.WithConfig(cfg)
+}
+
+func retryTestMessage() *types.Message {
+	return &types.Message{
+		To:       []string{"recipient@example.com"},
+		From:     "sender@example.com",
+		Subject:  "Retry Test",
+		HTMLBody: "Test Body",
+	}
+}
+
+func TestRetryOnServerError(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(503)
+			w.Write([]byte(`{"code": "server_error", "message": "Unavailable"}`))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12351", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, 3)
+	result, err := client.SendMessage(context.Background(), retryTestMessage())
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if result.MessageID != "12351" {
+		t.Errorf("SendMessage() MessageID = %v, want 12351", result.MessageID)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("server received %d attempts, want 3", got)
+	}
+	if result.Retry == nil || result.Retry.Attempts != 3 || !types.IsServerError(result.Retry.LastError) || result.Retry.Elapsed <= 0 {
+		t.Errorf("Result.Retry = %+v, want 3 attempts, elapsed time and the last 503", result.Retry)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(429)
+		w.Write([]byte(`{"code": "rate_limit", "message": "Rate limit exceeded"}`))
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, 2)
+	_, err := client.SendMessage(context.Background(), retryTestMessage())
+	if !types.IsRateLimit(err) {
+		t.Errorf("SendMessage() error = %v, want rate limit error", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("server received %d attempts, want 3", got)
+	}
+	var retryErr *types.RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 || !types.IsRateLimit(retryErr.LastError) {
+		t.Errorf("SendMessage() error = %v, want RetryError with 3 attempts", err)
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(400)
+		w.Write([]byte(`{"code": "validation_error", "message": "Invalid"}`))
+	}))
+	defer ts.Close()
+
+	client := newRetryTestClient(t, ts.URL, 3)
+	_, err := client.SendMessage(context.Background(), retryTestMessage())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if types.IsRetryable(err) {
+		t.Error("400 response should not be retryable")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("server received %d attempts, want 1", got)
+	}
+	var retryErr *types.RetryError
+	if errors.As(err, &retryErr) {
+		t.Errorf("SendMessage() error = %v, want no RetryError for a single attempt", err)
+	}
+}
+
+func TestDefaultOperationTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+	cfg.DefaultOperationTimeout = 50 * time.Millisecond
+	cfg.Debug = true
+	cfg.Logger = log.New(&buf, "", 0)
+	client.WithConfig(cfg)
+
+	start := time.Now()
+	_, err = client.SendMessage(context.Background(), retryTestMessage())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendMessage() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SendMessage() took %v, want default timeout to apply", elapsed)
+	}
+	if !strings.Contains(buf.String(), "POST send/message: deadline") {
+		t.Errorf("expected effective deadline in debug log, got %q", buf.String())
+	}
+}
+
+func TestDefaultOperationTimeoutKeepsCallerDeadline(t *testing.T) {
+	client := &clientImpl{config: &Config{DefaultOperationTimeout: time.Millisecond}}
+
+	want := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.Background(), want)
+	defer cancel()
+
+	ctx, cancelDefault := client.withDefaultTimeout(ctx)
+	defer cancelDefault()
+
+	if got, _ := ctx.Deadline(); !got.Equal(want) {
+		t.Errorf("deadline = %v, want caller's deadline %v", got, want)
+	}
+}
+
+// instantClock fires every timer immediately and records the waits asked for
+type instantClock struct {
+	waits []time.Duration
+}
+
+func (c *instantClock) Now() time.Time {
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (c *instantClock) NewTimer(d time.Duration) clock.Timer {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now().Add(d)
+	return instantTimer(ch)
+}
+
+type instantTimer chan time.Time
+
+func (t instantTimer) C() <-chan time.Time { return t }
+func (t instantTimer) Stop() bool          { return false }
+
+func TestRetryClockAndJitter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+		w.Write([]byte(`{"code": "server_error", "message": "Unavailable"}`))
+	}))
+	defer ts.Close()
+
+	waits := func(seed int64) []time.Duration {
+		clk := &instantClock{}
+		c, err := NewClient(ts.URL, "test-key",
+			WithMaxRetries(4),
+			WithRetryInterval(time.Hour),
+			WithRetryJitter(0.5),
+			WithClock(clk),
+			WithRandSource(rand.NewSource(seed)),
+		)
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		if _, err := c.SendMessage(context.Background(), retryTestMessage()); err == nil {
+			t.Fatal("SendMessage() error = nil, want exhausted retries")
+		}
+		return clk.waits
+	}
+
+	// An hour-long retry interval finishes at once on the test clock
+	first := waits(1)
+	if len(first) != 4 {
+		t.Fatalf("got %d waits, want 4", len(first))
+	}
+	for i, d := range first {
+		if d < 30*time.Minute || d > 90*time.Minute {
+			t.Errorf("wait %d = %v, want within 50%% of 1h", i, d)
+		}
+	}
+	if first[0] == first[1] && first[1] == first[2] {
+		t.Errorf("waits %v are not jittered", first)
+	}
+
+	// The same seed gives the same waits
+	second := waits(1)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("wait %d = %v with the same seed, want %v", i, second[i], first[i])
+		}
+	}
+}
+
+func TestRetryVirtualTime(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(503)
+			w.Write([]byte(`{"code": "server_error", "message": "Unavailable"}`))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12351", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := NewClient(ts.URL, "test-key", WithMaxRetries(3), WithRetryInterval(time.Minute), WithClock(clk))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.SendMessage(context.Background(), retryTestMessage())
+		done <- err
+	}()
+
+	// Each retry waits a virtual minute
+	for i := 0; i < 2; i++ {
+		clk.BlockUntil(1)
+		if got := atomic.LoadInt32(&attempts); got != int32(i+1) {
+			t.Fatalf("attempts before retry %d = %d, want %d", i+1, got, i+1)
+		}
+		clk.Advance(time.Minute)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(429)
+			w.Write([]byte(`{"code": "rate_limited", "message": "Too many requests"}`))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12351", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := NewClient(ts.URL, "test-key", WithMaxRetries(1), WithRetryInterval(time.Second), WithClock(clk))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.SendMessage(context.Background(), retryTestMessage())
+		done <- err
+	}()
+
+	// The retry waits for Retry-After rather than RetryInterval
+	clk.BlockUntil(1)
+	clk.Advance(119 * time.Second)
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("attempts before Retry-After elapsed = %d, want 1", got)
+	}
+	clk.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	// Without retries the wait is reported to the caller
+	c, err = NewClient(ts.URL, "test-key", WithMaxRetries(0), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	atomic.StoreInt32(&attempts, 0)
+	_, err = c.SendMessage(context.Background(), retryTestMessage())
+
+	var rateLimitErr *types.RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 2*time.Minute {
+		t.Fatalf("SendMessage() error = %v, want RateLimitError with RetryAfter 2m", err)
+	}
+	var postalErr *types.PostalError
+	if !errors.As(err, &postalErr) || postalErr.Code != "rate_limited" || !types.IsRateLimit(err) {
+		t.Errorf("SendMessage() error = %v, want it to wrap the rate_limited PostalError", err)
+	}
+}
+
+func TestRetrySendSafety(t *testing.T) {
+	// A listener that is closed refuses connections, so nothing is sent
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		status   int
+		keys     bool
+		wantSent int32
+	}{
+		{"500 not retried", http.StatusInternalServerError, false, 1},
+		{"502 not retried", http.StatusBadGateway, false, 1},
+		{"503 retried", http.StatusServiceUnavailable, false, 3},
+		{"429 retried", http.StatusTooManyRequests, false, 3},
+		{"500 retried with idempotency keys", http.StatusInternalServerError, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"code": "server_error", "message": "Failed"}`))
+			}))
+			defer ts.Close()
+
+			client := newRetryTestClient(t, ts.URL, 2)
+			if tt.keys {
+				client = client.Clone(WithIdempotencyKeys(""))
+			}
+			if _, err := client.SendMessage(context.Background(), retryTestMessage()); err == nil {
+				t.Fatal("SendMessage() error = nil")
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantSent {
+				t.Errorf("server received %d attempts, want %d", got, tt.wantSent)
+			}
+		})
+	}
+
+	t.Run("lookups retried", func(t *testing.T) {
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code": "server_error", "message": "Failed"}`))
+		}))
+		defer ts.Close()
+
+		client := newRetryTestClient(t, ts.URL, 2)
+		client.GetDeliveries(context.Background(), 1)
+		if got := atomic.LoadInt32(&attempts); got != 3 {
+			t.Errorf("server received %d attempts, want 3", got)
+		}
+	})
+
+	t.Run("timeout after write not retried", func(t *testing.T) {
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			time.Sleep(100 * time.Millisecond)
+		}))
+		defer ts.Close()
+
+		client := newRetryTestClient(t, ts.URL, 2)
+		cfg := DefaultConfig()
+		cfg.MaxRetries = 2
+		cfg.RetryInterval = time.Millisecond
+		cfg.Timeout = 20 * time.Millisecond
+		client.WithConfig(cfg)
+		if _, err := client.SendMessage(context.Background(), retryTestMessage()); err == nil {
+			t.Fatal("SendMessage() error = nil")
+		}
+		if got := atomic.LoadInt32(&attempts); got != 1 {
+			t.Errorf("server received %d attempts, want 1", got)
+		}
+	})
+
+	t.Run("connection refused retried", func(t *testing.T) {
+		client := newRetryTestClient(t, closed.URL, 2)
+		_, err := client.SendMessage(context.Background(), retryTestMessage())
+		var retryErr *types.RetryError
+		var transportErr *types.TransportError
+		if !errors.As(err, &retryErr) || retryErr.Attempts != 3 || !errors.As(err, &transportErr) || !transportErr.NotSent {
+			t.Errorf("SendMessage() error = %v, want 3 attempts ending in an unsent TransportError", err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/middleware"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// SendOption customizes a single send call
+type SendOption func(*sendOptions)
+
+// sendOptions holds per-call settings collected from SendOptions
+type sendOptions struct {
+	mutators   []func(*http.Request)
+	middleware []middleware.Middleware
+	profile    string
+	// correlationID is written to types.CorrelationHeader
+	correlationID string
+	// idempotencyKey is sent in the idempotency header instead of a
+	// generated key
+	idempotencyKey string
+}
+
+// WithRequestMutator modifies the outgoing HTTP request for this call only,
+// e.g. to add a one-off header or alternate auth
+func WithRequestMutator(fn func(*http.Request)) SendOption {
+	return func(o *sendOptions) {
+		o.mutators = append(o.mutators, fn)
+	}
+}
+
+// WithCallMiddleware wraps the transport with middleware for this call only.
+// It runs outside any middleware registered with Client.WithMiddleware.
+func WithCallMiddleware(mws ...Middleware) SendOption {
+	return func(o *sendOptions) {
+		for _, m := range mws {
+			o.middleware = append(o.middleware, middleware.Middleware(m))
+		}
+	}
+}
+
+// WithProfile selects a SenderProfile registered with WithSenderProfile
+// for this call
+func WithProfile(name string) SendOption {
+	return func(o *sendOptions) {
+		o.profile = name
+	}
+}
+
+// WithCorrelationID writes an application correlation ID to the
+// types.CorrelationHeader header of the sent mail, so webhook events for the
+// message can be traced back to this send with types.CorrelationID
+func WithCorrelationID(id string) SendOption {
+	return func(o *sendOptions) {
+		o.correlationID = id
+	}
+}
+
+// correlationHeaders returns headers with the correlation ID set, copying
+// rather than modifying the caller's map
+func (o *sendOptions) correlationHeaders(headers map[string]string) map[string]string {
+	if o.correlationID == "" {
+		return headers
+	}
+	return mergeHeaders(headers, map[string]string{types.CorrelationHeader: o.correlationID})
+}
+
+// collectSendOptions applies opts to an empty sendOptions
+func collectSendOptions(opts []SendOption) *sendOptions {
+	var o sendOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &o
+}
+
+// newRequest builds a transport request with the given send options applied
+func (c *clientImpl) newRequest(method, path string, body interface{}, o *sendOptions) *transport.Request {
+	req := &transport.Request{
+		Method:     method,
+		Path:       path,
+		Body:       body,
+		Mutators:   o.mutators,
+		Middleware: o.middleware,
+	}
+	c.applyIdempotencyKey(req, o)
+	return req
+}
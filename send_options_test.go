@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+func TestWithRequestMutator(t *testing.T) {
+	var gotHeaders []http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = append(gotHeaders, r.Header.Clone())
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12353", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	_, err = client.SendMessage(ctx, compatTestMessage(), WithRequestMutator(func(r *http.Request) {
+		r.Header.Set("X-Server-API-Key", "alternate-key")
+		r.Header.Set("X-One-Off", "yes")
+	}))
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, err := client.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if got := gotHeaders[0].Get("X-Server-API-Key"); got != "alternate-key" {
+		t.Errorf("first call API key = %q, want alternate-key", got)
+	}
+	if got := gotHeaders[0].Get("X-One-Off"); got != "yes" {
+		t.Errorf("first call X-One-Off = %q, want yes", got)
+	}
+	if got := gotHeaders[1].Get("X-Server-API-Key"); got != "test-key" {
+		t.Errorf("second call API key = %q, want test-key", got)
+	}
+	if got := gotHeaders[1].Get("X-One-Off"); got != "" {
+		t.Errorf("mutator leaked into second call: X-One-Off = %q", got)
+	}
+}
+
+func TestWithCallMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12354", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(r)
+			})
+		}
+	}
+	client.WithMiddleware(tag("global"))
+
+	ctx := context.Background()
+	if _, err := client.SendRawMessage(ctx, rawTestMessage(), WithCallMiddleware(tag("call"))); err != nil {
+		t.Fatalf("SendRawMessage() error = %v", err)
+	}
+	if _, err := client.SendRawMessage(ctx, rawTestMessage()); err != nil {
+		t.Fatalf("SendRawMessage() error = %v", err)
+	}
+
+	want := "[call global global]"
+	if got := fmt.Sprint(order); got != want {
+		t.Errorf("middleware order = %s, want %s", got, want)
+	}
+}
+
+func TestWithCorrelationID(t *testing.T) {
+	var got []types.Message
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg types.Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		got = append(got, msg)
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12360", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "test-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	msg := compatTestMessage()
+	msg.Headers = map[string]string{"X-Campaign": "spring"}
+	if _, err := client.SendMessage(ctx, msg, WithCorrelationID("order-42")); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, err := client.SendMessage(ctx, msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if id := types.CorrelationID(got[0].Headers); id != "order-42" {
+		t.Errorf("sent correlation ID = %q, want order-42", id)
+	}
+	if got[0].Headers["X-Campaign"] != "spring" {
+		t.Errorf("existing headers dropped: %v", got[0].Headers)
+	}
+	if id := types.CorrelationID(got[1].Headers); id != "" {
+		t.Errorf("correlation ID leaked into second send: %q", id)
+	}
+	if _, ok := msg.Headers[types.CorrelationHeader]; ok {
+		t.Error("WithCorrelationID() modified the caller's headers")
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// SequenceStep is one send in a Sequence
+type SequenceStep struct {
+	// Name identifies the step in errors
+	Name string
+
+	// Build returns the message to send, given the results of the steps
+	// before it in order. Returning an error stops the sequence.
+	Build func(ctx context.Context, prev []*types.Result) (*types.Message, error)
+
+	// Options are passed to SendMessage for this step
+	Options []SendOption
+
+	// Rollback undoes the step when a later one fails, for example by
+	// sending a correction. It is optional.
+	Rollback func(ctx context.Context, result *types.Result) error
+}
+
+// name returns the step's name, or its position when it has none
+func (s SequenceStep) name(i int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("step %d", i+1)
+}
+
+// SequenceError reports the step that stopped a Sequence and any rollbacks
+// that failed afterwards
+type SequenceError struct {
+	Step      string
+	Err       error
+	Rollbacks []RollbackError
+}
+
+// RollbackError is a rollback that failed after a Sequence step failed
+type RollbackError struct {
+	Step string
+	Err  error
+}
+
+// Error implements the error interface
+func (e *SequenceError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sequence step %q failed: %v", e.Step, e.Err)
+	for _, r := range e.Rollbacks {
+		fmt.Fprintf(&b, "; rollback of %q failed: %v", r.Step, r.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the step error followed by the rollback errors
+func (e *SequenceError) Unwrap() []error {
+	errs := []error{e.Err}
+	for _, r := range e.Rollbacks {
+		errs = append(errs, r.Err)
+	}
+	return errs
+}
+
+// Sequence sends steps one after another, each built from the results of
+// the ones before it, such as a confirmation followed by a receipt that
+// references it. If a step fails, the completed steps are rolled back in
+// reverse order and a *SequenceError is returned with the results of the
+// completed steps. Rollbacks run even if ctx has been cancelled.
+func Sequence(ctx context.Context, sender MessageSender, steps ...SequenceStep) ([]*types.Result, error) {
+	results := make([]*types.Result, 0, len(steps))
+	for i, step := range steps {
+		err := ctx.Err()
+		var msg *types.Message
+		if err == nil {
+			msg, err = step.Build(ctx, results)
+		}
+		var result *types.Result
+		if err == nil {
+			result, err = sender.SendMessage(ctx, msg, step.Options...)
+		}
+		if err != nil {
+			return results, &SequenceError{
+				Step:      step.name(i),
+				Err:       err,
+				Rollbacks: rollback(context.WithoutCancel(ctx), steps[:i], results),
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// rollback undoes the completed steps in reverse order, returning the
+// rollbacks that failed
+func rollback(ctx context.Context, steps []SequenceStep, results []*types.Result) []RollbackError {
+	var failed []RollbackError
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Rollback == nil {
+			continue
+		}
+		if err := steps[i].Rollback(ctx, results[i]); err != nil {
+			failed = append(failed, RollbackError{Step: steps[i].name(i), Err: err})
+		}
+	}
+	return failed
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+)
+
+// failingSender numbers the messages it sends and fails the one at failAt
+type failingSender struct {
+	sent   int
+	failAt int
+	err    error
+}
+
+func (s *failingSender) SendMessage(ctx context.Context, msg *types.Message, opts ...SendOption) (*types.Result, error) {
+	s.sent++
+	if s.sent == s.failAt {
+		return nil, s.err
+	}
+	return &types.Result{MessageID: fmt.Sprintf("msg-%d", s.sent)}, nil
+}
+
+func TestSequence(t *testing.T) {
+	var rolledBack []string
+	step := func(name string, rollbackErr error) SequenceStep {
+		return SequenceStep{
+			Name: name,
+			Build: func(ctx context.Context, prev []*types.Result) (*types.Message, error) {
+				msg := compatTestMessage()
+				if len(prev) > 0 {
+					msg.Subject = "Re: " + prev[len(prev)-1].MessageID
+				}
+				return msg, nil
+			},
+			Rollback: func(ctx context.Context, result *types.Result) error {
+				rolledBack = append(rolledBack, result.MessageID)
+				return rollbackErr
+			},
+		}
+	}
+
+	t.Run("all steps succeed", func(t *testing.T) {
+		rolledBack = nil
+		results, err := Sequence(context.Background(), &failingSender{},
+			step("confirmation", nil), step("receipt", nil))
+		if err != nil {
+			t.Fatalf("Sequence() error = %v", err)
+		}
+		if len(results) != 2 || results[1].MessageID != "msg-2" {
+			t.Errorf("Sequence() results = %v, want two results", results)
+		}
+		if len(rolledBack) != 0 {
+			t.Errorf("rolled back %v, want nothing", rolledBack)
+		}
+	})
+
+	t.Run("failed step rolls back earlier ones", func(t *testing.T) {
+		rolledBack = nil
+		sendErr := errors.New("server unavailable")
+		rollbackErr := errors.New("correction rejected")
+		results, err := Sequence(context.Background(), &failingSender{failAt: 3, err: sendErr},
+			step("confirmation", rollbackErr), step("", nil), step("receipt", nil))
+
+		var seqErr *SequenceError
+		if !errors.As(err, &seqErr) {
+			t.Fatalf("Sequence() error = %v, want *SequenceError", err)
+		}
+		if seqErr.Step != "receipt" {
+			t.Errorf("failed step = %q, want receipt", seqErr.Step)
+		}
+		if !errors.Is(err, sendErr) || !errors.Is(err, rollbackErr) {
+			t.Errorf("Sequence() error = %v, want it to wrap the send and rollback errors", err)
+		}
+		if len(seqErr.Rollbacks) != 1 || seqErr.Rollbacks[0].Step != "confirmation" {
+			t.Errorf("failed rollbacks = %v, want confirmation", seqErr.Rollbacks)
+		}
+		if len(results) != 2 {
+			t.Errorf("got %d results, want the 2 completed steps", len(results))
+		}
+		if fmt.Sprint(rolledBack) != "[msg-2 msg-1]" {
+			t.Errorf("rolled back %v, want [msg-2 msg-1]", rolledBack)
+		}
+	})
+
+	t.Run("build error stops the sequence", func(t *testing.T) {
+		buildErr := errors.New("missing order")
+		sender := &failingSender{}
+		_, err := Sequence(context.Background(), sender, SequenceStep{
+			Build: func(context.Context, []*types.Result) (*types.Message, error) { return nil, buildErr },
+		})
+		if !errors.Is(err, buildErr) || !contains(err.Error(), `"step 1"`) {
+			t.Errorf("Sequence() error = %v, want the build error for step 1", err)
+		}
+		if sender.sent != 0 {
+			t.Errorf("sent %d messages, want 0", sender.sent)
+		}
+	})
+}
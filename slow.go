@@ -0,0 +1,38 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/middleware/timing"
+)
+
+// RequestTimings breaks down the time spent on one HTTP request: DNS,
+// connect, TLS and time to first byte
+type RequestTimings = timing.Timings
+
+// SlowRequestHandler receives each request that took at least the slow
+// request threshold, with the error from the round trip if it failed
+type SlowRequestHandler func(req *http.Request, timings RequestTimings, err error)
+
+// WithSlowRequestLog reports every request taking threshold or longer,
+// including each retry attempt, with its timing breakdown. With a nil
+// handler they are logged to the client's logger, whether or not debug
+// output is enabled.
+func WithSlowRequestLog(threshold time.Duration, handler SlowRequestHandler) Option {
+	return func(c *clientImpl) {
+		if handler == nil {
+			handler = func(req *http.Request, timings RequestTimings, err error) {
+				if err != nil {
+					c.logger().Printf("[SLOW] %s %s failed after %v: %s: %v", req.Method, req.URL.Path, timings.Total, timings, err)
+					return
+				}
+				c.logger().Printf("[SLOW] %s %s took %v: %s", req.Method, req.URL.Path, timings.Total, timings)
+			}
+		}
+		c.transport.AddMiddleware(timing.New(timing.Config{
+			Threshold: threshold,
+			Report:    handler,
+		}))
+	}
+}
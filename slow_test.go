@@ -0,0 +1,46 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithSlowRequestLog(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"message_id": "12355", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	client, err := NewClient(ts.URL, "test-key",
+		WithLogger(log.New(&buf, "", 0)),
+		WithSlowRequestLog(10*time.Millisecond, nil),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.SendMessage(context.Background(), compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if !contains(buf.String(), "[SLOW] POST /api/v1/send/message took") || !contains(buf.String(), "ttfb=") {
+		t.Errorf("log = %q, want a slow request line with timings", buf.String())
+	}
+
+	var calls int
+	quiet, err := NewClient(ts.URL, "test-key", WithSlowRequestLog(time.Minute, func(*http.Request, RequestTimings, error) { calls++ }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := quiet.SendMessage(context.Background(), compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("handler called %d times for a fast request, want 0", calls)
+	}
+}
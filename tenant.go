@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/privacy"
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/internal/middleware"
+	"github.com/sachin-duhan/postal-go/internal/middleware/ratelimit"
+	"github.com/sachin-duhan/postal-go/internal/transport"
+)
+
+// TenantDefaults configures a tenant view created by Client.ForTenant
+type TenantDefaults struct {
+	// Name identifies the tenant. It is added to the metrics labels as "tenant".
+	Name string
+
+	// From is used when a message does not set its own sender
+	From string
+
+	// Labels are attached to every request context made through the view;
+	// middleware can read them with types.LabelsFromContext
+	Labels map[string]string
+
+	// RequestsPerSecond limits the tenant's request rate independently of
+	// other tenants. Zero means no tenant-level limit.
+	RequestsPerSecond float64
+	// Burst is the number of requests allowed above RequestsPerSecond at once
+	Burst int
+
+	// Quota caps the number of sends per period. Sends beyond it fail with
+	// types.ErrQuotaExceeded.
+	Quota TenantQuota
+}
+
+// TenantQuota limits how many messages a tenant may send per period
+type TenantQuota struct {
+	Limit  int
+	Period time.Duration
+
+	// LimitFunc, when set, is consulted at each send instead of Limit, e.g.
+	// an ipwarmup.Schedule's LimitAt to ramp up a new IP
+	LimitFunc func(now time.Time) int
+}
+
+// quota counts sends in fixed windows of Period
+type quota struct {
+	TenantQuota
+
+	mu          sync.Mutex
+	windowStart time.Time
+	used        int
+}
+
+// take records a send, returning false if the quota for the current window
+// is used up
+func (q *quota) take(now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if now.Sub(q.windowStart) >= q.Period {
+		q.windowStart = now
+		q.used = 0
+	}
+	limit := q.Limit
+	if q.LimitFunc != nil {
+		limit = q.LimitFunc(now)
+	}
+	if q.used >= limit {
+		return false
+	}
+	q.used++
+	return true
+}
+
+// ForTenant implements Client
+func (c *clientImpl) ForTenant(apiKey string, defaults TenantDefaults) Client {
+	labels := make(map[string]string, len(defaults.Labels)+1)
+	for k, v := range defaults.Labels {
+		labels[k] = v
+	}
+	if defaults.Name != "" {
+		labels["tenant"] = defaults.Name
+	}
+	defaults.Labels = labels
+
+	view := &clientImpl{
+		baseURL:     c.baseURL,
+		apiKey:      apiKey,
+		httpClient:  c.httpClient,
+		config:      c.config,
+		transport:   c.transport,
+		tenant:      &defaults,
+		profiles:    c.profiles,
+		budget:      c.budget,
+		resultStore: c.resultStore,
+		domainStats: c.domainStats,
+		inflight:    c.inflight,
+		clock:       c.clock,
+		random:      c.random,
+	}
+
+	if defaults.RequestsPerSecond > 0 {
+		burst := defaults.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		view.viewMiddleware = append(view.viewMiddleware, ratelimit.New(ratelimit.Config{
+			RequestsPerSecond: defaults.RequestsPerSecond,
+			Burst:             burst,
+			Enabled:           true,
+		}))
+	}
+	if (defaults.Quota.Limit > 0 || defaults.Quota.LimitFunc != nil) && defaults.Quota.Period > 0 {
+		view.quota = &quota{TenantQuota: defaults.Quota}
+	}
+
+	return view
+}
+
+// applyTenant sets the tenant's API key, view middleware and labels on a
+// request. Sends are charged against the tenant's quota.
+func (c *clientImpl) applyTenant(ctx context.Context, req *transport.Request, send bool) (context.Context, error) {
+	if send && c.quota != nil && !c.quota.take(c.now()) {
+		return ctx, fmt.Errorf("tenant %q: %w", c.tenant.Name, types.ErrQuotaExceeded)
+	}
+
+	c.tenantRequest(req)
+	labels := c.tenant.Labels
+	if c.config.PrivacyMode {
+		labels = privacy.RedactLabels(labels)
+	}
+	return types.ContextWithLabels(ctx, labels), nil
+}
+
+// tenantRequest sets the view's API key and middleware on req
+func (c *clientImpl) tenantRequest(req *transport.Request) {
+	req.APIKey = c.apiKey
+	if len(c.viewMiddleware) > 0 {
+		mws := make([]middleware.Middleware, 0, len(c.viewMiddleware)+len(req.Middleware))
+		mws = append(mws, req.Middleware...)
+		req.Middleware = append(mws, c.viewMiddleware...)
+	}
+}
+
+// messageDefaults fills in tenant defaults without modifying the caller's message
+func (c *clientImpl) messageDefaults(msg *types.Message) *types.Message {
+	if c.tenant == nil || c.tenant.From == "" || msg == nil || msg.From != "" {
+		return msg
+	}
+	withDefaults := *msg
+	withDefaults.From = c.tenant.From
+	return &withDefaults
+}
+
+// rawMessageDefaults fills in tenant defaults without modifying the caller's message
+func (c *clientImpl) rawMessageDefaults(raw *types.RawMessage) *types.RawMessage {
+	if c.tenant == nil || c.tenant.From == "" || raw == nil || raw.From != "" {
+		return raw
+	}
+	withDefaults := *raw
+	withDefaults.From = c.tenant.From
+	return &withDefaults
+}
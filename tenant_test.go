@@ -0,0 +1,263 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/ipwarmup"
+)
+
+func TestForTenant(t *testing.T) {
+	type received struct {
+		apiKey string
+		from   string
+		tenant string
+	}
+	var got []received
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var msg types.Message
+		json.Unmarshal(body, &msg)
+		got = append(got, received{
+			apiKey: r.Header.Get("X-Server-API-Key"),
+			from:   msg.From,
+			tenant: r.Header.Get("X-Tenant-Label"),
+		})
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12356", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	parent, err := NewClient(ts.URL, "parent-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// Global middleware sees the tenant label through the request context
+	parent.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if tenant := types.LabelsFromContext(r.Context())["tenant"]; tenant != "" {
+				r.Header.Set("X-Tenant-Label", tenant)
+			}
+			return next.RoundTrip(r)
+		})
+	})
+
+	tenant := parent.ForTenant("tenant-key", TenantDefaults{
+		Name: "acme",
+		From: "noreply@acme.example.com",
+	})
+
+	msg := compatTestMessage()
+	msg.From = ""
+	ctx := context.Background()
+	if _, err := tenant.SendMessage(ctx, msg); err != nil {
+		t.Fatalf("tenant SendMessage() error = %v", err)
+	}
+	if msg.From != "" {
+		t.Error("ForTenant() modified the caller's message")
+	}
+	if _, err := parent.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("parent SendMessage() error = %v", err)
+	}
+
+	want := []received{
+		{apiKey: "tenant-key", from: "noreply@acme.example.com", tenant: "acme"},
+		{apiKey: "parent-key", from: "sender@example.com", tenant: ""},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestForTenantMiddlewareIsolation(t *testing.T) {
+	var tenantCalls, parentCalls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tenant-Only") != "" {
+			tenantCalls++
+		} else {
+			parentCalls++
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12357", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	parent, err := NewClient(ts.URL, "parent-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	tenant := parent.ForTenant("tenant-key", TenantDefaults{Name: "acme"})
+	tenant.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("X-Tenant-Only", "1")
+			return next.RoundTrip(r)
+		})
+	})
+
+	ctx := context.Background()
+	if _, err := tenant.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("tenant SendMessage() error = %v", err)
+	}
+	if _, err := parent.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("parent SendMessage() error = %v", err)
+	}
+
+	if tenantCalls != 1 || parentCalls != 1 {
+		t.Errorf("tenant middleware leaked: tenantCalls=%d parentCalls=%d", tenantCalls, parentCalls)
+	}
+}
+
+func TestForTenantQuota(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12358", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	parent, err := NewClient(ts.URL, "parent-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	noisy := parent.ForTenant("noisy-key", TenantDefaults{
+		Name:  "noisy",
+		Quota: TenantQuota{Limit: 2, Period: time.Hour},
+	})
+	quiet := parent.ForTenant("quiet-key", TenantDefaults{
+		Name:  "quiet",
+		Quota: TenantQuota{Limit: 2, Period: time.Hour},
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := noisy.SendMessage(ctx, compatTestMessage()); err != nil {
+			t.Fatalf("send %d error = %v", i, err)
+		}
+	}
+	_, err = noisy.SendMessage(ctx, compatTestMessage())
+	if !errors.Is(err, types.ErrQuotaExceeded) {
+		t.Errorf("third send error = %v, want ErrQuotaExceeded", err)
+	}
+
+	// Other tenants and the parent are unaffected
+	if _, err := quiet.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Errorf("quiet tenant SendMessage() error = %v", err)
+	}
+	if _, err := parent.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Errorf("parent SendMessage() error = %v", err)
+	}
+}
+
+func TestForTenantRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12359", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	parent, err := NewClient(ts.URL, "parent-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	limited := parent.ForTenant("limited-key", TenantDefaults{
+		Name:              "limited",
+		RequestsPerSecond: 20,
+		Burst:             1,
+	})
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := limited.SendMessage(ctx, compatTestMessage()); err != nil {
+			t.Fatalf("send %d error = %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 sends at 20/s took %v, want tenant limit applied", elapsed)
+	}
+
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := parent.SendMessage(ctx, compatTestMessage()); err != nil {
+			t.Fatalf("parent send %d error = %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 90*time.Millisecond {
+		t.Errorf("parent sends took %v, tenant limit leaked into parent", elapsed)
+	}
+}
+
+func TestQuotaWindow(t *testing.T) {
+	q := &quota{TenantQuota: TenantQuota{Limit: 1, Period: time.Minute}}
+	now := time.Now()
+
+	if !q.take(now) {
+		t.Fatal("first take() = false")
+	}
+	if q.take(now.Add(time.Second)) {
+		t.Error("take() within window = true, want false")
+	}
+	if !q.take(now.Add(time.Minute)) {
+		t.Error("take() in next window = false, want true")
+	}
+}
+
+func TestQuotaLimitFunc(t *testing.T) {
+	schedule := ipwarmup.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), []int{1, 2})
+	q := &quota{TenantQuota: TenantQuota{Period: 24 * time.Hour, LimitFunc: schedule.LimitAt}}
+
+	day1 := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	if !q.take(day1) || q.take(day1.Add(time.Hour)) {
+		t.Error("expected one send on the first warm-up day")
+	}
+
+	day2 := day1.Add(24 * time.Hour)
+	if !q.take(day2) || !q.take(day2.Add(time.Hour)) || q.take(day2.Add(2*time.Hour)) {
+		t.Error("expected two sends on the second warm-up day")
+	}
+}
+
+func TestContextAPIKeyOverride(t *testing.T) {
+	var keys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("X-Server-API-Key"))
+		w.WriteHeader(200)
+		w.Write([]byte(`{"message_id": "12356", "status": "success"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "client-key")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := types.ContextWithAPIKey(context.Background(), "request-key")
+	if _, err := c.SendMessage(ctx, compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, err := c.ForTenant("tenant-key", TenantDefaults{}).SendRawMessage(ctx, rawTestMessage()); err != nil {
+		t.Fatalf("tenant SendRawMessage() error = %v", err)
+	}
+	if _, err := c.SendMessage(context.Background(), compatTestMessage()); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	want := []string{"request-key", "request-key", "client-key"}
+	if len(keys) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(keys), len(want))
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("request %d key = %q, want %q", i, keys[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package conformance checks a live Postal server against this client, to
+// validate server upgrades before rolling them out. Run the suite with
+//
+//	POSTAL_CONFORMANCE_URL=https://postal.example.com \
+//	POSTAL_CONFORMANCE_API_KEY=... \
+//	POSTAL_CONFORMANCE_FROM=conformance@example.com \
+//	POSTAL_CONFORMANCE_TO=sink@example.com \
+//	go test -v ./tests/conformance/
+//
+// or call Run from a program. Test messages are really sent, so point
+// POSTAL_CONFORMANCE_TO at a mailbox you control.
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	client "github.com/sachin-duhan/postal-go"
+	"github.com/sachin-duhan/postal-go/common/types"
+	"github.com/sachin-duhan/postal-go/rawmail"
+)
+
+// Environment variables read by ConfigFromEnv
+const (
+	EnvURL    = "POSTAL_CONFORMANCE_URL"
+	EnvAPIKey = "POSTAL_CONFORMANCE_API_KEY"
+	EnvFrom   = "POSTAL_CONFORMANCE_FROM"
+	EnvTo     = "POSTAL_CONFORMANCE_TO"
+)
+
+// Config identifies the server under test and the addresses to send with
+type Config struct {
+	BaseURL string
+	APIKey  string
+	// From must be an address on a domain the server can send for
+	From string
+	// To receives the test messages
+	To []string
+	// Options are passed to NewClient, e.g. compatibility settings
+	Options []client.Option
+}
+
+// ConfigFromEnv reads the POSTAL_CONFORMANCE_* variables. It returns false
+// if any is unset, in which case the suite should be skipped.
+func ConfigFromEnv() (Config, bool) {
+	cfg := Config{
+		BaseURL: os.Getenv(EnvURL),
+		APIKey:  os.Getenv(EnvAPIKey),
+		From:    os.Getenv(EnvFrom),
+	}
+	if to := os.Getenv(EnvTo); to != "" {
+		cfg.To = strings.Split(to, ",")
+	}
+	ok := cfg.BaseURL != "" && cfg.APIKey != "" && cfg.From != "" && len(cfg.To) > 0
+	return cfg, ok
+}
+
+// Status is the outcome of a check
+type Status string
+
+// Check outcomes
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Check is the outcome of one conformance check
+type Check struct {
+	Name   string
+	Status Status
+	// Detail explains a failure or skip, or describes what passed
+	Detail string
+}
+
+// Report lists the checks run against a server
+type Report struct {
+	BaseURL string
+	Checks  []Check
+}
+
+// Passed returns true if no check failed
+func (r *Report) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the report as one line per check
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "conformance report for %s\n", r.BaseURL)
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "  %-4s %-16s %s\n", c.Status, c.Name, c.Detail)
+	}
+	return b.String()
+}
+
+// Run checks the server's capabilities, structured and raw sends, and
+// which optional endpoints are available. An error is returned only if
+// the client cannot be created.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	c, err := client.NewClient(cfg.BaseURL, cfg.APIKey, cfg.Options...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{BaseURL: cfg.BaseURL}
+	add := func(name string, status Status, detail string) {
+		report.Checks = append(report.Checks, Check{Name: name, Status: status, Detail: detail})
+	}
+	stamp := time.Now().UTC().Format(time.RFC3339)
+
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		add("capabilities", StatusFail, err.Error())
+	} else {
+		var supported []string
+		for _, capability := range types.AllCapabilities {
+			if caps.Supports(capability) {
+				supported = append(supported, string(capability))
+			}
+		}
+		add("capabilities", StatusPass, strings.Join(supported, ", "))
+	}
+
+	result, err := c.SendMessage(ctx, &types.Message{
+		To:       cfg.To,
+		From:     cfg.From,
+		Subject:  "postal-go conformance: send/message " + stamp,
+		HTMLBody: "<p>postal-go conformance check</p>",
+	})
+	status, detail := sendStatus(result, err)
+	add("send/message", status, detail)
+
+	raw, err := rawmail.NewRawBuilder().
+		WithFrom(cfg.From).
+		WithTo(cfg.To...).
+		WithSubject("postal-go conformance: send/raw " + stamp).
+		WithText("postal-go conformance check").
+		Build()
+	if err != nil {
+		add("send/raw", StatusFail, "failed to build message: "+err.Error())
+	} else {
+		result, err = c.SendRawMessage(ctx, raw)
+		status, detail := sendStatus(result, err)
+		add("send/raw", status, detail)
+	}
+
+	for _, capability := range []types.Capability{types.CapabilityMessageDetails, types.CapabilityDeliveries} {
+		switch {
+		case caps == nil:
+			add(string(capability), StatusSkip, "capabilities unknown")
+		case caps.Supports(capability):
+			add(string(capability), StatusPass, "endpoint available")
+		default:
+			add(string(capability), StatusSkip, "endpoint not available on this server")
+		}
+	}
+
+	// Webhook delivery needs a public URL registered on the server, which
+	// the suite cannot set up on its own
+	add("webhooks", StatusSkip, "needs a webhook endpoint registered on the server")
+	return report, nil
+}
+
+// sendStatus turns a send outcome into a check status and detail
+func sendStatus(result *types.Result, err error) (Status, string) {
+	switch {
+	case err != nil:
+		return StatusFail, err.Error()
+	case result.Failed():
+		return StatusFail, "status " + result.Status
+	case result.MessageID == "":
+		return StatusFail, "response has no message ID"
+	}
+	return StatusPass, "message " + result.MessageID
+}
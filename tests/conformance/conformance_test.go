@@ -0,0 +1,82 @@
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestConformance runs the suite against the server named by the
+// POSTAL_CONFORMANCE_* variables
+func TestConformance(t *testing.T) {
+	cfg, ok := ConfigFromEnv()
+	if !ok {
+		t.Skipf("set %s, %s, %s and %s to run against a Postal server", EnvURL, EnvAPIKey, EnvFrom, EnvTo)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	report, err := Run(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	t.Log(report)
+	for _, c := range report.Checks {
+		if c.Status == StatusFail {
+			t.Errorf("%s: %s", c.Name, c.Detail)
+		}
+	}
+}
+
+// TestRunReport checks the suite itself against a stand-in server that
+// supports sends but not message details
+func TestRunReport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/send/message"):
+			w.Write([]byte(`{"message_id": "m-1", "status": "success"}`))
+		case strings.HasSuffix(r.URL.Path, "/send/raw"):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"code": "UnauthenticatedFromAddress", "message": "from address not allowed"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	report, err := Run(context.Background(), Config{
+		BaseURL: ts.URL,
+		APIKey:  "test-key",
+		From:    "conformance@example.com",
+		To:      []string{"sink@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]Status{
+		"capabilities":        StatusPass,
+		"send/message":        StatusPass,
+		"send/raw":            StatusFail,
+		"messages/message":    StatusSkip,
+		"messages/deliveries": StatusSkip,
+		"webhooks":            StatusSkip,
+	}
+	if len(report.Checks) != len(want) {
+		t.Fatalf("got %d checks, want %d:\n%s", len(report.Checks), len(want), report)
+	}
+	for _, c := range report.Checks {
+		if c.Status != want[c.Name] {
+			t.Errorf("%s = %s (%s), want %s", c.Name, c.Status, c.Detail, want[c.Name])
+		}
+	}
+	if report.Passed() {
+		t.Error("Passed() = true with a failing check")
+	}
+	if !strings.Contains(report.String(), "fail send/raw") {
+		t.Errorf("String() =\n%s", report)
+	}
+}
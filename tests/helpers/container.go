@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// EnvPostalImage names the image StartPostalContainer runs when the config
+// does not set one
+const EnvPostalImage = "POSTAL_TEST_IMAGE"
+
+// ContainerConfig configures StartPostalContainer
+type ContainerConfig struct {
+	// Image is a Postal image, or a lightweight stand-in serving the same
+	// API. Defaults to $POSTAL_TEST_IMAGE.
+	Image string
+	// Port is the container port the API listens on. Defaults to 5000.
+	Port int
+	// APIKey is the server API key the container is seeded with
+	APIKey string
+	// Env is passed to the container
+	Env map[string]string
+	// Args are appended to the image's command
+	Args []string
+	// ReadyPath is polled until it answers without a 5xx status. Defaults to "/".
+	ReadyPath string
+	// StartupTimeout bounds the wait for readiness. Defaults to two minutes.
+	StartupTimeout time.Duration
+}
+
+// PostalContainer is a disposable Postal server running in Docker
+type PostalContainer struct {
+	ID      string
+	BaseURL string
+	APIKey  string
+}
+
+// runDocker runs the docker CLI and returns its standard output; tests
+// replace it
+var runDocker = func(ctx context.Context, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// dockerAvailable reports whether the docker CLI can be run; tests replace it
+var dockerAvailable = func() bool {
+	_, err := exec.LookPath("docker")
+	return err == nil
+}
+
+// StartPostalContainer boots a container, waits until its API answers and
+// removes it when the test ends. The test is skipped in short mode, when
+// Docker is not installed or when no image is configured.
+func StartPostalContainer(t testing.TB, cfg ContainerConfig) *PostalContainer {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping container test in short mode")
+	}
+	if cfg.Image == "" {
+		cfg.Image = os.Getenv(EnvPostalImage)
+	}
+	if cfg.Image == "" {
+		t.Skipf("set %s to run container tests", EnvPostalImage)
+	}
+	if !dockerAvailable() {
+		t.Skip("docker is not available")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 5000
+	}
+	if cfg.ReadyPath == "" {
+		cfg.ReadyPath = "/"
+	}
+	if cfg.StartupTimeout == 0 {
+		cfg.StartupTimeout = 2 * time.Minute
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.StartupTimeout)
+	defer cancel()
+
+	args := []string{"run", "--detach", "--publish", fmt.Sprintf("127.0.0.1::%d", cfg.Port)}
+	for k, v := range cfg.Env {
+		args = append(args, "--env", k+"="+v)
+	}
+	args = append(args, cfg.Image)
+	args = append(args, cfg.Args...)
+
+	out, err := runDocker(ctx, args...)
+	if err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+	id := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := runDocker(ctx, "rm", "--force", "--volumes", id); err != nil {
+			t.Logf("failed to remove container %s: %v", id, err)
+		}
+	})
+
+	out, err = runDocker(ctx, "port", id, fmt.Sprintf("%d/tcp", cfg.Port))
+	if err != nil {
+		t.Fatalf("failed to find container port: %v", err)
+	}
+	// docker port prints one mapping per line, e.g. "127.0.0.1:49153"
+	hostPort := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		t.Fatalf("unexpected docker port output %q", out)
+	}
+
+	c := &PostalContainer{ID: id, BaseURL: "http://" + hostPort, APIKey: cfg.APIKey}
+	if err := waitReady(ctx, c.BaseURL+cfg.ReadyPath); err != nil {
+		logs, _ := runDocker(context.Background(), "logs", "--tail", "50", id)
+		t.Fatalf("container %s not ready: %v\n%s", id, err, logs)
+	}
+	return c
+}
+
+// waitReady polls url until it answers without a server error
+func waitReady(ctx context.Context, url string) error {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < http.StatusInternalServerError {
+				return nil
+			}
+			err = fmt.Errorf("status %d", resp.StatusCode)
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+	}
+}
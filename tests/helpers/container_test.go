@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStartPostalContainer(t *testing.T) {
+	var ready bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ready {
+			ready = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var calls []string
+	origRun, origAvailable := runDocker, dockerAvailable
+	t.Cleanup(func() { runDocker, dockerAvailable = origRun, origAvailable })
+	dockerAvailable = func() bool { return true }
+	runDocker = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		switch args[0] {
+		case "run":
+			return []byte("abc123\n"), nil
+		case "port":
+			return []byte(strings.TrimPrefix(ts.URL, "http://") + "\n"), nil
+		}
+		return nil, nil
+	}
+
+	t.Run("container", func(t *testing.T) {
+		c := StartPostalContainer(t, ContainerConfig{
+			Image:  "postal-standin:test",
+			APIKey: "test-key",
+			Env:    map[string]string{"POSTAL_API_KEY": "test-key"},
+		})
+		if c.ID != "abc123" || c.BaseURL != ts.URL || c.APIKey != "test-key" {
+			t.Errorf("container = %+v", c)
+		}
+	})
+
+	want := []string{
+		"run --detach --publish 127.0.0.1::5000 --env POSTAL_API_KEY=test-key postal-standin:test",
+		"port abc123 5000/tcp",
+		"rm --force --volumes abc123",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("docker calls =\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/clock"
+)
+
+// DefaultDedupeTTL is how long delivered event UUIDs are remembered when
+// Dedupe is given no TTL
+const DefaultDedupeTTL = 24 * time.Hour
+
+// DedupeStore remembers which deliveries have been handled. Implementations
+// shared by several processes, such as one backed by Redis SET NX, make
+// deduplication work across replicas.
+type DedupeStore interface {
+	// Claim records key for ttl and reports whether it was not already
+	// recorded
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release forgets key, so that a redelivery is handled again
+	Release(ctx context.Context, key string) error
+}
+
+// Dedupe wraps fn so that an event Postal redelivers is passed to it at
+// most once within ttl (DefaultDedupeTTL when zero). Events are keyed by
+// their envelope UUID, which Postal keeps across retries; those without one
+// are always handled. When fn fails, the claim is released so that Postal's
+// retry is handled again.
+func Dedupe(store DedupeStore, ttl time.Duration, fn HandlerFunc) HandlerFunc {
+	if ttl <= 0 {
+		ttl = DefaultDedupeTTL
+	}
+	return func(ctx context.Context, env *Envelope, ev Event) error {
+		if env.UUID == "" {
+			return fn(ctx, env, ev)
+		}
+		key := "postal-webhook:" + env.UUID
+
+		first, err := store.Claim(ctx, key, ttl)
+		if err != nil {
+			return fmt.Errorf("webhooks: dedupe claim failed: %w", err)
+		}
+		if !first {
+			return nil
+		}
+		if err := fn(ctx, env, ev); err != nil {
+			// The response already reports the failure, so a release error
+			// only means the redelivery may be dropped as a duplicate
+			_ = store.Release(context.WithoutCancel(ctx), key)
+			return err
+		}
+		return nil
+	}
+}
+
+// MemoryDedupeStore is a DedupeStore for a single process
+type MemoryDedupeStore struct {
+	// Clock tells the time for expiry. Defaults to clock.Real.
+	Clock clock.Clock
+
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+// NewMemoryDedupeStore creates an empty MemoryDedupeStore
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{}
+}
+
+// Claim implements DedupeStore
+func (s *MemoryDedupeStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if expiry, ok := s.expires[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	if s.expires == nil {
+		s.expires = make(map[string]time.Time)
+	}
+	s.expires[key] = now.Add(ttl)
+
+	// Sweep expired keys as the map grows so it stays bounded by the
+	// delivery rate times ttl
+	if len(s.expires)%1024 == 0 {
+		for k, expiry := range s.expires {
+			if !now.Before(expiry) {
+				delete(s.expires, k)
+			}
+		}
+	}
+	return true, nil
+}
+
+// Release implements DedupeStore
+func (s *MemoryDedupeStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expires, key)
+	return nil
+}
+
+// Len returns the number of remembered keys, including expired ones not
+// yet swept
+func (s *MemoryDedupeStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.expires)
+}
+
+func (s *MemoryDedupeStore) now() time.Time {
+	if s.Clock == nil {
+		return time.Now()
+	}
+	return s.Clock.Now()
+}
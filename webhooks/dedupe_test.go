@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/clock"
+)
+
+func TestDedupe(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := &MemoryDedupeStore{Clock: clk}
+
+	var calls int
+	var fail error
+	h := Dedupe(store, time.Hour, func(ctx context.Context, env *Envelope, ev Event) error {
+		calls++
+		return fail
+	})
+
+	ctx := context.Background()
+	deliver := func(uuid string) error {
+		return h(ctx, &Envelope{Event: EventMessageSent, UUID: uuid}, &MessageSent{})
+	}
+
+	// A redelivery of a handled event is acknowledged without a call
+	deliver("a")
+	deliver("a")
+	if calls != 1 {
+		t.Errorf("calls after redelivery = %d, want 1", calls)
+	}
+
+	// A failed delivery is handled again when Postal retries it
+	fail = errors.New("db down")
+	if err := deliver("b"); !errors.Is(err, fail) {
+		t.Errorf("deliver() error = %v, want %v", err, fail)
+	}
+	fail = nil
+	deliver("b")
+	if calls != 3 {
+		t.Errorf("calls after retry = %d, want 3", calls)
+	}
+
+	// Events without a UUID are never deduplicated
+	deliver("")
+	deliver("")
+	if calls != 5 {
+		t.Errorf("calls for events without UUID = %d, want 5", calls)
+	}
+
+	// Once the TTL passes the UUID is forgotten
+	clk.Advance(time.Hour)
+	deliver("a")
+	if calls != 6 {
+		t.Errorf("calls after TTL = %d, want 6", calls)
+	}
+}
+
+func TestDedupeDistinctEvents(t *testing.T) {
+	// Events about the same message are distinct deliveries
+	var calls int
+	h := Dedupe(NewMemoryDedupeStore(), 0, func(ctx context.Context, env *Envelope, ev Event) error {
+		calls++
+		return nil
+	})
+	msg := Message{ID: 7}
+	h(context.Background(), &Envelope{Event: EventMessageDelayed, UUID: "a"}, &MessageDelayed{DeliveryStatus{Message: msg}})
+	h(context.Background(), &Envelope{Event: EventMessageSent, UUID: "b"}, &MessageSent{DeliveryStatus{Message: msg}})
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Claim(context.Context, string, time.Duration) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func (failingStore) Release(context.Context, string) error { return nil }
+
+func TestDedupeStoreError(t *testing.T) {
+	h := Dedupe(failingStore{}, 0, func(ctx context.Context, env *Envelope, ev Event) error {
+		t.Error("handler called although the store failed")
+		return nil
+	})
+	if err := h(context.Background(), &Envelope{UUID: "a"}, &MessageSent{}); err == nil {
+		t.Error("Dedupe() with failing store returned nil, want an error so Postal retries")
+	}
+}
@@ -0,0 +1,200 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventStore records received events so that each is processed once and
+// those interrupted by a crash can be replayed. Implementations must keep
+// the first recording of a UUID.
+type EventStore interface {
+	// Record saves env unless its UUID is already recorded, and reports
+	// whether the event has been processed
+	Record(ctx context.Context, env *Envelope) (processed bool, err error)
+	// MarkProcessed records that the event with uuid was handled
+	MarkProcessed(ctx context.Context, uuid string) error
+	// Unprocessed returns recorded events not yet processed, oldest first.
+	// A limit above zero returns at most that many.
+	Unprocessed(ctx context.Context, limit int) ([]*Envelope, error)
+}
+
+// Persist wraps fn so that each event is recorded in store before it is
+// handled and marked processed once fn succeeds. Events already processed
+// are acknowledged without calling fn; those fn fails on stay recorded for
+// Postal's retry or Replay. Events without a UUID are handled unrecorded.
+func Persist(store EventStore, fn HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, env *Envelope, ev Event) error {
+		if env.UUID == "" {
+			return fn(ctx, env, ev)
+		}
+		processed, err := store.Record(ctx, env)
+		if err != nil {
+			return fmt.Errorf("webhooks: failed to record event: %w", err)
+		}
+		if processed {
+			return nil
+		}
+		if err := fn(ctx, env, ev); err != nil {
+			return err
+		}
+		if err := store.MarkProcessed(ctx, env.UUID); err != nil {
+			return fmt.Errorf("webhooks: failed to mark event processed: %w", err)
+		}
+		return nil
+	}
+}
+
+// Replay passes the events in store that were recorded but never processed
+// to fn, oldest first, marking each processed when fn succeeds. It returns
+// how many were processed and the errors of those that were not, which stay
+// recorded. Run it at startup, before the endpoint receives events, so that
+// a replayed event is not handled concurrently with Postal's retry of it.
+func Replay(ctx context.Context, store EventStore, fn HandlerFunc) (int, error) {
+	envs, err := store.Unprocessed(ctx, 0)
+	if err != nil {
+		return 0, fmt.Errorf("webhooks: failed to load events: %w", err)
+	}
+
+	var (
+		n    int
+		errs []error
+	)
+	for _, env := range envs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		ev, err := env.Decode()
+		if err == nil {
+			err = fn(ctx, env, ev)
+		}
+		if err == nil {
+			err = store.MarkProcessed(ctx, env.UUID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("event %s: %w", env.UUID, err))
+			continue
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
+
+// MemoryEventStore is an EventStore for a single process. Its events do
+// not survive a restart, so it suits tests and deduplication more than
+// crash recovery. Set MaxEvents or Retention to bound its memory; a
+// redelivery of an event dropped by either is handled again.
+type MemoryEventStore struct {
+	// MaxEvents caps the number of retained events, dropping the oldest.
+	// Zero means unlimited.
+	MaxEvents int
+	// Retention drops events received longer ago than this on each Record.
+	// Zero keeps events until MaxEvents is reached.
+	Retention time.Duration
+
+	mu     sync.Mutex
+	events []*memoryEvent
+	byUUID map[string]*memoryEvent
+}
+
+// memoryEvent is a recorded event and its state
+type memoryEvent struct {
+	env       Envelope
+	received  time.Time
+	processed bool
+}
+
+// NewMemoryEventStore creates an empty MemoryEventStore retaining at most
+// maxEvents events, or any number when zero
+func NewMemoryEventStore(maxEvents int) *MemoryEventStore {
+	return &MemoryEventStore{MaxEvents: maxEvents, byUUID: make(map[string]*memoryEvent)}
+}
+
+// Record implements EventStore
+func (s *MemoryEventStore) Record(ctx context.Context, env *Envelope) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byUUID == nil {
+		s.byUUID = make(map[string]*memoryEvent)
+	}
+	if e, ok := s.byUUID[env.UUID]; ok {
+		return e.processed, nil
+	}
+
+	now := time.Now()
+	e := &memoryEvent{env: *env, received: now}
+	s.events = append(s.events, e)
+	s.byUUID[env.UUID] = e
+	if s.MaxEvents > 0 && len(s.events) > s.MaxEvents {
+		s.drop(len(s.events) - s.MaxEvents)
+	}
+	if s.Retention > 0 {
+		s.purge(now.Add(-s.Retention))
+	}
+	return false, nil
+}
+
+// MarkProcessed implements EventStore
+func (s *MemoryEventStore) MarkProcessed(ctx context.Context, uuid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.byUUID[uuid]
+	if !ok {
+		return fmt.Errorf("event %s is not recorded", uuid)
+	}
+	e.processed = true
+	return nil
+}
+
+// Unprocessed implements EventStore
+func (s *MemoryEventStore) Unprocessed(ctx context.Context, limit int) ([]*Envelope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*Envelope
+	for _, e := range s.events {
+		if e.processed {
+			continue
+		}
+		saved := e.env
+		out = append(out, &saved)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Purge deletes events received before olderThan and returns how many were
+// deleted
+func (s *MemoryEventStore) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.purge(olderThan), nil
+}
+
+// purge removes events received before cutoff. Events are kept in the order
+// received, so they are dropped from the front. It must be called with mu
+// held.
+func (s *MemoryEventStore) purge(cutoff time.Time) int64 {
+	n := 0
+	for n < len(s.events) && s.events[n].received.Before(cutoff) {
+		n++
+	}
+	s.drop(n)
+	return int64(n)
+}
+
+// drop removes the n oldest events. It must be called with mu held.
+func (s *MemoryEventStore) drop(n int) {
+	if n == 0 {
+		return
+	}
+	for _, e := range s.events[:n] {
+		delete(s.byUUID, e.env.UUID)
+	}
+	s.events = append([]*memoryEvent(nil), s.events[n:]...)
+}
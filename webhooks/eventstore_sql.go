@@ -0,0 +1,143 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/sqlutil"
+)
+
+// Placeholder styles for SQL parameters
+const (
+	// PlaceholderQuestion uses "?" (MySQL, SQLite)
+	PlaceholderQuestion = sqlutil.PlaceholderQuestion
+	// PlaceholderDollar uses "$1", "$2"... (PostgreSQL)
+	PlaceholderDollar = sqlutil.PlaceholderDollar
+)
+
+// SQLConfig configures an SQLEventStore
+type SQLConfig struct {
+	// Table defaults to "postal_webhook_events"
+	Table string
+	// Placeholder selects the parameter style, PlaceholderQuestion by default
+	Placeholder int
+	// Retention deletes events received longer ago than this, processed or
+	// not, checked at most once a minute when recording. Zero keeps events
+	// until Purge is called.
+	Retention time.Duration
+}
+
+// SQLEventStore keeps events in a database/sql table keyed by UUID, so that
+// several replicas can share it
+type SQLEventStore struct {
+	db     *sql.DB
+	config SQLConfig
+
+	// purges spaces out the deletion of expired events
+	purges sqlutil.PurgeSchedule
+}
+
+// NewSQLEventStore creates a store using db. Call CreateTable to create
+// the table if it does not exist yet.
+func NewSQLEventStore(db *sql.DB, cfg SQLConfig) *SQLEventStore {
+	if cfg.Table == "" {
+		cfg.Table = "postal_webhook_events"
+	}
+	return &SQLEventStore{db: db, config: cfg}
+}
+
+// CreateTable creates the events table if it does not exist
+func (s *SQLEventStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	uuid VARCHAR(64) NOT NULL PRIMARY KEY,
+	event VARCHAR(64) NOT NULL,
+	event_time DOUBLE PRECISION NOT NULL,
+	payload TEXT NOT NULL,
+	received_at TIMESTAMP NOT NULL,
+	processed_at TIMESTAMP NULL
+)`, s.config.Table))
+	return err
+}
+
+// Record implements EventStore. The insert of a UUID already recorded
+// fails on the primary key, and the existing row is reported instead.
+func (s *SQLEventStore) Record(ctx context.Context, env *Envelope) (bool, error) {
+	query := fmt.Sprintf("INSERT INTO %s (uuid, event, event_time, payload, received_at) VALUES (%s)",
+		s.config.Table, sqlutil.Placeholders(s.config.Placeholder, 1, 5))
+	now := time.Now()
+	_, insertErr := s.db.ExecContext(ctx, query, env.UUID, env.Event, env.Timestamp, string(env.Payload), now.UTC())
+	if insertErr == nil {
+		if s.config.Retention > 0 && s.purges.Due(now) {
+			if _, err := s.Purge(ctx, now.Add(-s.config.Retention)); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	var processedAt sql.NullTime
+	query = fmt.Sprintf("SELECT processed_at FROM %s WHERE uuid = %s", s.config.Table, s.placeholder(1))
+	err := s.db.QueryRowContext(ctx, query, env.UUID).Scan(&processedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("failed to record event: %w", insertErr)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record event: %w", err)
+	}
+	return processedAt.Valid, nil
+}
+
+// MarkProcessed implements EventStore
+func (s *SQLEventStore) MarkProcessed(ctx context.Context, uuid string) error {
+	query := fmt.Sprintf("UPDATE %s SET processed_at = %s WHERE uuid = %s", s.config.Table, s.placeholder(1), s.placeholder(2))
+	if _, err := s.db.ExecContext(ctx, query, time.Now().UTC(), uuid); err != nil {
+		return fmt.Errorf("failed to mark event processed: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes events received before olderThan and returns how many were
+// deleted
+func (s *SQLEventStore) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE received_at < %s", s.config.Table, s.placeholder(1))
+	res, err := s.db.ExecContext(ctx, query, olderThan.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge events: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Unprocessed implements EventStore
+func (s *SQLEventStore) Unprocessed(ctx context.Context, limit int) ([]*Envelope, error) {
+	query := fmt.Sprintf("SELECT uuid, event, event_time, payload FROM %s WHERE processed_at IS NULL ORDER BY received_at", s.config.Table)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*Envelope
+	for rows.Next() {
+		var (
+			env     Envelope
+			payload string
+		)
+		if err := rows.Scan(&env.UUID, &env.Event, &env.Timestamp, &payload); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		env.Payload = []byte(payload)
+		out = append(out, &env)
+	}
+	return out, rows.Err()
+}
+
+// placeholder returns the n-th (1-based) parameter placeholder
+func (s *SQLEventStore) placeholder(n int) string {
+	return sqlutil.Placeholder(s.config.Placeholder, n)
+}
@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/internal/sqlutil/sqltest"
+)
+
+// openEvents opens a fake database whose inserts of a UUID already inserted
+// fail, as on the primary key
+func openEvents(t *testing.T) (*sql.DB, *sqltest.DB) {
+	t.Helper()
+	db, d := sqltest.Open(t)
+	known := map[string]bool{}
+	d.OnExec = func(query string, args []driver.Value) error {
+		if strings.HasPrefix(query, "INSERT") {
+			uuid := args[0].(string)
+			if known[uuid] {
+				return errors.New("duplicate key")
+			}
+			known[uuid] = true
+		}
+		return nil
+	}
+	return db, d
+}
+
+func TestSQLEventStoreRecord(t *testing.T) {
+	db, d := openEvents(t)
+	store := NewSQLEventStore(db, SQLConfig{Placeholder: PlaceholderDollar})
+	ctx := context.Background()
+	env := &Envelope{Event: EventMessageSent, Timestamp: 1477945177.5, UUID: "a", Payload: []byte(`{"status":"Sent"}`)}
+
+	processed, err := store.Record(ctx, env)
+	if err != nil || processed {
+		t.Fatalf("Record() = %v, %v; want a new event", processed, err)
+	}
+	insert := d.Execs[0]
+	if !strings.HasPrefix(insert.Query, "INSERT INTO postal_webhook_events (uuid, event, event_time, payload, received_at) VALUES ($1, $2, $3, $4, $5)") {
+		t.Errorf("query = %q", insert.Query)
+	}
+	if insert.Args[0] != "a" || insert.Args[1] != EventMessageSent || insert.Args[3] != `{"status":"Sent"}` {
+		t.Errorf("args = %v", insert.Args)
+	}
+
+	// A redelivery reports the existing row's state
+	d.Columns = []string{"processed_at"}
+	d.Rows = [][]driver.Value{{time.Now()}}
+	processed, err = store.Record(ctx, env)
+	if err != nil || !processed {
+		t.Errorf("Record() of processed event = %v, %v; want processed", processed, err)
+	}
+	d.Rows = [][]driver.Value{{nil}}
+	if processed, err = store.Record(ctx, env); err != nil || processed {
+		t.Errorf("Record() of unprocessed event = %v, %v; want unprocessed", processed, err)
+	}
+
+	// An insert failing for another reason is returned
+	d.Rows = nil
+	if _, err := store.Record(ctx, env); err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("Record() without existing row error = %v, want the insert error", err)
+	}
+
+	if err := store.MarkProcessed(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	update := d.Execs[len(d.Execs)-1]
+	if update.Query != "UPDATE postal_webhook_events SET processed_at = $1 WHERE uuid = $2" || update.Args[1] != "a" {
+		t.Errorf("update = %+v", update)
+	}
+}
+
+func TestSQLEventStoreUnprocessed(t *testing.T) {
+	db, d := openEvents(t)
+	store := NewSQLEventStore(db, SQLConfig{Table: "events"})
+	d.Columns = []string{"uuid", "event", "event_time", "payload"}
+	d.Rows = [][]driver.Value{
+		{"a", EventMessageSent, 1477945177.5, `{"status":"Sent"}`},
+		{"b", EventMessageHeld, 1477945178.0, `{"status":"Held"}`},
+	}
+
+	envs, err := store.Unprocessed(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Queries[0].Query; got != "SELECT uuid, event, event_time, payload FROM events WHERE processed_at IS NULL ORDER BY received_at LIMIT 10" {
+		t.Errorf("query = %q", got)
+	}
+	if len(envs) != 2 || envs[1].UUID != "b" || envs[0].Timestamp != 1477945177.5 {
+		t.Fatalf("Unprocessed() = %+v", envs)
+	}
+	ev, err := envs[1].Decode()
+	if err != nil || ev.(*MessageHeld).Status != "Held" {
+		t.Errorf("Decode() = %+v, %v", ev, err)
+	}
+}
+
+func TestSQLEventStorePurge(t *testing.T) {
+	db, d := openEvents(t)
+	store := NewSQLEventStore(db, SQLConfig{Placeholder: PlaceholderDollar})
+
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	if _, err := store.Purge(context.Background(), cutoff); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	exec := d.Execs[0]
+	if exec.Query != "DELETE FROM postal_webhook_events WHERE received_at < $1" {
+		t.Errorf("query = %q", exec.Query)
+	}
+	if want := []driver.Value{cutoff.UTC()}; !reflect.DeepEqual(exec.Args, want) {
+		t.Errorf("args = %v, want %v", exec.Args, want)
+	}
+}
+
+func TestSQLEventStoreRetention(t *testing.T) {
+	db, d := openEvents(t)
+	store := NewSQLEventStore(db, SQLConfig{Retention: 24 * time.Hour})
+
+	for _, uuid := range []string{"a", "b", "c"} {
+		if _, err := store.Record(context.Background(), &Envelope{Event: EventMessageSent, UUID: uuid}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// Expired events are deleted on the first record, then once a minute
+	var deletes int
+	for _, exec := range d.Execs {
+		if strings.HasPrefix(exec.Query, "DELETE FROM postal_webhook_events") {
+			deletes++
+		}
+	}
+	if deletes != 1 {
+		t.Errorf("got %d purges over 3 records, want 1", deletes)
+	}
+}
@@ -0,0 +1,143 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPersistAndReplay(t *testing.T) {
+	store := NewMemoryEventStore(0)
+	ctx := context.Background()
+
+	var handled []string
+	var fail error
+	h := Persist(store, func(ctx context.Context, env *Envelope, ev Event) error {
+		handled = append(handled, env.UUID)
+		return fail
+	})
+	deliver := func(uuid string) error {
+		env, err := ParseEnvelope([]byte(`{"event": "MessageSent", "uuid": "` + uuid + `", "payload": {"status": "Sent"}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev, err := env.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h(ctx, env, ev)
+	}
+
+	// A processed event is acknowledged without a call when redelivered
+	deliver("a")
+	deliver("a")
+	if len(handled) != 1 {
+		t.Errorf("handled = %v after redelivery, want one call", handled)
+	}
+
+	// Failed events stay recorded as unprocessed
+	fail = errors.New("crashed")
+	if err := deliver("b"); !errors.Is(err, fail) {
+		t.Errorf("deliver() error = %v, want %v", err, fail)
+	}
+	deliver("c")
+	pending, err := store.Unprocessed(ctx, 0)
+	if err != nil || len(pending) != 2 || pending[0].UUID != "b" || pending[1].UUID != "c" {
+		t.Fatalf("Unprocessed() = %v, %v; want b and c", pending, err)
+	}
+	if limited, _ := store.Unprocessed(ctx, 1); len(limited) != 1 {
+		t.Errorf("Unprocessed(1) returned %d events", len(limited))
+	}
+
+	// Replay handles them again with the decoded event
+	handled = nil
+	fail = nil
+	var replayed []Event
+	n, err := Replay(ctx, store, func(ctx context.Context, env *Envelope, ev Event) error {
+		replayed = append(replayed, ev)
+		if env.UUID == "c" {
+			return errors.New("still failing")
+		}
+		return nil
+	})
+	if n != 1 || err == nil {
+		t.Errorf("Replay() = %d, %v; want 1 and c's error", n, err)
+	}
+	if len(replayed) != 2 || replayed[0].(*MessageSent).Status != "Sent" {
+		t.Errorf("replayed = %v", replayed)
+	}
+	pending, _ = store.Unprocessed(ctx, 0)
+	if len(pending) != 1 || pending[0].UUID != "c" {
+		t.Errorf("Unprocessed() after replay = %v, want c", pending)
+	}
+}
+
+func TestPersistWithoutUUID(t *testing.T) {
+	store := NewMemoryEventStore(0)
+	var calls int
+	h := Persist(store, func(ctx context.Context, env *Envelope, ev Event) error {
+		calls++
+		return nil
+	})
+	h(context.Background(), &Envelope{Event: EventMessageSent}, &MessageSent{})
+	h(context.Background(), &Envelope{Event: EventMessageSent}, &MessageSent{})
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if pending, _ := store.Unprocessed(context.Background(), 0); len(pending) != 0 {
+		t.Errorf("Unprocessed() = %v, want none", pending)
+	}
+}
+
+func TestMemoryEventStoreLimits(t *testing.T) {
+	ctx := context.Background()
+	record := func(s *MemoryEventStore, uuids ...string) {
+		for _, uuid := range uuids {
+			if _, err := s.Record(ctx, &Envelope{Event: EventMessageSent, UUID: uuid}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	uuids := func(s *MemoryEventStore) []string {
+		var out []string
+		for _, e := range s.events {
+			out = append(out, e.env.UUID)
+		}
+		return out
+	}
+
+	// MaxEvents drops the oldest, which are then recorded as new again
+	store := NewMemoryEventStore(2)
+	record(store, "a", "b", "c")
+	if got := uuids(store); len(got) != 2 || got[0] != "b" || len(store.byUUID) != 2 {
+		t.Errorf("events = %v, want b and c", got)
+	}
+	store.MarkProcessed(ctx, "b")
+	if processed, _ := store.Record(ctx, &Envelope{UUID: "b"}); !processed {
+		t.Error("Record() of a retained processed event = false")
+	}
+
+	// Purge drops events received before the cutoff
+	store = NewMemoryEventStore(0)
+	record(store, "a", "b")
+	store.events[0].received = time.Now().Add(-2 * time.Hour)
+	if n, err := store.Purge(ctx, time.Now().Add(-time.Hour)); n != 1 || err != nil {
+		t.Errorf("Purge() = %d, %v; want 1", n, err)
+	}
+	if got := uuids(store); len(got) != 1 || got[0] != "b" {
+		t.Errorf("events after Purge() = %v, want b", got)
+	}
+	if err := store.MarkProcessed(ctx, "a"); err == nil {
+		t.Error("MarkProcessed() of a purged event succeeded")
+	}
+
+	// Retention purges on each Record
+	store = &MemoryEventStore{Retention: time.Hour}
+	record(store, "a")
+	store.events[0].received = time.Now().Add(-2 * time.Hour)
+	record(store, "b")
+	if got := uuids(store); len(got) != 1 || got[0] != "b" {
+		t.Errorf("events with Retention = %v, want b", got)
+	}
+}
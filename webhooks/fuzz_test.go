@@ -0,0 +1,20 @@
+package webhooks
+
+import "testing"
+
+func FuzzParseEvent(f *testing.F) {
+	f.Add([]byte(`{"event": "MessageSent", "timestamp": 1477945177.1, "uuid": "a", "payload": {"message": {"id": 1}, "status": "Sent"}}`))
+	f.Add([]byte(`{"event": "DomainDNSError", "payload": {"domain": "example.com", "dns_checked_at": 1e400}}`))
+	f.Add([]byte(`{"event": "MessageBounced", "payload": null}`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ev, err := ParseEvent(data)
+		if err != nil {
+			return
+		}
+		if ev.EventName() == "" {
+			t.Errorf("ParseEvent(%q) returned an event without a name", data)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MaxPayloadSize is the largest request body the handlers accept
+const MaxPayloadSize = 1 << 20
+
+// HandlerFunc processes a verified webhook event. Returning an error makes
+// the handler respond with 500 so that Postal retries the delivery.
+type HandlerFunc func(ctx context.Context, env *Envelope, ev Event) error
+
+// Handler returns an http.Handler for a webhook endpoint. Requests whose
+// signature does not verify against key are rejected with 401; a nil key
+// skips verification, for endpoints authenticated some other way. Events
+// without a type in this package are acknowledged without calling fn.
+func Handler(key *rsa.PublicKey, fn HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		env, ev, ok := readEvent(w, r, key)
+		if !ok {
+			return
+		}
+		if err := fn(r.Context(), env, ev); err != nil {
+			http.Error(w, "failed to process event", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// readEvent reads, verifies and parses a webhook request. It responds and
+// returns false when the request is rejected or the event is unknown.
+func readEvent(w http.ResponseWriter, r *http.Request, key *rsa.PublicKey) (*Envelope, Event, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, nil, false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxPayloadSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return nil, nil, false
+		}
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	if key != nil {
+		if err := Verify(key, r.Header, body); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return nil, nil, false
+		}
+	}
+
+	env, err := ParseEnvelope(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	ev, err := env.Decode()
+	if errors.Is(err, ErrUnknownEvent) {
+		w.WriteHeader(http.StatusOK)
+		return nil, nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return env, ev, true
+}
@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const sentEventJSON = `{"event": "MessageSent", "timestamp": 1477945177.5, "uuid": "a7a7e5c5-7d2c-4a6b-9c1b-1c2b5e1f0e9d", "payload": {"message": ` + messageJSON + `, "status": "Sent"}}`
+
+// signedRequest builds a webhook request for body signed with key
+func signedRequest(t *testing.T, key *rsa.PrivateKey, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	if key != nil {
+		if err := Sign(key, req.Header, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return req
+}
+
+func TestHandler(t *testing.T) {
+	key := testKey(t)
+	tests := []struct {
+		name       string
+		req        func() *http.Request
+		handlerErr error
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "signed event",
+			req:        func() *http.Request { return signedRequest(t, key, sentEventJSON) },
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		{
+			name:       "handler error",
+			req:        func() *http.Request { return signedRequest(t, key, sentEventJSON) },
+			handlerErr: errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCalled: true,
+		},
+		{
+			name:       "unsigned",
+			req:        func() *http.Request { return signedRequest(t, nil, sentEventJSON) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "signed by another key",
+			req:        func() *http.Request { return signedRequest(t, testKey(t), sentEventJSON) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid json",
+			req:        func() *http.Request { return signedRequest(t, key, `{`) },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "unknown event",
+			req: func() *http.Request {
+				return signedRequest(t, key, `{"event": "ServerCreated", "uuid": "x", "payload": {}}`)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong method",
+			req:        func() *http.Request { return httptest.NewRequest(http.MethodGet, "/webhooks", nil) },
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			h := Handler(&key.PublicKey, func(ctx context.Context, env *Envelope, ev Event) error {
+				called = true
+				if env.UUID == "" || ev.EventName() != env.Event {
+					t.Errorf("handler got %+v, %T", env, ev)
+				}
+				return tt.handlerErr
+			})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tt.req())
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrHandlerPanic is wrapped by the error a Receiver reports when its
+// handler panics
+var ErrHandlerPanic = errors.New("webhooks: handler panicked")
+
+// ErrorPolicy selects how a Receiver answers Postal when its handler fails
+type ErrorPolicy int
+
+const (
+	// RetryOnError responds 500 so that Postal redelivers the event
+	RetryOnError ErrorPolicy = iota
+	// AckOnError responds 200 and only reports the error to OnError, for
+	// handlers whose failures a redelivery would not fix
+	AckOnError
+)
+
+// ReceiverConfig configures a Receiver
+type ReceiverConfig struct {
+	// Workers is the number of background workers. Zero handles each
+	// event in its request, as Handler does; otherwise events are
+	// acknowledged once queued, so slow handlers cannot make Postal's
+	// request time out, and failures can only be reported to OnError.
+	Workers int
+	// QueueSize is how many events can wait for a worker. When the queue
+	// is full the receiver responds 503 and Postal redelivers later.
+	// Defaults to Workers.
+	QueueSize int
+
+	// ErrorPolicy applies to handler errors and panics when Workers is zero
+	ErrorPolicy ErrorPolicy
+	// OnError is called with every handler error and recovered panic
+	OnError func(err error)
+	// Timeout bounds each handler call. Zero means no limit.
+	Timeout time.Duration
+}
+
+// Receiver is an http.Handler for webhook endpoints that verifies and
+// parses events like Handler, then recovers handler panics, applies an
+// error policy and can process events on a worker pool. Receivers with
+// workers must be stopped with Shutdown.
+type Receiver struct {
+	cfg ReceiverConfig
+	key *rsa.PublicKey
+	fn  HandlerFunc
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan func(context.Context) error
+	wg     sync.WaitGroup
+}
+
+// NewReceiver creates a Receiver that verifies signatures against key, or
+// skips verification when it is nil, and passes events to fn
+func NewReceiver(key *rsa.PublicKey, fn HandlerFunc, cfg ReceiverConfig) *Receiver {
+	r := &Receiver{cfg: cfg, key: key, fn: fn}
+	if cfg.Workers > 0 {
+		size := cfg.QueueSize
+		if size <= 0 {
+			size = cfg.Workers
+		}
+		r.queue = make(chan func(context.Context) error, size)
+		r.wg.Add(cfg.Workers)
+		for i := 0; i < cfg.Workers; i++ {
+			go r.work()
+		}
+	}
+	return r
+}
+
+// ServeHTTP implements http.Handler
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	env, ev, ok := readEvent(w, req, r.key)
+	if !ok {
+		return
+	}
+	call := func(ctx context.Context) error { return r.fn(ctx, env, ev) }
+
+	if r.queue == nil {
+		if err := r.run(req.Context(), call); err != nil && r.cfg.ErrorPolicy == RetryOnError {
+			http.Error(w, "failed to process event", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		http.Error(w, "receiver is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	select {
+	case r.queue <- call:
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "receiver is busy", http.StatusServiceUnavailable)
+	}
+}
+
+// Shutdown stops accepting events and waits for queued ones to be
+// handled, or for ctx to be done
+func (r *Receiver) Shutdown(ctx context.Context) error {
+	if r.queue == nil {
+		return nil
+	}
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work handles queued events until the queue is closed
+func (r *Receiver) work() {
+	defer r.wg.Done()
+	for call := range r.queue {
+		r.run(context.Background(), call)
+	}
+}
+
+// run calls the handler with the configured timeout, turning a panic into
+// an error and reporting failures to OnError
+func (r *Receiver) run(ctx context.Context, call func(context.Context) error) (err error) {
+	if r.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v\n%s", ErrHandlerPanic, p, debug.Stack())
+		}
+		if err != nil && r.cfg.OnError != nil {
+			r.cfg.OnError(err)
+		}
+	}()
+	return call(ctx)
+}
@@ -0,0 +1,146 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// deliver posts body to h, signed with key when it is not nil
+func deliver(t *testing.T, h http.Handler, key *rsa.PrivateKey, body string) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, key, body))
+	return rec.Code
+}
+
+func TestReceiverErrorPolicy(t *testing.T) {
+	key := testKey(t)
+	tests := []struct {
+		name       string
+		policy     ErrorPolicy
+		handler    HandlerFunc
+		wantStatus int
+		wantErr    error
+	}{
+		{
+			name:       "success",
+			handler:    func(context.Context, *Envelope, Event) error { return nil },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "error triggers redelivery",
+			policy:     RetryOnError,
+			handler:    func(context.Context, *Envelope, Event) error { return errors.New("db down") },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "error acknowledged",
+			policy:     AckOnError,
+			handler:    func(context.Context, *Envelope, Event) error { return errors.New("bad address") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "panic recovered",
+			policy:     RetryOnError,
+			handler:    func(context.Context, *Envelope, Event) error { panic("nil map") },
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    ErrHandlerPanic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported error
+			r := NewReceiver(&key.PublicKey, tt.handler, ReceiverConfig{
+				ErrorPolicy: tt.policy,
+				OnError:     func(err error) { reported = err },
+			})
+			if code := deliver(t, r, key, sentEventJSON); code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", code, tt.wantStatus)
+			}
+			if tt.wantErr != nil && !errors.Is(reported, tt.wantErr) {
+				t.Errorf("reported error = %v, want %v", reported, tt.wantErr)
+			}
+			if deliver(t, r, key, "{") != http.StatusBadRequest {
+				t.Error("invalid payload was not rejected")
+			}
+			if deliver(t, r, nil, sentEventJSON) != http.StatusUnauthorized {
+				t.Error("unsigned event was not rejected")
+			}
+		})
+	}
+}
+
+func TestReceiverTimeout(t *testing.T) {
+	var reported error
+	r := NewReceiver(nil, func(ctx context.Context, env *Envelope, ev Event) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, ReceiverConfig{Timeout: 10 * time.Millisecond, OnError: func(err error) { reported = err }})
+
+	if code := deliver(t, r, nil, sentEventJSON); code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", code)
+	}
+	if !errors.Is(reported, context.DeadlineExceeded) {
+		t.Errorf("reported error = %v, want DeadlineExceeded", reported)
+	}
+}
+
+func TestReceiverWorkers(t *testing.T) {
+	release := make(chan struct{})
+	var handled atomic.Int32
+	var mu sync.Mutex
+	var reported []error
+	r := NewReceiver(nil, func(ctx context.Context, env *Envelope, ev Event) error {
+		<-release
+		if handled.Add(1) == 1 {
+			panic("first event")
+		}
+		return nil
+	}, ReceiverConfig{
+		Workers:   1,
+		QueueSize: 1,
+		OnError: func(err error) {
+			mu.Lock()
+			reported = append(reported, err)
+			mu.Unlock()
+		},
+	})
+
+	// One event occupies the worker and one waits in the queue; the
+	// next is refused so that Postal redelivers it later
+	if code := deliver(t, r, nil, sentEventJSON); code != http.StatusOK {
+		t.Fatalf("first delivery status = %d, want 200", code)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(r.queue) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if code := deliver(t, r, nil, sentEventJSON); code != http.StatusOK {
+		t.Fatalf("queued delivery status = %d, want 200", code)
+	}
+	if code := deliver(t, r, nil, sentEventJSON); code != http.StatusServiceUnavailable {
+		t.Errorf("delivery to full queue status = %d, want 503", code)
+	}
+
+	close(release)
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := handled.Load(); got != 2 {
+		t.Errorf("handled %d events, want 2", got)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], ErrHandlerPanic) {
+		t.Errorf("reported errors = %v, want one panic", reported)
+	}
+	if code := deliver(t, r, nil, sentEventJSON); code != http.StatusServiceUnavailable {
+		t.Errorf("delivery after shutdown status = %d, want 503", code)
+	}
+}
@@ -0,0 +1,144 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SelfTestToken is the message token of events sent by SelfTest
+const SelfTestToken = "postal-go-self-test"
+
+// selfTestUUIDPrefix starts the UUID of every event sent by SelfTest
+const selfTestUUIDPrefix = "postal-go-self-test-"
+
+// SelfTestOptions configures SelfTest
+type SelfTestOptions struct {
+	// Key signs the test event. The endpoint must verify signatures with
+	// its public half, so run it with a test key pair while self-testing.
+	Key *rsa.PrivateKey
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// SelfTestResult is the endpoint's responses to a self-test
+type SelfTestResult struct {
+	// StatusCode, Body and Duration describe the response to the signed event
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+	// RejectedStatus is the status the endpoint answered an event with a
+	// bad signature with
+	RejectedStatus int
+}
+
+// SelfTest posts a MessageSent event to the webhook endpoint at url the way
+// Postal would, checking that it rejects the event when the signature is
+// wrong and that signature verification, parsing and the handler succeed
+// when it is right. Handlers can recognise the event with
+// Envelope.IsSelfTest. Failures are returned as errors along with the
+// result so far.
+func SelfTest(ctx context.Context, url string, opts SelfTestOptions) (*SelfTestResult, error) {
+	if opts.Key == nil {
+		return nil, errors.New("webhooks: self-test: a signing key is required")
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	now := time.Now()
+	body, err := selfTestPayload(now)
+	if err != nil {
+		return nil, err
+	}
+	result := &SelfTestResult{}
+
+	// A signature over a different body must be rejected
+	status, _, _, err := selfTestPost(ctx, client, url, body, func(h http.Header) error {
+		return Sign(opts.Key, h, append([]byte("tampered "), body...))
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.RejectedStatus = status
+	if status < 400 || status > 499 {
+		return result, fmt.Errorf("webhooks: self-test: event with an invalid signature got status %d, want 4xx", status)
+	}
+
+	result.StatusCode, result.Body, result.Duration, err = selfTestPost(ctx, client, url, body, func(h http.Header) error {
+		return Sign(opts.Key, h, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.StatusCode < 200 || result.StatusCode > 299 {
+		return result, fmt.Errorf("webhooks: self-test got status %d: %s", result.StatusCode, result.Body)
+	}
+	return result, nil
+}
+
+// IsSelfTest reports whether the event was sent by SelfTest
+func (e *Envelope) IsSelfTest() bool {
+	return strings.HasPrefix(e.UUID, selfTestUUIDPrefix)
+}
+
+// selfTestPost posts body with the headers set by sign and returns the
+// response
+func selfTestPost(ctx context.Context, client *http.Client, url string, body []byte, sign func(http.Header) error) (int, string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("webhooks: self-test: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := sign(req.Header); err != nil {
+		return 0, "", 0, fmt.Errorf("webhooks: self-test: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("webhooks: self-test: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	return resp.StatusCode, string(bytes.TrimSpace(respBody)), time.Since(start), nil
+}
+
+// selfTestPayload builds the request body of the self-test event
+func selfTestPayload(now time.Time) ([]byte, error) {
+	ts := float64(now.UnixNano()) / 1e9
+	id := strconv.FormatInt(now.UnixNano(), 36)
+	payload, err := json.Marshal(MessageSent{DeliveryStatus{
+		Message: Message{
+			Token:      SelfTestToken,
+			Direction:  "outgoing",
+			MessageID:  "self-test-" + id + "@postal-go.invalid",
+			To:         "self-test@example.com",
+			From:       "self-test@postal-go.invalid",
+			Subject:    "postal-go webhook self-test",
+			Timestamp:  ts,
+			SpamStatus: "NotChecked",
+		},
+		Status:    "Sent",
+		Details:   "This event was sent by webhooks.SelfTest to check the endpoint.",
+		Timestamp: ts,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Envelope{
+		Event:     EventMessageSent,
+		Timestamp: ts,
+		UUID:      selfTestUUIDPrefix + id,
+		Payload:   payload,
+	})
+}
@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	key := testKey(t)
+	var got *MessageSent
+	ts := httptest.NewServer(Handler(&key.PublicKey, func(ctx context.Context, env *Envelope, ev Event) error {
+		if !env.IsSelfTest() {
+			return errors.New("not a self-test event")
+		}
+		got = ev.(*MessageSent)
+		return nil
+	}))
+	defer ts.Close()
+
+	result, err := SelfTest(context.Background(), ts.URL, SelfTestOptions{Key: key})
+	if err != nil {
+		t.Fatalf("SelfTest() error = %v", err)
+	}
+	if result.StatusCode != 200 || result.RejectedStatus != 401 {
+		t.Errorf("result = %+v, want 200 and a 401 rejection", result)
+	}
+	if got == nil || got.Message.Token != SelfTestToken {
+		t.Errorf("handler got %+v", got)
+	}
+
+	// A key the endpoint does not trust is rejected both times
+	other := testKey(t)
+	if result, err = SelfTest(context.Background(), ts.URL, SelfTestOptions{Key: other}); err == nil || result.StatusCode != 401 {
+		t.Errorf("SelfTest() with another key = %+v, %v; want a 401 error", result, err)
+	}
+}
+
+func TestSelfTestUnverifiedEndpoint(t *testing.T) {
+	// An endpoint that accepts anything fails the signature check
+	ts := httptest.NewServer(Handler(nil, func(ctx context.Context, env *Envelope, ev Event) error {
+		return nil
+	}))
+	defer ts.Close()
+
+	result, err := SelfTest(context.Background(), ts.URL, SelfTestOptions{Key: testKey(t)})
+	if err == nil || result == nil || result.RejectedStatus != 200 {
+		t.Fatalf("SelfTest() = %+v, %v; want an error for the accepted bad signature", result, err)
+	}
+}
+
+func TestSelfTestHandlerError(t *testing.T) {
+	key := testKey(t)
+	ts := httptest.NewServer(Handler(&key.PublicKey, func(ctx context.Context, env *Envelope, ev Event) error {
+		return errors.New("boom")
+	}))
+	defer ts.Close()
+
+	result, err := SelfTest(context.Background(), ts.URL, SelfTestOptions{Key: key})
+	if err == nil || result == nil || result.StatusCode != 500 {
+		t.Fatalf("SelfTest() = %+v, %v; want a 500 error", result, err)
+	}
+}
+
+// testKey generates a signing key for a test
+func testKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
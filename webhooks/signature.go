@@ -0,0 +1,122 @@
+package webhooks
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Headers Postal signs webhook requests with. Both hold a base64 RSA
+// signature of the request body, made with the server's signing key.
+const (
+	SignatureHeader    = "X-Postal-Signature"
+	Signature256Header = "X-Postal-Signature-256"
+)
+
+// ErrInvalidSignature is returned when a request's signature is missing or
+// does not match its body
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ParsePublicKey parses the public key Postal verifies webhooks with, as
+// shown in its web UI (bare base64) or in PEM form
+func ParsePublicKey(s string) (*rsa.PublicKey, error) {
+	der, err := decodeKey(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		if rsaKey, rsaErr := x509.ParsePKCS1PublicKey(der); rsaErr == nil {
+			return rsaKey, nil
+		}
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid public key: %T is not an RSA key", key)
+	}
+	return rsaKey, nil
+}
+
+// ParsePrivateKey parses a PKCS #1 or PKCS #8 RSA private key in PEM or
+// bare base64 form, for signing with Sign
+func ParsePrivateKey(s string) (*rsa.PrivateKey, error) {
+	der, err := decodeKey(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key: %T is not an RSA key", key)
+	}
+	return rsaKey, nil
+}
+
+// decodeKey returns the DER bytes of a PEM or bare base64 key
+func decodeKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		return block.Bytes, nil
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// Verify checks the signature of a webhook request body against key. The
+// SHA-256 signature is used when present, otherwise the SHA-1 one older
+// Postal versions send.
+func Verify(key *rsa.PublicKey, header http.Header, body []byte) error {
+	if sig := header.Get(Signature256Header); sig != "" {
+		sum := sha256.Sum256(body)
+		return verify(key, crypto.SHA256, sum[:], sig)
+	}
+	if sig := header.Get(SignatureHeader); sig != "" {
+		sum := sha1.Sum(body)
+		return verify(key, crypto.SHA1, sum[:], sig)
+	}
+	return fmt.Errorf("%w: no %s header", ErrInvalidSignature, SignatureHeader)
+}
+
+func verify(key *rsa.PublicKey, hash crypto.Hash, sum []byte, sig string) error {
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, sum, raw); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign sets both signature headers for body the way Postal does. It is
+// used by SelfTest and for signing test requests.
+func Sign(key *rsa.PrivateKey, header http.Header, body []byte) error {
+	sum1 := sha1.Sum(body)
+	sig1, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, sum1[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign webhook: %w", err)
+	}
+	sum256 := sha256.Sum256(body)
+	sig256, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum256[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign webhook: %w", err)
+	}
+	header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(sig1))
+	header.Set(Signature256Header, base64.StdEncoding.EncodeToString(sig256))
+	return nil
+}
@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	key := testKey(t)
+	body := []byte(`{"event":"MessageSent"}`)
+	header := http.Header{}
+	if err := Sign(key, header, body); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(&key.PublicKey, header, body); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := Verify(&key.PublicKey, header, []byte(`{"event":"MessageHeld"}`)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() of another body error = %v, want ErrInvalidSignature", err)
+	}
+	if err := Verify(&testKey(t).PublicKey, header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() with another key error = %v, want ErrInvalidSignature", err)
+	}
+	if err := Verify(&key.PublicKey, http.Header{}, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() without headers error = %v, want ErrInvalidSignature", err)
+	}
+
+	// Older Postal versions only send the SHA-1 signature
+	header.Del(Signature256Header)
+	if err := Verify(&key.PublicKey, header, body); err != nil {
+		t.Errorf("Verify() of SHA-1 signature error = %v", err)
+	}
+	header.Set(SignatureHeader, "not base64!")
+	if err := Verify(&key.PublicKey, header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() of malformed signature error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	key := testKey(t)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]string{
+		"base64": base64.StdEncoding.EncodeToString(der),
+		"pem":    string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		"pkcs1":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})),
+	} {
+		pub, err := ParsePublicKey(s)
+		if err != nil || !pub.Equal(&key.PublicKey) {
+			t.Errorf("ParsePublicKey(%s) = %v, %v", name, pub, err)
+		}
+	}
+	if _, err := ParsePublicKey("not a key"); err == nil {
+		t.Error("ParsePublicKey() of garbage succeeded")
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]string{
+		"pkcs1": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"pkcs8": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+	} {
+		priv, err := ParsePrivateKey(s)
+		if err != nil || !priv.Equal(key) {
+			t.Errorf("ParsePrivateKey(%s) = %v", name, err)
+		}
+	}
+}
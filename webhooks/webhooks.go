@@ -0,0 +1,242 @@
+// Package webhooks parses the events Postal posts to webhook endpoints
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sachin-duhan/postal-go/deliverability"
+)
+
+// Event names sent by Postal
+const (
+	EventMessageSent           = "MessageSent"
+	EventMessageDelayed        = "MessageDelayed"
+	EventMessageDeliveryFailed = "MessageDeliveryFailed"
+	EventMessageHeld           = "MessageHeld"
+	EventMessageBounced        = "MessageBounced"
+	EventMessageLinkClicked    = "MessageLinkClicked"
+	EventMessageLoaded         = "MessageLoaded"
+	EventDomainDNSError        = deliverability.EventDomainDNSError
+	EventSendLimitApproaching  = "SendLimitApproaching"
+	EventSendLimitExceeded     = "SendLimitExceeded"
+)
+
+// ErrUnknownEvent is returned for events this package has no type for, so
+// receivers can acknowledge and skip them
+var ErrUnknownEvent = errors.New("unknown webhook event")
+
+// Event is a parsed webhook payload, one of the pointer types in this package
+type Event interface {
+	// EventName returns the Postal event name, e.g. "MessageSent"
+	EventName() string
+}
+
+// Envelope is the wrapper Postal sends every event in
+type Envelope struct {
+	Event     string          `json:"event"`
+	Timestamp float64         `json:"timestamp"`
+	UUID      string          `json:"uuid"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Time returns when Postal generated the event
+func (e *Envelope) Time() time.Time {
+	return unixTime(e.Timestamp)
+}
+
+// ParseEnvelope decodes the wrapper of a webhook request body without
+// decoding its payload
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid webhook: %w", err)
+	}
+	if env.Event == "" {
+		return nil, fmt.Errorf("invalid webhook: event is empty")
+	}
+	if len(env.Payload) == 0 {
+		return nil, fmt.Errorf("invalid webhook: %s has no payload", env.Event)
+	}
+	return &env, nil
+}
+
+// ParseEvent decodes a webhook request body into its typed event. Events
+// without a type return an error wrapping ErrUnknownEvent.
+func ParseEvent(data []byte) (Event, error) {
+	env, err := ParseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	return env.Decode()
+}
+
+// Decode decodes the payload into the type for the envelope's event
+func (e *Envelope) Decode() (Event, error) {
+	var ev Event
+	switch e.Event {
+	case EventMessageSent:
+		ev = &MessageSent{}
+	case EventMessageDelayed:
+		ev = &MessageDelayed{}
+	case EventMessageDeliveryFailed:
+		ev = &MessageDeliveryFailed{}
+	case EventMessageHeld:
+		ev = &MessageHeld{}
+	case EventMessageBounced:
+		ev = &MessageBounced{}
+	case EventMessageLinkClicked:
+		ev = &MessageLinkClicked{}
+	case EventMessageLoaded:
+		ev = &MessageLoaded{}
+	case EventDomainDNSError:
+		ev = &DomainDNSError{}
+	case EventSendLimitApproaching:
+		ev = &SendLimitApproaching{}
+	case EventSendLimitExceeded:
+		ev = &SendLimitExceeded{}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, e.Event)
+	}
+	if err := json.Unmarshal(e.Payload, ev); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", e.Event, err)
+	}
+	return ev, nil
+}
+
+// Message describes the message an event is about
+type Message struct {
+	ID         int     `json:"id"`
+	Token      string  `json:"token"`
+	Direction  string  `json:"direction"`
+	MessageID  string  `json:"message_id"`
+	To         string  `json:"to"`
+	From       string  `json:"from"`
+	Subject    string  `json:"subject"`
+	Timestamp  float64 `json:"timestamp"`
+	SpamStatus string  `json:"spam_status"`
+	Tag        string  `json:"tag"`
+}
+
+// Time returns when the message was received by Postal
+func (m *Message) Time() time.Time {
+	return unixTime(m.Timestamp)
+}
+
+// DeliveryStatus is the payload shared by delivery attempt events
+type DeliveryStatus struct {
+	Message Message `json:"message"`
+	// Status is "Sent", "SoftFail", "HardFail" or "Held"
+	Status string `json:"status"`
+	// Details describes the attempt; Output is the remote server's response
+	Details     string  `json:"details"`
+	Output      string  `json:"output"`
+	SentWithSSL bool    `json:"sent_with_ssl"`
+	Timestamp   float64 `json:"timestamp"`
+	Duration    float64 `json:"time"` // Seconds
+}
+
+// Time returns when the attempt was made
+func (d *DeliveryStatus) Time() time.Time {
+	return unixTime(d.Timestamp)
+}
+
+// MessageSent is sent when a message is accepted by the receiving server
+type MessageSent struct{ DeliveryStatus }
+
+// MessageDelayed is sent when delivery failed temporarily and will be retried
+type MessageDelayed struct{ DeliveryStatus }
+
+// MessageDeliveryFailed is sent when delivery failed permanently
+type MessageDeliveryFailed struct{ DeliveryStatus }
+
+// MessageHeld is sent when Postal holds a message instead of delivering it
+type MessageHeld struct{ DeliveryStatus }
+
+// MessageBounced is sent when a bounce is received for a sent message
+type MessageBounced struct {
+	OriginalMessage Message `json:"original_message"`
+	Bounce          Message `json:"bounce"`
+}
+
+// MessageLinkClicked is sent when a recipient follows a tracked link
+type MessageLinkClicked struct {
+	URL       string  `json:"url"`
+	Token     string  `json:"token"`
+	IPAddress string  `json:"ip_address"`
+	UserAgent string  `json:"user_agent"`
+	Message   Message `json:"message"`
+}
+
+// MessageLoaded is sent when a recipient opens a message with tracking enabled
+type MessageLoaded struct {
+	IPAddress string  `json:"ip_address"`
+	UserAgent string  `json:"user_agent"`
+	Message   Message `json:"message"`
+}
+
+// DomainDNSError is sent when Postal's DNS check finds a domain's records
+// broken. Checker.Diagnose in the deliverability package suggests fixes.
+type DomainDNSError struct {
+	deliverability.DNSErrorEvent
+}
+
+// Server identifies the mail server in send limit events
+type Server struct {
+	UUID         string `json:"uuid"`
+	Name         string `json:"name"`
+	Permalink    string `json:"permalink"`
+	Organization string `json:"organization"`
+}
+
+// SendLimit is the payload shared by send limit events
+type SendLimit struct {
+	Server Server `json:"server"`
+	Volume int    `json:"volume"`
+	Limit  int    `json:"limit"`
+}
+
+// SendLimitApproaching is sent when a server nears its send limit
+type SendLimitApproaching struct{ SendLimit }
+
+// SendLimitExceeded is sent when a server exceeds its send limit
+type SendLimitExceeded struct{ SendLimit }
+
+// EventName implements Event
+func (*MessageSent) EventName() string { return EventMessageSent }
+
+// EventName implements Event
+func (*MessageDelayed) EventName() string { return EventMessageDelayed }
+
+// EventName implements Event
+func (*MessageDeliveryFailed) EventName() string { return EventMessageDeliveryFailed }
+
+// EventName implements Event
+func (*MessageHeld) EventName() string { return EventMessageHeld }
+
+// EventName implements Event
+func (*MessageBounced) EventName() string { return EventMessageBounced }
+
+// EventName implements Event
+func (*MessageLinkClicked) EventName() string { return EventMessageLinkClicked }
+
+// EventName implements Event
+func (*MessageLoaded) EventName() string { return EventMessageLoaded }
+
+// EventName implements Event
+func (*DomainDNSError) EventName() string { return EventDomainDNSError }
+
+// EventName implements Event
+func (*SendLimitApproaching) EventName() string { return EventSendLimitApproaching }
+
+// EventName implements Event
+func (*SendLimitExceeded) EventName() string { return EventSendLimitExceeded }
+
+// unixTime converts Postal's fractional Unix timestamps
+func unixTime(ts float64) time.Time {
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
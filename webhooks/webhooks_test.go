@@ -0,0 +1,148 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const messageJSON = `{"id": 12345, "token": "abc", "direction": "outgoing", "message_id": "5817a64332f44_4ec93ff59e79d154565eb@app34.mail", "to": "test@example.com", "from": "sales@awesomeapp.com", "subject": "Welcome to AwesomeApp", "timestamp": 1477945177.12994, "spam_status": "NotSpam", "tag": "welcome"}`
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		body  string
+		check func(t *testing.T, ev Event)
+	}{
+		{
+			name:  "sent",
+			event: EventMessageSent,
+			body:  `{"message": ` + messageJSON + `, "status": "Sent", "details": "Message for test@example.com accepted by 1.2.3.4", "output": "250 OK", "sent_with_ssl": true, "timestamp": 1477945177.5, "time": 0.12}`,
+			check: func(t *testing.T, ev Event) {
+				sent := ev.(*MessageSent)
+				if sent.Status != "Sent" || sent.Output != "250 OK" || !sent.SentWithSSL || sent.Message.Tag != "welcome" {
+					t.Errorf("MessageSent = %+v", sent)
+				}
+				if got := sent.Time(); !got.Equal(time.Unix(1477945177, 5e8)) {
+					t.Errorf("Time() = %v", got)
+				}
+			},
+		},
+		{
+			name:  "delivery failed",
+			event: EventMessageDeliveryFailed,
+			body:  `{"message": ` + messageJSON + `, "status": "HardFail", "output": "550 No such user"}`,
+			check: func(t *testing.T, ev Event) {
+				if failed := ev.(*MessageDeliveryFailed); failed.Status != "HardFail" || failed.Message.ID != 12345 {
+					t.Errorf("MessageDeliveryFailed = %+v", failed)
+				}
+			},
+		},
+		{
+			name:  "held",
+			event: EventMessageHeld,
+			body:  `{"message": ` + messageJSON + `, "status": "Held", "details": "Credential is configured to hold all messages"}`,
+			check: func(t *testing.T, ev Event) {
+				if held := ev.(*MessageHeld); held.Status != "Held" {
+					t.Errorf("MessageHeld = %+v", held)
+				}
+			},
+		},
+		{
+			name:  "bounced",
+			event: EventMessageBounced,
+			body:  `{"original_message": ` + messageJSON + `, "bounce": {"id": 12347, "to": "abc@awesomeapp.com", "from": "postmaster@someserver.com", "subject": "Delivery Error"}}`,
+			check: func(t *testing.T, ev Event) {
+				if bounced := ev.(*MessageBounced); bounced.OriginalMessage.ID != 12345 || bounced.Bounce.ID != 12347 {
+					t.Errorf("MessageBounced = %+v", bounced)
+				}
+			},
+		},
+		{
+			name:  "link clicked",
+			event: EventMessageLinkClicked,
+			body:  `{"url": "https://example.com/start", "token": "VJzsFA0S", "ip_address": "185.22.208.2", "user_agent": "Mozilla/5.0", "message": ` + messageJSON + `}`,
+			check: func(t *testing.T, ev Event) {
+				if clicked := ev.(*MessageLinkClicked); clicked.URL != "https://example.com/start" || clicked.Message.To != "test@example.com" {
+					t.Errorf("MessageLinkClicked = %+v", clicked)
+				}
+			},
+		},
+		{
+			name:  "dns error",
+			event: EventDomainDNSError,
+			body:  `{"domain": "example.com", "uuid": "820b47a4", "dns_checked_at": 1477945711.5, "spf_status": "Missing", "spf_error": "No SPF record exists for this domain"}`,
+			check: func(t *testing.T, ev Event) {
+				dnsErr := ev.(*DomainDNSError)
+				if dnsErr.Domain != "example.com" || dnsErr.SPFStatus != "Missing" || dnsErr.CheckedAt().Unix() != 1477945711 {
+					t.Errorf("DomainDNSError = %+v", dnsErr)
+				}
+			},
+		},
+		{
+			name:  "send limit",
+			event: EventSendLimitExceeded,
+			body:  `{"server": {"uuid": "54529725", "name": "Main", "permalink": "main", "organization": "acme"}, "volume": 510, "limit": 500}`,
+			check: func(t *testing.T, ev Event) {
+				if limit := ev.(*SendLimitExceeded); limit.Server.Permalink != "main" || limit.Volume != 510 || limit.Limit != 500 {
+					t.Errorf("SendLimitExceeded = %+v", limit)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := ParseEvent([]byte(`{"event": "` + tt.event + `", "timestamp": 1477945177.1, "uuid": "a1b2", "payload": ` + tt.body + `}`))
+			if err != nil {
+				t.Fatalf("ParseEvent() error = %v", err)
+			}
+			if ev.EventName() != tt.event {
+				t.Errorf("EventName() = %q, want %q", ev.EventName(), tt.event)
+			}
+			tt.check(t, ev)
+		})
+	}
+}
+
+func TestParseEventErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		is   error
+	}{
+		{"not json", `{`, nil},
+		{"no event", `{"payload": {}}`, nil},
+		{"no payload", `{"event": "MessageSent"}`, nil},
+		{"unknown event", `{"event": "ServerSuspended", "payload": {}}`, ErrUnknownEvent},
+		{"bad payload", `{"event": "MessageSent", "payload": {"status": 5}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEvent([]byte(tt.body))
+			if err == nil {
+				t.Fatal("ParseEvent() error = nil")
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("ParseEvent() error = %v, want %v", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestParseEnvelope(t *testing.T) {
+	env, err := ParseEnvelope([]byte(`{"event": "MessageLoaded", "timestamp": 1477945177.25, "uuid": "a1b2", "payload": {"ip_address": "1.2.3.4"}}`))
+	if err != nil {
+		t.Fatalf("ParseEnvelope() error = %v", err)
+	}
+	if env.UUID != "a1b2" || !env.Time().Equal(time.Unix(1477945177, 25e7)) {
+		t.Errorf("Envelope = %+v", env)
+	}
+	ev, err := env.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if loaded := ev.(*MessageLoaded); loaded.IPAddress != "1.2.3.4" {
+		t.Errorf("MessageLoaded = %+v", loaded)
+	}
+}